@@ -20,6 +20,7 @@ import (
 type ciCmd struct {
 	fileName             string
 	outputSnapshotOnExit string
+	logFormat            store.LogFormat
 }
 
 func (c *ciCmd) name() model.TiltSubcommand { return "ci" }
@@ -51,6 +52,7 @@ See blog post for additional information: https://blog.tilt.dev/2020/04/16/how-t
 	addNamespaceFlag(cmd)
 	addLogFilterFlags(cmd, "log-")
 	addLogFilterResourcesFlag(cmd)
+	addLogFormatFlag(cmd, &c.logFormat)
 
 	cmd.Flags().BoolVar(&logActionsFlag, "logactions", false, "log all actions and state changes")
 	cmd.Flags().Lookup("logactions").Hidden = true
@@ -98,7 +100,7 @@ func (c *ciCmd) run(ctx context.Context, args []string) error {
 	}
 
 	err = upper.Start(ctx, args, cmdCIDeps.TiltBuild,
-		c.fileName, store.TerminalModeStream, a.UserOpt(), cmdCIDeps.Token,
+		c.fileName, store.TerminalModeStream, c.logFormat, a.UserOpt(), cmdCIDeps.Token,
 		string(cmdCIDeps.CloudAddress))
 	if err == nil {
 		_, _ = fmt.Fprintln(colorable.NewColorableStdout(),
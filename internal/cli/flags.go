@@ -10,6 +10,7 @@ import (
 
 	"github.com/tilt-dev/tilt/internal/hud"
 	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/tiltfile"
 	"github.com/tilt-dev/tilt/pkg/model"
 )
@@ -50,6 +51,11 @@ func addTiltfileFlag(cmd *cobra.Command, s *string) {
 	cmd.Flags().StringVarP(s, "file", "f", tiltfile.FileName, "Path to Tiltfile")
 }
 
+// f: address of the field to populate
+func addLogFormatFlag(cmd *cobra.Command, f *store.LogFormat) {
+	cmd.Flags().Var(f, "log-format", `Format of the log lines Tilt's controllers write. One of "text", "json"`)
+}
+
 func addKubeContextFlag(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&kubeContextOverride, "context", "", "Kubernetes context override. Equivalent to kubectl --context")
 }
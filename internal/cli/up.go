@@ -48,8 +48,9 @@ type upCmd struct {
 	fileName             string
 	outputSnapshotOnExit string
 
-	legacy bool
-	stream bool
+	legacy    bool
+	stream    bool
+	logFormat store.LogFormat
 }
 
 func (c *upCmd) name() model.TiltSubcommand { return "up" }
@@ -91,6 +92,7 @@ local resources--i.e. those using serve_cmd--are terminated when you exit Tilt.
 	addNamespaceFlag(cmd)
 	addLogFilterFlags(cmd, "log-")
 	addLogFilterResourcesFlag(cmd)
+	addLogFormatFlag(cmd, &c.logFormat)
 	cmd.Flags().Lookup("logactions").Hidden = true
 	cmd.Flags().StringVar(&c.outputSnapshotOnExit, "output-snapshot-on-exit", "", "If specified, Tilt will dump a snapshot of its state to the specified path when it exits")
 
@@ -173,7 +175,7 @@ func (c *upCmd) run(ctx context.Context, args []string) error {
 	}
 
 	err = upper.Start(ctx, args, cmdUpDeps.TiltBuild,
-		c.fileName, termMode, a.UserOpt(), cmdUpDeps.Token, string(cmdUpDeps.CloudAddress))
+		c.fileName, termMode, c.logFormat, a.UserOpt(), cmdUpDeps.Token, string(cmdUpDeps.CloudAddress))
 	if err != context.Canceled {
 		return err
 	} else {
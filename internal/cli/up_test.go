@@ -31,3 +31,28 @@ func TestHudEnabled(t *testing.T) {
 		})
 	}
 }
+
+func TestLogFormat(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		args     []string
+		expected store.LogFormat
+	}{
+		{"no flags (default)", nil, store.LogFormatText},
+		{"text", []string{"--log-format=text"}, store.LogFormatText},
+		{"json", []string{"--log-format=json"}, store.LogFormatJSON},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			cmd := upCmd{}
+			c := cmd.register()
+			err := c.Flags().Parse(test.args)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, cmd.logFormat)
+		})
+	}
+
+	cmd := ciCmd{}
+	err := cmd.register().Flags().Parse([]string{"--log-format=yaml"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Unrecognized log format: yaml")
+}
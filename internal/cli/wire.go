@@ -126,7 +126,7 @@ var BaseWireSet = wire.NewSet(
 	engineanalytics.ProvideAnalyticsReporter,
 	provideUpdateModeFlag,
 	fsevent.ProvideWatcherMaker,
-	fsevent.ProvideTimerMaker,
+	fsevent.ProvideDebounceTimersMaker,
 
 	controllers.WireSet,
 
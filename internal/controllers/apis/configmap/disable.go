@@ -3,8 +3,11 @@ package configmap
 import (
 	"context"
 	"fmt"
+	"os"
 	"strconv"
+	"time"
 
+	"github.com/jonboulle/clockwork"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -15,17 +18,25 @@ import (
 
 type DisableResult int
 
+// FileDisablePollInterval is how often a reconciler should re-check a DisableSource that's
+// controlled by a file, since there's no API object to watch for changes.
+const FileDisablePollInterval = 2 * time.Second
+
 func DisableStatus(getCM func(name string) (v1alpha1.ConfigMap, error), disableSource *v1alpha1.DisableSource) (result v1alpha1.DisableState, reason string, err error) {
+	return disableStatus(getCM, clockwork.NewRealClock(), disableSource)
+}
+
+func disableStatus(getCM func(name string) (v1alpha1.ConfigMap, error), clock clockwork.Clock, disableSource *v1alpha1.DisableSource) (result v1alpha1.DisableState, reason string, err error) {
 	if disableSource == nil {
 		// if there is no source, assume the object has opted out of being disabled and is always eanbled
 		return v1alpha1.DisableStateEnabled, "object does not specify a DisableSource", nil
 	}
 
-	if disableSource.ConfigMap != nil {
+	switch {
+	case disableSource.ConfigMap != nil:
 		return cmDisableState(getCM, *disableSource.ConfigMap)
-	}
 
-	if len(disableSource.EveryConfigMap) > 0 {
+	case len(disableSource.EveryConfigMap) > 0:
 		for _, cm := range disableSource.EveryConfigMap {
 			state, reason, err := cmDisableState(getCM, cm)
 			if state != v1alpha1.DisableStateDisabled {
@@ -33,11 +44,121 @@ func DisableStatus(getCM func(name string) (v1alpha1.ConfigMap, error), disableS
 			}
 		}
 		return v1alpha1.DisableStateDisabled, "Every ConfigMap disabled", nil
+
+	case disableSource.File != nil:
+		return fileDisableState(*disableSource.File)
+
+	case disableSource.Schedule != nil:
+		return scheduleDisableState(clock.Now(), *disableSource.Schedule)
+
+	case disableSource.EnvVar != nil:
+		return envVarDisableState(*disableSource.EnvVar)
 	}
 
 	return v1alpha1.DisableStateError, "DisableSource specifies no valid sources", nil
 }
 
+// CombinedDisableStatus resolves each of the sources, and combines their results according to the policy.
+//
+// With AnyDisabled (the default), the result is Disabled if any source is disabled. With AllDisabled,
+// the result is Disabled only if every source is disabled. Otherwise, the first source that
+// isn't Enabled (or Disabled, respectively) determines the result, so that errors and pending
+// sources aren't hidden.
+func CombinedDisableStatus(getCM func(name string) (v1alpha1.ConfigMap, error), clock clockwork.Clock, sources []v1alpha1.DisableSource, policy v1alpha1.DisableSourcePolicy) (v1alpha1.DisableState, string, error) {
+	if len(sources) == 0 {
+		return disableStatus(getCM, clock, nil)
+	}
+	if len(sources) == 1 {
+		return disableStatus(getCM, clock, &sources[0])
+	}
+
+	// The state that settles the result as soon as any source reports it.
+	decisive := v1alpha1.DisableStateDisabled
+	if policy == v1alpha1.DisableSourcePolicyAllDisabled {
+		decisive = v1alpha1.DisableStateEnabled
+	}
+
+	hasUndecided := false
+	var undecidedState v1alpha1.DisableState
+	var undecidedReason string
+	for i := range sources {
+		state, reason, err := disableStatus(getCM, clock, &sources[i])
+		if err != nil {
+			return state, reason, err
+		}
+		if state == decisive {
+			return state, reason, nil
+		}
+		if !hasUndecided && state != v1alpha1.DisableStateEnabled && state != v1alpha1.DisableStateDisabled {
+			hasUndecided = true
+			undecidedState = state
+			undecidedReason = reason
+		}
+	}
+
+	if hasUndecided {
+		return undecidedState, undecidedReason, nil
+	}
+	if decisive == v1alpha1.DisableStateDisabled {
+		return v1alpha1.DisableStateEnabled, "No DisableSource disabled", nil
+	}
+	return v1alpha1.DisableStateDisabled, "Every DisableSource disabled", nil
+}
+
+func fileDisableState(source v1alpha1.FileDisableSource) (v1alpha1.DisableState, string, error) {
+	path := source.Path
+	if path == "" {
+		return v1alpha1.DisableStateError, "File DisableSource has no path", nil
+	}
+
+	_, err := os.Stat(path)
+	exists := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return v1alpha1.DisableStateError, fmt.Sprintf("error reading file %q: %v", path, err), nil
+	}
+
+	var reason string
+	if exists {
+		reason = fmt.Sprintf("File %q exists", path)
+	} else {
+		reason = fmt.Sprintf("File %q does not exist", path)
+	}
+
+	if exists != source.Invert {
+		return v1alpha1.DisableStateDisabled, reason, nil
+	}
+	return v1alpha1.DisableStateEnabled, reason, nil
+}
+
+func envVarDisableState(source v1alpha1.EnvVarDisableSource) (v1alpha1.DisableState, string, error) {
+	name := source.Name
+	if name == "" {
+		return v1alpha1.DisableStateError, "EnvVar DisableSource has no name", nil
+	}
+
+	val, ok := os.LookupEnv(name)
+	if !ok {
+		return v1alpha1.DisableStateEnabled, fmt.Sprintf("Env var %q is not set", name), nil
+	}
+	reason := fmt.Sprintf("Env var %q is %q", name, val)
+
+	var isDisabled bool
+	if source.Value != "" {
+		isDisabled = val == source.Value
+	} else if val != "" {
+		var err error
+		isDisabled, err = strconv.ParseBool(val)
+		if err != nil {
+			return v1alpha1.DisableStateError, fmt.Sprintf("error parsing env var %q value %q as a bool: %v", name, val, err), nil
+		}
+	}
+
+	if isDisabled {
+		return v1alpha1.DisableStateDisabled, reason, nil
+	}
+	return v1alpha1.DisableStateEnabled, reason, nil
+}
+
 func cmDisableState(getCM func(name string) (v1alpha1.ConfigMap, error), source v1alpha1.ConfigMapDisableSource) (v1alpha1.DisableState, string, error) {
 	name := source.Name
 	key := source.Key
@@ -68,18 +189,69 @@ func cmDisableState(getCM func(name string) (v1alpha1.ConfigMap, error), source
 	return result, fmt.Sprintf("ConfigMap/key %q/%q is %v", name, key, isDisabled), nil
 }
 
-// Returns a new DisableStatus if the disable status has changed, or the prev status if it hasn't.
-func MaybeNewDisableStatus(ctx context.Context, client client.Client, disableSource *v1alpha1.DisableSource, prevStatus *v1alpha1.DisableStatus) (*v1alpha1.DisableStatus, error) {
-	getCM := func(name string) (v1alpha1.ConfigMap, error) {
+// configMapReadBackoff is how long to wait before each retry of a ConfigMap read that failed
+// with a transient error.
+var configMapReadBackoff = []time.Duration{50 * time.Millisecond, 100 * time.Millisecond, 200 * time.Millisecond}
+
+// isTransientError returns true if a read may succeed when retried (e.g., the apiserver is
+// briefly overloaded or unavailable).
+func isTransientError(err error) bool {
+	return apierrors.IsServerTimeout(err) ||
+		apierrors.IsTimeout(err) ||
+		apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServiceUnavailable(err) ||
+		apierrors.IsInternalError(err)
+}
+
+// configMapGetter reads ConfigMaps from the client, retrying transient errors with a short backoff.
+func configMapGetter(ctx context.Context, client client.Client) func(name string) (v1alpha1.ConfigMap, error) {
+	return func(name string) (v1alpha1.ConfigMap, error) {
 		var cm v1alpha1.ConfigMap
 		err := client.Get(ctx, types.NamespacedName{Name: name}, &cm)
+		for _, backoff := range configMapReadBackoff {
+			if err == nil || !isTransientError(err) {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return cm, err
+			case <-time.After(backoff):
+			}
+			cm = v1alpha1.ConfigMap{}
+			err = client.Get(ctx, types.NamespacedName{Name: name}, &cm)
+		}
 		return cm, err
 	}
+}
+
+// Returns a new DisableStatus if the disable status has changed, or the prev status if it hasn't.
+//
+// Transient errors reading ConfigMaps are retried. If reading still fails, the error is returned
+// rather than guessing at the disable state.
+func MaybeNewDisableStatus(ctx context.Context, client client.Client, disableSource *v1alpha1.DisableSource, prevStatus *v1alpha1.DisableStatus) (*v1alpha1.DisableStatus, error) {
+	getCM := configMapGetter(ctx, client)
 
 	result, reason, err := DisableStatus(getCM, disableSource)
 	if err != nil {
 		return nil, err
 	}
+	return newDisableStatus(result, reason, prevStatus), nil
+}
+
+// Returns a new DisableStatus for multiple sources if the disable status has changed, or the prev status if it hasn't.
+//
+// Like MaybeNewDisableStatus, transient errors reading ConfigMaps are retried.
+func MaybeNewCombinedDisableStatus(ctx context.Context, client client.Client, clock clockwork.Clock, sources []v1alpha1.DisableSource, policy v1alpha1.DisableSourcePolicy, prevStatus *v1alpha1.DisableStatus) (*v1alpha1.DisableStatus, error) {
+	getCM := configMapGetter(ctx, client)
+
+	result, reason, err := CombinedDisableStatus(getCM, clock, sources, policy)
+	if err != nil {
+		return nil, err
+	}
+	return newDisableStatus(result, reason, prevStatus), nil
+}
+
+func newDisableStatus(result v1alpha1.DisableState, reason string, prevStatus *v1alpha1.DisableStatus) *v1alpha1.DisableStatus {
 	// we treat pending as disabled
 	// eventually we should probably represent isDisabled by an enum in the API, but for now
 	// we treat pending as disabled, with the understanding that it's better to momentarily delay the start of an
@@ -92,7 +264,7 @@ func MaybeNewDisableStatus(ctx context.Context, client client.Client, disableSou
 			LastUpdateTime: apis.Now(),
 			Reason:         reason,
 			State:          result,
-		}, nil
+		}
 	}
-	return prevStatus, nil
+	return prevStatus
 }
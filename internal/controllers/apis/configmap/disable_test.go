@@ -2,10 +2,16 @@ package configmap
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/jonboulle/clockwork"
 	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/pointer"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -142,6 +148,133 @@ func TestMaybeNewDisableStatusChange(t *testing.T) {
 	require.NotSame(t, status, newStatus)
 }
 
+func TestMaybeNewDisableStatusFile(t *testing.T) {
+	f := newDisableFixture(t)
+	path := filepath.Join(t.TempDir(), "disabled")
+	source := &v1alpha1.DisableSource{File: &v1alpha1.FileDisableSource{Path: path}}
+
+	newStatus, err := MaybeNewDisableStatus(f.ctx, f.fc, source, nil)
+	require.NoError(t, err)
+	require.Equal(t, false, newStatus.Disabled)
+	require.Equal(t, v1alpha1.DisableStateEnabled, newStatus.State)
+	require.Contains(t, newStatus.Reason, "does not exist")
+
+	require.NoError(t, os.WriteFile(path, nil, 0644))
+	newStatus, err = MaybeNewDisableStatus(f.ctx, f.fc, source, newStatus)
+	require.NoError(t, err)
+	require.Equal(t, true, newStatus.Disabled)
+	require.Equal(t, v1alpha1.DisableStateDisabled, newStatus.State)
+	require.Contains(t, newStatus.Reason, "exists")
+}
+
+func TestMaybeNewDisableStatusFileInvert(t *testing.T) {
+	f := newDisableFixture(t)
+	path := filepath.Join(t.TempDir(), "enabled")
+	source := &v1alpha1.DisableSource{File: &v1alpha1.FileDisableSource{Path: path, Invert: true}}
+
+	newStatus, err := MaybeNewDisableStatus(f.ctx, f.fc, source, nil)
+	require.NoError(t, err)
+	require.Equal(t, true, newStatus.Disabled)
+	require.Equal(t, v1alpha1.DisableStateDisabled, newStatus.State)
+
+	require.NoError(t, os.WriteFile(path, nil, 0644))
+	newStatus, err = MaybeNewDisableStatus(f.ctx, f.fc, source, newStatus)
+	require.NoError(t, err)
+	require.Equal(t, false, newStatus.Disabled)
+	require.Equal(t, v1alpha1.DisableStateEnabled, newStatus.State)
+}
+
+func TestMaybeNewDisableStatusFileNoPath(t *testing.T) {
+	f := newDisableFixture(t)
+	source := &v1alpha1.DisableSource{File: &v1alpha1.FileDisableSource{}}
+	newStatus, err := MaybeNewDisableStatus(f.ctx, f.fc, source, nil)
+	require.NoError(t, err)
+	require.Equal(t, true, newStatus.Disabled)
+	require.Equal(t, v1alpha1.DisableStateError, newStatus.State)
+	require.Contains(t, newStatus.Reason, "has no path")
+}
+
+func TestMaybeNewDisableStatusEnvVar(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		value    string
+		set      bool
+		envValue string
+		expected v1alpha1.DisableState
+	}{
+		{"unset", "", false, "", v1alpha1.DisableStateEnabled},
+		{"true", "", true, "true", v1alpha1.DisableStateDisabled},
+		{"1", "", true, "1", v1alpha1.DisableStateDisabled},
+		{"false", "", true, "false", v1alpha1.DisableStateEnabled},
+		{"empty", "", true, "", v1alpha1.DisableStateEnabled},
+		{"not a bool", "", true, "yes please", v1alpha1.DisableStateError},
+		{"matching value", "ci", true, "ci", v1alpha1.DisableStateDisabled},
+		{"other value", "ci", true, "local", v1alpha1.DisableStateEnabled},
+		{"value unset", "ci", false, "", v1alpha1.DisableStateEnabled},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newDisableFixture(t)
+			const name = "TILT_TEST_DISABLE_ENV_VAR"
+			if tc.set {
+				t.Setenv(name, tc.envValue)
+			}
+			source := &v1alpha1.DisableSource{EnvVar: &v1alpha1.EnvVarDisableSource{Name: name, Value: tc.value}}
+
+			newStatus, err := MaybeNewDisableStatus(f.ctx, f.fc, source, nil)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, newStatus.State)
+			require.Contains(t, newStatus.Reason, name)
+		})
+	}
+}
+
+func TestMaybeNewDisableStatusEnvVarNoName(t *testing.T) {
+	f := newDisableFixture(t)
+	source := &v1alpha1.DisableSource{EnvVar: &v1alpha1.EnvVarDisableSource{}}
+	newStatus, err := MaybeNewDisableStatus(f.ctx, f.fc, source, nil)
+	require.NoError(t, err)
+	require.Equal(t, true, newStatus.Disabled)
+	require.Equal(t, v1alpha1.DisableStateError, newStatus.State)
+	require.Contains(t, newStatus.Reason, "has no name")
+}
+
+func TestMaybeNewCombinedDisableStatus(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		policy   v1alpha1.DisableSourcePolicy
+		cm1      *string
+		cm2      *string
+		expected v1alpha1.DisableState
+	}{
+		{"any, both disabled", v1alpha1.DisableSourcePolicyAnyDisabled, pointer.StringPtr("true"), pointer.StringPtr("true"), v1alpha1.DisableStateDisabled},
+		{"any, one disabled", v1alpha1.DisableSourcePolicyAnyDisabled, pointer.StringPtr("false"), pointer.StringPtr("true"), v1alpha1.DisableStateDisabled},
+		{"any, none disabled", v1alpha1.DisableSourcePolicyAnyDisabled, pointer.StringPtr("false"), pointer.StringPtr("false"), v1alpha1.DisableStateEnabled},
+		{"any, one disabled and one missing", v1alpha1.DisableSourcePolicyAnyDisabled, pointer.StringPtr("true"), nil, v1alpha1.DisableStateDisabled},
+		{"any, one enabled and one missing", v1alpha1.DisableSourcePolicyAnyDisabled, pointer.StringPtr("false"), nil, v1alpha1.DisableStatePending},
+		{"default is any", "", pointer.StringPtr("false"), pointer.StringPtr("true"), v1alpha1.DisableStateDisabled},
+		{"all, both disabled", v1alpha1.DisableSourcePolicyAllDisabled, pointer.StringPtr("true"), pointer.StringPtr("true"), v1alpha1.DisableStateDisabled},
+		{"all, one disabled", v1alpha1.DisableSourcePolicyAllDisabled, pointer.StringPtr("true"), pointer.StringPtr("false"), v1alpha1.DisableStateEnabled},
+		{"all, none disabled", v1alpha1.DisableSourcePolicyAllDisabled, pointer.StringPtr("false"), pointer.StringPtr("false"), v1alpha1.DisableStateEnabled},
+		{"all, one disabled and one missing", v1alpha1.DisableSourcePolicyAllDisabled, pointer.StringPtr("true"), nil, v1alpha1.DisableStatePending},
+		{"all, one enabled and one missing", v1alpha1.DisableSourcePolicyAllDisabled, pointer.StringPtr("false"), nil, v1alpha1.DisableStateEnabled},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newDisableFixture(t)
+			f.createConfigMapNamed(configMapName, tc.cm1)
+			if tc.cm2 != nil {
+				f.createConfigMapNamed(configMap2Name, tc.cm2)
+			}
+
+			sources := []v1alpha1.DisableSource{*disableSource(), *disableSourceNamed(configMap2Name)}
+			newStatus, err := MaybeNewCombinedDisableStatus(f.ctx, f.fc, clockwork.NewRealClock(), sources, tc.policy, nil)
+			require.NoError(t, err)
+			require.NotNil(t, newStatus)
+			require.Equal(t, tc.expected, newStatus.State)
+			require.Equal(t, tc.expected != v1alpha1.DisableStateEnabled, newStatus.Disabled)
+		})
+	}
+}
+
 type disableFixture struct {
 	t   *testing.T
 	fc  ctrlclient.Client
@@ -181,10 +314,68 @@ func (f *disableFixture) updateConfigMap(isDisabled *string) {
 	require.NoError(f.t, err)
 }
 
+func TestMaybeNewDisableStatusRetriesTransientErrors(t *testing.T) {
+	f := newDisableFixture(t)
+	f.createConfigMap(pointer.StringPtr("true"))
+	fc := &flakyClient{Client: f.fc, failures: len(configMapReadBackoff), err: unavailableErr()}
+
+	newStatus, err := MaybeNewDisableStatus(f.ctx, fc, disableSource(), nil)
+	require.NoError(t, err)
+	require.Equal(t, v1alpha1.DisableStateDisabled, newStatus.State)
+	require.Equal(t, len(configMapReadBackoff)+1, fc.calls)
+}
+
+func TestMaybeNewDisableStatusPersistentError(t *testing.T) {
+	f := newDisableFixture(t)
+	f.createConfigMap(pointer.StringPtr("false"))
+	fc := &flakyClient{Client: f.fc, failures: len(configMapReadBackoff) + 1, err: unavailableErr()}
+
+	prevStatus := &v1alpha1.DisableStatus{State: v1alpha1.DisableStateEnabled}
+	newStatus, err := MaybeNewDisableStatus(f.ctx, fc, disableSource(), prevStatus)
+	require.Error(t, err)
+	require.Nil(t, newStatus)
+	require.Equal(t, len(configMapReadBackoff)+1, fc.calls)
+}
+
+func TestMaybeNewDisableStatusDoesNotRetryPermanentErrors(t *testing.T) {
+	f := newDisableFixture(t)
+	f.createConfigMap(pointer.StringPtr("false"))
+	fc := &flakyClient{Client: f.fc, failures: 1, err: apierrors.NewForbidden(
+		schema.GroupResource{Resource: "configmaps"}, configMapName, fmt.Errorf("nope"))}
+
+	_, err := MaybeNewDisableStatus(f.ctx, fc, disableSource(), nil)
+	require.Error(t, err)
+	require.Equal(t, 1, fc.calls)
+}
+
+func unavailableErr() error {
+	return apierrors.NewServiceUnavailable("apiserver is restarting")
+}
+
+// flakyClient fails the first `failures` reads with err.
+type flakyClient struct {
+	ctrlclient.Client
+	failures int
+	err      error
+	calls    int
+}
+
+func (c *flakyClient) Get(ctx context.Context, key ctrlclient.ObjectKey, obj ctrlclient.Object, opts ...ctrlclient.GetOption) error {
+	c.calls++
+	if c.calls <= c.failures {
+		return c.err
+	}
+	return c.Client.Get(ctx, key, obj, opts...)
+}
+
 func disableSource() *v1alpha1.DisableSource {
+	return disableSourceNamed(configMapName)
+}
+
+func disableSourceNamed(name string) *v1alpha1.DisableSource {
 	return &v1alpha1.DisableSource{
 		ConfigMap: &v1alpha1.ConfigMapDisableSource{
-			Name: configMapName,
+			Name: name,
 			Key:  key,
 		},
 	}
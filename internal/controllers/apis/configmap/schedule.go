@@ -0,0 +1,96 @@
+package configmap
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+const scheduleTimeFormat = "15:04"
+
+// scheduleWindow is a daily time window, with times stored as minutes after midnight.
+type scheduleWindow struct {
+	start int
+	end   int
+	loc   *time.Location
+}
+
+func parseScheduleWindow(source v1alpha1.ScheduleDisableSource) (scheduleWindow, error) {
+	start, err := parseTimeOfDay(source.ActiveStart)
+	if err != nil {
+		return scheduleWindow{}, fmt.Errorf("invalid activeStart: %v", err)
+	}
+	end, err := parseTimeOfDay(source.ActiveEnd)
+	if err != nil {
+		return scheduleWindow{}, fmt.Errorf("invalid activeEnd: %v", err)
+	}
+
+	loc := time.Local
+	if source.TimeZone != "" {
+		loc, err = time.LoadLocation(source.TimeZone)
+		if err != nil {
+			return scheduleWindow{}, fmt.Errorf("invalid timeZone: %v", err)
+		}
+	}
+	return scheduleWindow{start: start, end: end, loc: loc}, nil
+}
+
+func parseTimeOfDay(s string) (int, error) {
+	t, err := time.Parse(scheduleTimeFormat, s)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func (w scheduleWindow) isActive(t time.Time) bool {
+	if w.start == w.end {
+		return true
+	}
+
+	t = t.In(w.loc)
+	minute := t.Hour()*60 + t.Minute()
+	if w.start < w.end {
+		return minute >= w.start && minute < w.end
+	}
+	// the window spans midnight
+	return minute >= w.start || minute < w.end
+}
+
+// next returns the first time after t that the window opens or closes.
+func (w scheduleWindow) next(t time.Time) time.Time {
+	t = t.In(w.loc)
+	var result time.Time
+	for day := 0; day <= 1; day++ {
+		for _, minute := range []int{w.start, w.end} {
+			c := time.Date(t.Year(), t.Month(), t.Day()+day, minute/60, minute%60, 0, 0, w.loc)
+			if c.After(t) && (result.IsZero() || c.Before(result)) {
+				result = c
+			}
+		}
+	}
+	return result
+}
+
+func scheduleDisableState(now time.Time, source v1alpha1.ScheduleDisableSource) (v1alpha1.DisableState, string, error) {
+	w, err := parseScheduleWindow(source)
+	if err != nil {
+		return v1alpha1.DisableStateError, fmt.Sprintf("error parsing schedule: %v", err), nil
+	}
+
+	if w.isActive(now) {
+		return v1alpha1.DisableStateEnabled, fmt.Sprintf("Inside active window %s-%s", source.ActiveStart, source.ActiveEnd), nil
+	}
+	return v1alpha1.DisableStateDisabled, fmt.Sprintf("Outside active window %s-%s", source.ActiveStart, source.ActiveEnd), nil
+}
+
+// NextScheduleTransition returns how long until the schedule's window next opens or closes,
+// so that reconcilers know when to re-check it. Returns 0 if the schedule never changes state.
+func NextScheduleTransition(now time.Time, source v1alpha1.ScheduleDisableSource) time.Duration {
+	w, err := parseScheduleWindow(source)
+	if err != nil || w.start == w.end {
+		return 0
+	}
+	return w.next(now).Sub(now)
+}
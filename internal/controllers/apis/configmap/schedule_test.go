@@ -0,0 +1,47 @@
+package configmap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestScheduleDisableState(t *testing.T) {
+	workHours := v1alpha1.ScheduleDisableSource{ActiveStart: "09:00", ActiveEnd: "17:30", TimeZone: "UTC"}
+	overnight := v1alpha1.ScheduleDisableSource{ActiveStart: "22:00", ActiveEnd: "06:00", TimeZone: "UTC"}
+
+	for _, tc := range []struct {
+		name     string
+		source   v1alpha1.ScheduleDisableSource
+		now      string
+		expected v1alpha1.DisableState
+		next     time.Duration
+	}{
+		{"before window", workHours, "08:59", v1alpha1.DisableStateDisabled, time.Minute},
+		{"window start", workHours, "09:00", v1alpha1.DisableStateEnabled, 8*time.Hour + 30*time.Minute},
+		{"inside window", workHours, "12:00", v1alpha1.DisableStateEnabled, 5*time.Hour + 30*time.Minute},
+		{"window end", workHours, "17:30", v1alpha1.DisableStateDisabled, 15*time.Hour + 30*time.Minute},
+		{"overnight, before midnight", overnight, "23:00", v1alpha1.DisableStateEnabled, 7 * time.Hour},
+		{"overnight, after midnight", overnight, "01:00", v1alpha1.DisableStateEnabled, 5 * time.Hour},
+		{"overnight, daytime", overnight, "12:00", v1alpha1.DisableStateDisabled, 10 * time.Hour},
+		{"empty window", v1alpha1.ScheduleDisableSource{ActiveStart: "09:00", ActiveEnd: "09:00"}, "12:00", v1alpha1.DisableStateEnabled, 0},
+		{"bad time", v1alpha1.ScheduleDisableSource{ActiveStart: "9am", ActiveEnd: "17:00"}, "12:00", v1alpha1.DisableStateError, 0},
+		{"bad time zone", v1alpha1.ScheduleDisableSource{ActiveStart: "09:00", ActiveEnd: "17:00", TimeZone: "Nowhere/Special"}, "12:00", v1alpha1.DisableStateError, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tod, err := time.Parse(scheduleTimeFormat, tc.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			now := time.Date(2021, time.March, 1, tod.Hour(), tod.Minute(), 0, 0, time.UTC)
+
+			state, _, err := scheduleDisableState(now, tc.source)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, state)
+			assert.Equal(t, tc.next, NextScheduleTransition(now, tc.source))
+		})
+	}
+}
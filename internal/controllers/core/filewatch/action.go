@@ -34,13 +34,19 @@ func HandleFileWatchUpdateStatusEvent(ctx context.Context, state *store.EngineSt
 }
 
 func processFileWatchStatus(ctx context.Context, state *store.EngineState, meta *metav1.ObjectMeta, status *v1alpha1.FileWatchStatus) {
-	if status.Error != "" || len(status.FileEvents) == 0 {
+	if status.Error != "" || status.SetupError != "" || len(status.FileEvents) == 0 {
 		return
 	}
 
 	// since the store is called on EVERY update, can always just look at the last event
 	latestEvent := status.FileEvents[len(status.FileEvents)-1]
 
+	// Events from before the current monitor started were already reported when they
+	// happened; the status is only being re-written because the monitor was restarted.
+	if latestEvent.Time.Before(&status.MonitorStartTime) {
+		return
+	}
+
 	targetID, err := targetID(meta)
 	if err != nil {
 		logger.Get(ctx).Debugf("Failed to get targetID for FileWatch %q to process update: %v", meta.GetName(), err)
@@ -0,0 +1,103 @@
+package filewatch
+
+import (
+	"archive/zip"
+	"os"
+	"sort"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// archiveEntry identifies the contents of an entry in a zip archive, using the checksum
+// from the archive's central directory, so the entry doesn't have to be decompressed.
+type archiveEntry struct {
+	crc32 uint32
+	size  uint64
+}
+
+// readArchive indexes the entries of the zip archive at path, keyed by their path in the
+// archive. Returns false if path can't be read as an archive.
+func readArchive(path string) (map[string]archiveEntry, bool) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, false
+	}
+	defer func() { _ = r.Close() }()
+
+	entries := make(map[string]archiveEntry, len(r.File))
+	for _, f := range r.File {
+		entries[f.Name] = archiveEntry{crc32: f.CRC32, size: f.UncompressedSize64}
+	}
+	return entries, true
+}
+
+// readArchives indexes the paths that are zip archives, keyed by archive path, for DiffArchives.
+func readArchives(paths []string) map[string]map[string]archiveEntry {
+	result := make(map[string]map[string]archiveEntry)
+	for _, p := range paths {
+		if entries, ok := readArchive(p); ok {
+			result[p] = entries
+		}
+	}
+	return result
+}
+
+// diffArchive returns the sorted paths of the entries that were added, changed, or removed.
+func diffArchive(old, new map[string]archiveEntry) []string {
+	var changed []string
+	for name, entry := range new {
+		if oldEntry, ok := old[name]; !ok || oldEntry != entry {
+			changed = append(changed, name)
+		}
+	}
+	for name := range old {
+		if _, ok := new[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// checkArchives re-reads the watched archives in paths, and returns the changes to their
+// entries, keyed by archive path, along with paths minus the archives whose entries
+// didn't change.
+//
+// An archive that was deleted has had all its entries removed. An archive that can't be
+// read (e.g., because it's only partly written) is still reported, without any changes,
+// and diffed against its last good contents next time.
+//
+// mu must be held before calling.
+func (w *watcher) checkArchives(paths []string) ([]string, map[string]v1alpha1.ArchiveChange) {
+	if len(w.archives) == 0 {
+		return paths, nil
+	}
+	var result []string
+	var changes map[string]v1alpha1.ArchiveChange
+	for _, path := range paths {
+		old, ok := w.archives[path]
+		if !ok {
+			result = append(result, path)
+			continue
+		}
+		entries, ok := readArchive(path)
+		if !ok {
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				result = append(result, path)
+				continue
+			}
+			entries = map[string]archiveEntry{}
+		}
+		w.archives[path] = entries
+		changed := diffArchive(old, entries)
+		if len(changed) == 0 {
+			continue
+		}
+		if changes == nil {
+			changes = make(map[string]v1alpha1.ArchiveChange)
+		}
+		changes[path] = v1alpha1.ArchiveChange{Path: path, Entries: changed}
+		result = append(result, path)
+	}
+	return result, changes
+}
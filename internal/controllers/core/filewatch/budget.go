@@ -0,0 +1,99 @@
+package filewatch
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/tilt-dev/tilt/internal/watch"
+)
+
+// MaxWatches caps the number of OS-level watches (e.g., inotify watch descriptors) held
+// across all FileWatches, to keep large projects from using up the OS limit. 0 means no cap.
+//
+// A FileWatch whose filesystem monitor wouldn't fit isn't started, and reports a
+// SetupError. It's retried with the usual setup backoff, and right away once other
+// watches free up enough of the budget. Watches are counted as reported in
+// Status.WatchCount, so Poll mode watches don't count.
+type MaxWatches int
+
+// MaxWatchesEnvVar sets MaxWatches. Unset or 0 means no cap.
+const MaxWatchesEnvVar = "TILT_MAX_WATCHES"
+
+func ProvideMaxWatches() MaxWatches {
+	n, err := strconv.Atoi(os.Getenv(MaxWatchesEnvVar))
+	if err != nil || n < 0 {
+		return 0
+	}
+	return MaxWatches(n)
+}
+
+// estimateWatches estimates the OS-level watches a native monitor on paths will need,
+// before it's started. On Linux, inotify needs a watch for every directory that isn't
+// ignored entirely (and the parent of each watched file). Elsewhere, each path is
+// watched recursively.
+//
+// A monitor that's shared with other FileWatches may need fewer.
+func estimateWatches(paths []string, m watch.PathMatcher) int {
+	if runtime.GOOS != "linux" {
+		return len(paths)
+	}
+	dirs := listDirectories(paths, m)
+	for _, p := range paths {
+		if info, err := os.Stat(p); err == nil && !info.IsDir() {
+			dirs[filepath.Dir(p)] = true
+		}
+	}
+	return len(dirs)
+}
+
+// freeWatches is the number of OS-level watches left in the budget, not counting the
+// ones held by the watch for name, which is about to be replaced.
+//
+// Caller must hold c.mu.
+func (c *Controller) freeWatches(name types.NamespacedName) int {
+	free := c.maxWatches
+	for _, w := range c.targetWatches {
+		if w.name == name {
+			continue
+		}
+		w.mu.Lock()
+		free -= int(w.status.WatchCount)
+		w.mu.Unlock()
+	}
+	return free
+}
+
+// checkWatchBudget reports an error if a monitor for name that holds needed OS-level
+// watches doesn't fit in the budget.
+//
+// Caller must hold c.mu.
+func (c *Controller) checkWatchBudget(name types.NamespacedName, needed int) error {
+	if c.maxWatches <= 0 {
+		return nil
+	}
+	if free := c.freeWatches(name); needed > free {
+		return fmt.Errorf("watch budget exceeded: needs %d OS watches, but only %d of %d are free (see %s)",
+			needed, max(free, 0), c.maxWatches, MaxWatchesEnvVar)
+	}
+	return nil
+}
+
+// budgetFreed is called after watches are removed, to retry the watches that didn't fit
+// in the budget and now do.
+//
+// Caller must hold c.mu.
+func (c *Controller) budgetFreed() {
+	if c.maxWatches <= 0 {
+		return
+	}
+	for _, w := range c.sortedWatches() {
+		if w.overBudget > 0 && w.overBudget <= c.freeWatches(w.name) {
+			c.requeuer.Add(w.name)
+		}
+	}
+}
@@ -15,11 +15,14 @@ package filewatch
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/jonboulle/clockwork"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -32,13 +35,13 @@ import (
 	"github.com/tilt-dev/tilt/internal/controllers/apis/configmap"
 	"github.com/tilt-dev/tilt/internal/controllers/core/filewatch/fsevent"
 	"github.com/tilt-dev/tilt/internal/controllers/indexer"
-	"github.com/tilt-dev/tilt/internal/ignore"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/store/filewatches"
 	"github.com/tilt-dev/tilt/internal/watch"
 	"github.com/tilt-dev/tilt/pkg/apis"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
 )
 
 // Controller reconciles a FileWatch object
@@ -48,27 +51,47 @@ type Controller struct {
 
 	targetWatches  map[types.NamespacedName]*watcher
 	fsWatcherMaker fsevent.WatcherMaker
-	timerMaker     fsevent.TimerMaker
+	debounceTimers fsevent.DebounceTimersMaker
 	mu             sync.Mutex
 	clock          clockwork.Clock
 	indexer        *indexer.Indexer
 	requeuer       *indexer.Requeuer
+	subscribers    subscribers
+
+	// Whether to warn about watches with overlapping paths. See DetectOverlapsEnvVar.
+	detectOverlaps bool
+
+	// How long each watch gets to flush its events and final status when the controller
+	// shuts down. See ShutdownDrainTimeoutEnvVar.
+	shutdownDrainTimeout time.Duration
+
+	// The most OS-level watches that all the watches together may hold, or 0 for no
+	// limit. See MaxWatchesEnvVar.
+	maxWatches int
 }
 
-func NewController(client ctrlclient.Client, store store.RStore, fsWatcherMaker fsevent.WatcherMaker, timerMaker fsevent.TimerMaker, scheme *runtime.Scheme, clock clockwork.Clock) *Controller {
+func NewController(client ctrlclient.Client, store store.RStore, fsWatcherMaker fsevent.WatcherMaker, debounceTimers fsevent.DebounceTimersMaker, scheme *runtime.Scheme, clock clockwork.Clock, maxWatches MaxWatches) *Controller {
 	return &Controller{
-		Client:         client,
-		Store:          store,
-		targetWatches:  make(map[types.NamespacedName]*watcher),
-		fsWatcherMaker: fsWatcherMaker,
-		timerMaker:     timerMaker,
-		indexer:        indexer.NewIndexer(scheme, indexFw),
-		requeuer:       indexer.NewRequeuer(),
-		clock:          clock,
+		Client:               client,
+		Store:                store,
+		targetWatches:        make(map[types.NamespacedName]*watcher),
+		fsWatcherMaker:       fsWatcherMaker,
+		debounceTimers:       debounceTimers,
+		indexer:              indexer.NewIndexer(scheme, indexFw),
+		requeuer:             indexer.NewRequeuer(),
+		clock:                clock,
+		detectOverlaps:       detectOverlapsFromEnv(),
+		shutdownDrainTimeout: shutdownDrainTimeoutFromEnv(),
+		maxWatches:           int(maxWatches),
 	}
 }
 
 func (c *Controller) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	// Subscribers may call back into the controller, so replayed events are only passed
+	// to them once mu has been released.
+	var replayed []v1alpha1.FileEvent
+	defer func() { c.subscribers.notify(req.NamespacedName, replayed) }()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	existing, hasExisting := c.targetWatches[req.NamespacedName]
@@ -84,9 +107,11 @@ func (c *Controller) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 
 	if apierrors.IsNotFound(err) || !fw.ObjectMeta.DeletionTimestamp.IsZero() {
 		if hasExisting {
+			c.drainWatch(ctx, existing, &fw, !apierrors.IsNotFound(err))
 			existing.cleanupWatch(ctx)
 			c.removeWatch(existing)
 		}
+		deleteEventMetrics(req.NamespacedName.Name)
 		c.Store.Dispatch(filewatches.NewFileWatchDeleteAction(req.NamespacedName.Name))
 		return ctrl.Result{}, nil
 	}
@@ -97,51 +122,155 @@ func (c *Controller) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	ctx = store.MustObjectLogHandler(ctx, c.Store, &fw)
 
 	// Get configmap's disable status
-	disableStatus, err := configmap.MaybeNewDisableStatus(ctx, c.Client, fw.Spec.DisableSource, fw.Status.DisableStatus)
+	disableStatus, err := configmap.MaybeNewCombinedDisableStatus(ctx, c.Client, c.clock, disableSources(fw.Spec), fw.Spec.DisableSourcePolicy, fw.Status.DisableStatus)
 	if err != nil {
+		// Don't guess at whether the watch should be disabled. Leave it as it is, record
+		// the error, and let the reconcile be retried.
+		status := fw.Status.DeepCopy()
+		if hasExisting {
+			status = existing.copyStatus()
+			status.DisableStatus = fw.Status.DisableStatus
+		}
+		status.Error = fmt.Sprintf("resolving disable status: %v", err)
+		status.ErrorTime = apis.NowMicro()
+		if updateErr := c.maybeUpdateObjectStatus(ctx, &fw, status); updateErr != nil {
+			return ctrl.Result{}, updateErr
+		}
 		return ctrl.Result{}, err
 	}
 
-	// Clean up existing filewatches if it's disabled
+	wasDisabled := fw.Status.DisableStatus != nil && fw.Status.DisableStatus.Disabled
+	if disableStatus.Disabled != wasDisabled {
+		if disableStatus.Disabled {
+			c.watchLogger(ctx, fw.Name).Infof("%s: disabled, file changes will be ignored (%s)", logPrefix(fw.Name, fw.Spec.LogPrefix), disableStatus.Reason)
+		} else {
+			c.watchLogger(ctx, fw.Name).Infof("%s: enabled (%s)", logPrefix(fw.Name, fw.Spec.LogPrefix), disableStatus.Reason)
+		}
+	}
+
+	// Clean up existing filewatches if it's disabled, unless they're only paused.
 	result := ctrl.Result{}
-	if disableStatus.State == v1alpha1.DisableStateDisabled {
+	paused := disableStatus.State == v1alpha1.DisableStateDisabled && fw.Spec.DisableMode == v1alpha1.FileWatchDisableModePause
+	if disableStatus.State == v1alpha1.DisableStateDisabled && !paused {
 		if hasExisting {
 			existing.cleanupWatch(ctx)
 			c.removeWatch(existing)
 		}
 	} else {
+		ignores, configMapPatterns, err := c.resolveIgnores(ctx, fw.Spec)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+
 		// Determine if we the filewatch needs to be refreshed.
-		shouldRestart := !hasExisting || !apicmp.DeepEqual(existing.spec, fw.Spec)
+		shouldRestart := !hasExisting || !apicmp.DeepEqual(monitorSpec(existing.spec), monitorSpec(fw.Spec)) ||
+			!apicmp.DeepEqual(existing.ignores, ignores)
 		if hasExisting && !shouldRestart {
+			existing.applySpec(fw.Spec)
 			shouldRestart, result = existing.shouldRestart()
 		}
+		if hasExisting && (existing.needsRestart() || existing.restartDue() || existing.setupRetryDue() ||
+			(existing.overBudget > 0 && existing.overBudget <= c.freeWatches(req.NamespacedName))) {
+			shouldRestart = true
+		}
 
 		if shouldRestart {
-			c.addOrReplace(ctx, req.NamespacedName, &fw)
+			c.addOrReplace(ctx, req.NamespacedName, &fw, ignores, configMapPatterns, paused)
+		} else {
+			existing.setPaused(paused)
 		}
 	}
 
 	watch, ok := c.targetWatches[req.NamespacedName]
-	status := &v1alpha1.FileWatchStatus{DisableStatus: disableStatus}
+	status := &v1alpha1.FileWatchStatus{
+		DisableStatus:   disableStatus,
+		LastRescanToken: fw.Status.LastRescanToken,
+		LastReplayToken: fw.Status.LastReplayToken,
+	}
 	if ok {
+		replayed = watch.replay(fw.Spec.ReplayToken)
+		if requeueAfter := watch.updateStaleCondition(); requeueAfter > 0 {
+			result = minRequeue(result, requeueAfter)
+		}
 		status = watch.copyStatus()
 		status.DisableStatus = disableStatus
 	}
 
-	err = c.maybeUpdateObjectStatus(ctx, &fw, status)
-	if err != nil {
-		return ctrl.Result{}, err
+	holdStatus := false
+	if ok && !apicmp.DeepEqual(status, &fw.Status) {
+		if delay := watch.statusWriteDelay(); delay > 0 && !statusNeedsPromptWrite(&fw.Status, status) {
+			// Hold the status until the interval has passed. Everything recorded in
+			// the meantime goes out in a single write.
+			c.scheduleStatusWrite(watch, delay)
+			holdStatus = true
+		} else {
+			watch.markStatusWritten()
+		}
+	}
+
+	if !holdStatus {
+		err = c.maybeUpdateObjectStatus(ctx, &fw, status)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Nothing notifies us when a sentinel file appears or disappears, so poll for it.
+	// Likewise, re-check schedules when the window opens or closes.
+	for _, ds := range disableSources(fw.Spec) {
+		var requeueAfter time.Duration
+		if ds.File != nil {
+			requeueAfter = configmap.FileDisablePollInterval
+		} else if ds.Schedule != nil {
+			requeueAfter = configmap.NextScheduleTransition(c.clock.Now(), *ds.Schedule)
+		}
+		if requeueAfter > 0 {
+			result = minRequeue(result, requeueAfter)
+		}
 	}
 
 	return result, nil
 }
 
+// minRequeue requeues at the earlier of the existing result's requeue and requeueAfter.
+func minRequeue(result ctrl.Result, requeueAfter time.Duration) ctrl.Result {
+	if result.RequeueAfter == 0 || result.RequeueAfter > requeueAfter {
+		result.RequeueAfter = requeueAfter
+	}
+	return result
+}
+
+// statusNeedsPromptWrite reports whether a status change is one that consumers need to see
+// right away, even when StatusUpdateInterval is holding back file events.
+func statusNeedsPromptWrite(oldStatus, newStatus *v1alpha1.FileWatchStatus) bool {
+	return !apicmp.DeepEqual(oldStatus.DisableStatus, newStatus.DisableStatus) ||
+		oldStatus.Error != newStatus.Error ||
+		oldStatus.SetupError != newStatus.SetupError
+}
+
+// scheduleStatusWrite reconciles the watch again after delay, so that a status held back
+// by StatusUpdateInterval is written. Does nothing if a write is already scheduled.
+func (c *Controller) scheduleStatusWrite(w *watcher, delay time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.statusWriteTimer != nil {
+		return
+	}
+	w.statusWriteTimer = c.clock.AfterFunc(delay, func() {
+		w.mu.Lock()
+		w.statusWriteTimer = nil
+		w.mu.Unlock()
+		c.requeuer.Add(w.name)
+	})
+}
+
 func (c *Controller) maybeUpdateObjectStatus(ctx context.Context, fw *v1alpha1.FileWatch, newStatus *v1alpha1.FileWatchStatus) error {
 	if apicmp.DeepEqual(newStatus, &fw.Status) {
 		return nil
 	}
 
 	oldError := fw.Status.Error
+	oldSetupError := fw.Status.SetupError
 
 	update := fw.DeepCopy()
 	update.Status = *newStatus
@@ -149,9 +278,13 @@ func (c *Controller) maybeUpdateObjectStatus(ctx context.Context, fw *v1alpha1.F
 	if err != nil {
 		return err
 	}
+	recordWriteLatencyMetrics(fw.Name, &fw.Status, &update.Status)
 
 	if update.Status.Error != "" && oldError != update.Status.Error {
-		logger.Get(ctx).Errorf("filewatch %s: %s", fw.Name, update.Status.Error)
+		c.watchLogger(ctx, fw.Name).Errorf("%s: %s", logPrefix(fw.Name, fw.Spec.LogPrefix), update.Status.Error)
+	}
+	if update.Status.SetupError != "" && oldSetupError != update.Status.SetupError {
+		c.watchLogger(ctx, fw.Name).Errorf("%s: %s", logPrefix(fw.Name, fw.Spec.LogPrefix), update.Status.SetupError)
 	}
 
 	c.Store.Dispatch(NewFileWatchUpdateStatusAction(update))
@@ -168,71 +301,329 @@ func (c *Controller) CreateBuilder(mgr ctrl.Manager) (*builder.Builder, error) {
 	return b, nil
 }
 
+// drainWatch records any events that the filesystem monitor had already received before the
+// watch is torn down, so that consumers see the final batch.
+//
+// If the object still exists (i.e., it's waiting on finalizers), the events are written to its
+// status. Otherwise, they're only dispatched to the engine.
+func (c *Controller) drainWatch(ctx context.Context, w *watcher, fw *v1alpha1.FileWatch, exists bool) {
+	status, ok := w.drain(ctx, drainTimeout)
+	if !ok {
+		return
+	}
+	if exists {
+		if err := c.maybeUpdateObjectStatus(ctx, fw, status); err != nil {
+			logger.Get(ctx).Debugf("Failed to record drained file events for %q: %v", w.name.String(), err)
+		}
+		return
+	}
+	c.Store.Dispatch(FileWatchUpdateStatusAction{ObjectMeta: w.objectMeta.DeepCopy(), Status: status})
+}
+
 // removeWatch removes a watch from the map. It does NOT stop the watcher or free up resources.
 //
 // mu must be held before calling.
 func (c *Controller) removeWatch(tw *watcher) {
 	if entry, ok := c.targetWatches[tw.name]; ok && tw == entry {
 		delete(c.targetWatches, tw.name)
+		activeWatches.WithLabelValues(tw.name.Namespace).Dec()
+		c.budgetFreed()
 	}
 }
 
-func (c *Controller) addOrReplace(ctx context.Context, name types.NamespacedName, fw *v1alpha1.FileWatch) {
+// addOrReplace starts a new filesystem monitor for fw, replacing any existing one.
+//
+// ignores are the spec's ignores, with the configMapPatterns patterns from ConfigMaps resolved.
+// If paused, the monitor is started, but its events are discarded.
+func (c *Controller) addOrReplace(ctx context.Context, name types.NamespacedName, fw *v1alpha1.FileWatch, ignores []v1alpha1.IgnoreDef, configMapPatterns int32, paused bool) {
 	existing, hasExisting := c.targetWatches[name]
-	status := &v1alpha1.FileWatchStatus{}
+	status := &v1alpha1.FileWatchStatus{LastRescanToken: fw.Status.LastRescanToken, LastReplayToken: fw.Status.LastReplayToken}
 	w := &watcher{
 		name:           name,
+		objectMeta:     *fw.ObjectMeta.DeepCopy(),
 		spec:           *fw.Spec.DeepCopy(),
+		ignores:        ignores,
 		clock:          c.clock,
 		restartBackoff: time.Second,
+		lastActive:     c.clock.Now(),
+		paused:         paused,
 	}
-	if hasExisting && apicmp.DeepEqual(existing.spec, w.spec) {
+	if hasExisting && apicmp.DeepEqual(monitorSpec(existing.spec), monitorSpec(w.spec)) {
 		w.restartBackoff = existing.restartBackoff
+		w.restartCount = existing.restartCount
+		w.setupFailures = existing.setupFailures
 		status.Error = existing.status.Error
+		status.ErrorTime = existing.status.ErrorTime
+	}
+
+	// Keep the event history from the previous monitor. After a controller restart there's no
+	// previous monitor in memory, but the history is still on the object's status, so a
+	// re-established watch picks up where it left off instead of starting empty.
+	prevStatus := &fw.Status
+	if hasExisting {
+		prevStatus = existing.status
 	}
+	status.FileEvents = prevStatus.FileEvents
+	if maxHistory := w.maxEventHistory(); len(status.FileEvents) > maxHistory {
+		status.FileEvents = status.FileEvents[len(status.FileEvents)-maxHistory:]
+	}
+	status.LastEventTime = prevStatus.LastEventTime
+	status.TotalEventCount = prevStatus.TotalEventCount
+	status.TotalFilesSeen = prevStatus.TotalFilesSeen
+
 	if hasExisting {
-		status.FileEvents = existing.status.FileEvents
-		status.LastEventTime = existing.status.LastEventTime
+		// Copy, since the existing watch's conditions are updated when it's cleaned up.
+		status.Conditions = append([]metav1.Condition(nil), existing.status.Conditions...)
+		if fw.Spec.MaxEventsPerSecond <= 0 {
+			meta.RemoveStatusCondition(&status.Conditions, v1alpha1.FileWatchConditionThrottled)
+		}
+		if !fw.Spec.SelfTest {
+			meta.RemoveStatusCondition(&status.Conditions, v1alpha1.FileWatchConditionSelfTestPassed)
+		}
+		status.LastRescanToken = existing.status.LastRescanToken
+		status.LastReplayToken = existing.status.LastReplayToken
+		w.lastActive = existing.lastActive
+		w.lastStatusWrite = existing.lastStatusWrite
 	}
 
-	ignoreMatcher := ignore.CreateFileChangeFilter(fw.Spec.Ignores)
 	startFileChangeLoop := false
-	notify, err := c.fsWatcherMaker(
-		append([]string{}, fw.Spec.WatchedPaths...),
-		ignoreMatcher,
-		logger.Get(ctx))
-	if err != nil {
-		status.Error = fmt.Sprintf("filewatch init: %v", err)
+	startRescan := false
+	startBaseline := false
+	var ignoreMatcher model.PathMatcher
+	var notify watch.Notify
+	var budgetErr error
+	watchedPaths, globMatcher, err := resolveWatchedPaths(fw.Spec.WatchedPaths)
+	if err == nil {
+		err = checkIgnoreRegexes(fw.Spec.Ignores)
+	}
+	if err == nil {
+		w.roots = append([]string(nil), watchedPaths...)
+		w.rootDirs = rootDirectories(w.roots)
+		setRootMissingCondition(status, w.roots, c.clock.Now())
+		rules := newIgnoreRules(fw.Spec, ignores, watchedPaths, globMatcher, c.clock)
+		ignoreMatcher = rules.matcher()
+		w.ignoreFiles = gitignoreFiles(ignores)
+		status.ActiveIgnoreRules = countIgnoreRules(fw.Spec, configMapPatterns, w.ignoreFiles)
+		watchedPaths, ignoreMatcher, w.hiddenIgnoreFiles = watchIgnoreFiles(watchedPaths, ignoreMatcher, w.ignoreFiles)
+		if fw.Spec.DebugIgnores {
+			ignoreMatcher = newDebugIgnoreMatcher(logPrefix(name.Name, fw.Spec.LogPrefix), ignoreMatcher, rules, logger.Get(ctx))
+		}
+		if fw.Spec.SkipUnreadable {
+			status.SkippedPaths = unreadableDirs(watchedPaths, ignoreMatcher)
+			for _, p := range status.SkippedPaths {
+				c.watchLogger(ctx, name.Name).Warnf("%s: skipping %s, which can't be read", logPrefix(name.Name, fw.Spec.LogPrefix), p)
+			}
+			if len(status.SkippedPaths) != 0 {
+				ignoreMatcher = skipDirsMatcher{dirs: status.SkippedPaths, matcher: ignoreMatcher}
+			}
+		}
+		if fw.Spec.WatchSymlinkTargets {
+			w.symlinkTargets = readSymlinkTargets(watchedPaths)
+			watchedPaths, ignoreMatcher = watchSymlinkTargets(watchedPaths, ignoreMatcher, w.symlinkTargets)
+		}
+		if fw.Spec.DiffArchives {
+			w.archives = readArchives(w.roots)
+		}
+		if fw.Spec.TrackMoves {
+			w.fileIDs = listFileIDs(w.roots, ignoreMatcher)
+		}
+		if fw.Spec.CrossMountBoundaries {
+			watchedPaths = append(watchedPaths, mountPointsUnder(ctx, watchedPaths, ignoreMatcher)...)
+		}
+		if fw.Spec.FollowSymlinks {
+			w.symlinks = followSymlinks(watchedPaths, ignoreMatcher)
+			for _, real := range w.symlinks {
+				watchedPaths = append(watchedPaths, real)
+			}
+			ignoreMatcher = symlinkMatcher{links: w.symlinks, matcher: ignoreMatcher}
+		}
+		if fw.Spec.ReportDirectoryEvents {
+			w.directories = listDirectories(watchedPaths, ignoreMatcher)
+		}
+		w.ignoreMatcher = ignoreMatcher
+		if fw.Spec.WatchMode == v1alpha1.FileWatchModePoll {
+			notify, err = watch.NewPollingWatcher(
+				watchedPaths,
+				ignoreMatcher,
+				fw.Spec.PollInterval.Duration,
+				watch.ChangeDetection(fw.Spec.ChangeDetection),
+				c.clock,
+				logger.Get(ctx))
+		} else {
+			recentlyIgnored := fw.Status.RecentlyIgnored
+			if hasExisting && existing.ignoreCounter != nil {
+				recentlyIgnored = existing.ignoreCounter.recentlyIgnored()
+			}
+			w.ignoreCounter = newIgnoreCounter(ignoreMatcher, watchedPaths, recentlyIgnored)
+			// Check the budget before the OS-level watches are allocated.
+			if c.maxWatches > 0 {
+				needed := estimateWatches(watchedPaths, ignoreMatcher)
+				if budgetErr = c.checkWatchBudget(name, needed); budgetErr != nil {
+					w.overBudget = needed
+				}
+			}
+			if budgetErr == nil {
+				notify, err = c.fsWatcherMaker(
+					watchedPaths,
+					w.ignoreCounter,
+					logger.Get(ctx))
+			}
+		}
+	}
+	if budgetErr != nil {
+		status.SetupError = fmt.Sprintf("filewatch init: %v", budgetErr)
+		status.SetupErrorTime = apis.NowMicro()
+		setReadyCondition(status, metav1.ConditionFalse, "OverBudget", status.SetupError, c.clock.Now())
+	} else if err != nil {
+		status.SetupError = fmt.Sprintf("filewatch init: %v", err)
+		status.SetupErrorTime = apis.NowMicro()
+		setReadyCondition(status, metav1.ConditionFalse, "SetupFailed", status.SetupError, c.clock.Now())
 	} else if err := notify.Start(); err != nil {
-		status.Error = fmt.Sprintf("filewatch init: %v", err)
+		status.SetupError = fmt.Sprintf("filewatch init: %v", err)
+		status.SetupErrorTime = apis.NowMicro()
+		setReadyCondition(status, metav1.ConditionFalse, "SetupFailed", status.SetupError, c.clock.Now())
 
 		// Close the notify immediately, but don't add it to the watcher object. The
 		// watcher object is still needed to handle backoff.
 		_ = notify.Close()
+	} else if err := c.checkWatchBudget(name, watch.WatchCount(notify)); err != nil {
+		// The estimate can fall short (e.g., if directories were created in the meantime).
+		status.SetupError = fmt.Sprintf("filewatch init: %v", err)
+		status.SetupErrorTime = apis.NowMicro()
+		setReadyCondition(status, metav1.ConditionFalse, "OverBudget", status.SetupError, c.clock.Now())
+		w.overBudget = watch.WatchCount(notify)
+		_ = notify.Close()
 	} else {
 		startFileChangeLoop = true
 	}
+	if !startFileChangeLoop {
+		w.setupFailures++
+		backoff := setupRetryBackoff(w.setupFailures)
+		w.setupRetryAt = c.clock.Now().Add(backoff)
+		w.setupRetryTimer = c.clock.AfterFunc(backoff, func() {
+			c.requeuer.Add(name)
+		})
+		if w.setupFailures >= maxSetupAttempts {
+			setReadyCondition(status, metav1.ConditionFalse, "SetupCircuitOpen",
+				fmt.Sprintf("filesystem monitor failed to start %d times in a row, retrying every %s until the spec changes: %s",
+					w.setupFailures, backoff, status.SetupError),
+				c.clock.Now())
+		}
+	}
 
 	if hasExisting {
+		if startFileChangeLoop && existing.restartDue() {
+			// The monitors overlapped while the new one was starting, so keep whatever
+			// the old one received in the meantime.
+			handOffEvents(ctx, existing, status, w.maxEventHistory())
+		}
 		// Clean up the existing watch AFTER the new watch has been started.
 		existing.cleanupWatch(ctx)
 	}
 
-	ctx, cancel := context.WithCancel(ctx)
-	w.cancel = cancel
+	ctx, cancel := context.WithCancelCause(ctx)
+	w.cancel = func() { cancel(errWatchStopped) }
 
 	if startFileChangeLoop {
 		w.notify = notify
+		w.drained = make(chan struct{})
 		status.MonitorStartTime = apis.NowMicro()
-		go c.dispatchFileChangesLoop(ctx, w)
+		w.startedAt = c.clock.Now()
+		status.DebounceDuration = metav1.Duration{Duration: w.debounceDuration()}
+		status.WatchedPaths = resolveSymlinks(watchedPaths)
+		if fw.Spec.RecordBaseline {
+			if prevStatus.Baseline != nil && slices.Equal(prevStatus.WatchedPaths, status.WatchedPaths) {
+				status.Baseline = prevStatus.Baseline
+			} else {
+				startBaseline = true
+			}
+		}
+		status.WatchCount = int32(watch.WatchCount(notify))
+		status.EstimatedWatchBytes = w.estimatedWatchBytes(int(status.WatchCount))
+		setReadyCondition(status, metav1.ConditionTrue, "MonitorStarted", "filesystem monitor is running", c.clock.Now())
+		if fw.Spec.SelfTest {
+			setSelfTestCondition(status, metav1.ConditionUnknown, "InProgress", "waiting for a file event from the self-test", c.clock.Now())
+		}
+		if token := fw.Spec.ForceRescanToken; token != "" && token != status.LastRescanToken {
+			status.LastRescanToken = token
+			startRescan = true
+		}
+		w.monitorStartedAt = c.clock.Now()
+		if interval := fw.Spec.WatchRestartInterval.Duration; interval > 0 {
+			w.restartTimer = c.clock.AfterFunc(interval, func() {
+				c.requeuer.Add(name)
+			})
+		}
 	}
 
 	w.status = status
+	if startFileChangeLoop && c.detectOverlaps &&
+		(!hasExisting || !slices.Equal(existing.status.WatchedPaths, status.WatchedPaths)) {
+		c.warnOverlaps(ctx, w, status.WatchedPaths)
+	}
+	if startFileChangeLoop {
+		go c.dispatchFileChangesLoop(ctx, w)
+		if startRescan {
+			go c.rescan(ctx, w, watchedPaths, ignoreMatcher)
+		}
+		if startBaseline {
+			go c.recordBaseline(ctx, w, watchedPaths, ignoreMatcher)
+		}
+		if fw.Spec.SelfTest {
+			go c.selfTest(ctx, w)
+		}
+	}
 	c.targetWatches[name] = w
+	if !hasExisting {
+		activeWatches.WithLabelValues(name.Namespace).Inc()
+	}
+}
+
+// handOffEvents adds the events that the old watcher recorded after status was copied from
+// it, stopping its monitor to make sure that nothing else comes in.
+func handOffEvents(ctx context.Context, old *watcher, status *v1alpha1.FileWatchStatus, maxHistory int) {
+	drained, ok := old.drain(ctx, drainTimeout)
+	if !ok {
+		return
+	}
+	missed := int(drained.TotalEventCount - status.TotalEventCount)
+	if missed <= 0 {
+		return
+	}
+	if missed > len(drained.FileEvents) {
+		missed = len(drained.FileEvents)
+	}
+	status.FileEvents = append(status.FileEvents, drained.FileEvents[len(drained.FileEvents)-missed:]...)
+	if len(status.FileEvents) > maxHistory {
+		status.FileEvents = status.FileEvents[len(status.FileEvents)-maxHistory:]
+	}
+	status.LastEventTime = drained.LastEventTime
+	status.TotalEventCount = drained.TotalEventCount
+	status.TotalFilesSeen = drained.TotalFilesSeen
 }
 
 func (c *Controller) dispatchFileChangesLoop(ctx context.Context, w *watcher) {
-	eventsCh := fsevent.Coalesce(c.timerMaker, w.notify.Events())
+	var warnedFull sync.Once
+	bufferedCh := fsevent.Buffer(ctx, int(w.spec.EventBufferSize), w.notify.Events(), func() {
+		warnedFull.Do(func() {
+			c.watchLogger(ctx, w.name.Name).Warnf("%s: event buffer is full, file changes will be delayed. "+
+				"Consider increasing spec.eventBufferSize (currently %d)",
+				logPrefix(w.name.Name, w.spec.LogPrefix), w.eventBufferSize())
+		})
+	})
+	coalesce := fsevent.Coalesce
+	if w.spec.PerDirectoryDebounce {
+		coalesce = fsevent.CoalescePerDir
+	}
+	eventsCh := coalesce(w.debounceTimers(c.debounceTimers), int(w.spec.MaxBatchSize), bufferedCh)
+	errorsCh := w.notify.Errors()
+
+	var summaryCh <-chan time.Time
+	if w.ignoreCounter != nil {
+		ticker := c.clock.NewTicker(ignoreSummaryInterval)
+		defer ticker.Stop()
+		summaryCh = ticker.Chan()
+	}
 
 	defer func() {
 		c.mu.Lock()
@@ -240,12 +631,16 @@ func (c *Controller) dispatchFileChangesLoop(ctx context.Context, w *watcher) {
 		w.cleanupWatch(ctx)
 		c.requeuer.Add(w.name)
 	}()
+	defer close(w.drained)
 
 	for {
 		select {
-		case err, ok := <-w.notify.Errors():
+		case err, ok := <-errorsCh:
 			if !ok {
-				return
+				// The monitor was closed, but it may still have events to flush,
+				// so keep going until the event channel is closed too.
+				errorsCh = nil
+				continue
 			}
 
 			if watch.IsWindowsShortReadError(err) {
@@ -259,30 +654,80 @@ func (c *Controller) dispatchFileChangesLoop(ctx context.Context, w *watcher) {
 					err))
 			} else if err.Error() == fsnotify.ErrEventOverflow.Error() {
 				w.recordError(fmt.Errorf("%s\nerror: %v", DetectedOverflowErrMsg, err))
+			} else if watch.IsWatchLimitError(err) {
+				w.recordError(fmt.Errorf("%s: %s\nerror: %v", WatchLimitErrReason, DetectedWatchLimitErrMsg, err))
 			} else {
 				w.recordError(err)
+
+				// Assume the monitor can no longer be trusted to deliver events. Returning
+				// tears it down, and the next reconcile restarts it after a backoff.
+				return
 			}
+
+			// The monitor keeps running after it drops events or runs out of watches, and
+			// restarting it wouldn't help, so only report the error.
 			c.requeuer.Add(w.name)
 
 		case <-ctx.Done():
+			if isShutdown(ctx) {
+				c.drainOnShutdown(ctx, w, eventsCh)
+			}
 			return
-		case fsEvents, ok := <-eventsCh:
+		case <-summaryCh:
+			for _, line := range w.ignoreCounter.summarize() {
+				c.watchLogger(ctx, w.name.Name).Infof("%s: %s", logPrefix(w.name.Name, w.spec.LogPrefix), line)
+			}
+			if w.ignoreCounter.takeRecentChanged() {
+				// Ignored paths don't trigger a reconcile on their own, so pick them up here
+				// in case nothing else has been written since.
+				c.requeuer.Add(w.name)
+			}
+		case batch, ok := <-eventsCh:
 			if !ok {
 				return
 			}
-			w.recordEvent(fsEvents)
+			c.logFileChanges(ctx, w.name.Name, batch.Events)
+			c.subscribers.notify(w.name, w.recordEvent(batch.Events, flushReason(batch.Reason)))
 			c.requeuer.Add(w.name)
+			if w.needsRestart() {
+				// The ignore rules and symlink targets are baked into the monitor, so it needs to be restarted.
+				// Cancel first, so that this isn't treated as an unexpected close.
+				w.cancel()
+				return
+			}
 		}
 	}
 }
 
+// rescan reports every file under the watched paths in a single batch, so that consumers can
+// treat the entire tree as changed.
+func (c *Controller) rescan(ctx context.Context, w *watcher, paths []string, ignoreMatcher watch.PathMatcher) {
+	events := rescanFiles(ctx, paths, ignoreMatcher)
+	if ctx.Err() != nil || len(events) == 0 {
+		return
+	}
+	c.subscribers.notify(w.name, w.recordEvent(events, v1alpha1.FileEventFlushReasonForceRescan))
+	c.requeuer.Add(w.name)
+}
+
+// recordBaseline lists every file under paths that isn't ignored by ignoreMatcher in the
+// status, as the state of the watched paths before any file events.
+func (c *Controller) recordBaseline(ctx context.Context, w *watcher, paths []string, ignoreMatcher watch.PathMatcher) {
+	events := rescanFiles(ctx, paths, ignoreMatcher)
+	if ctx.Err() != nil {
+		return
+	}
+	w.setBaseline(events)
+	c.requeuer.Add(w.name)
+}
+
 // Find all the objects to watch based on the Filewatch model
 func indexFw(obj ctrlclient.Object) []indexer.Key {
 	fw := obj.(*v1alpha1.FileWatch)
 	result := []indexer.Key{}
 
-	if fw.Spec.DisableSource != nil {
-		cm := fw.Spec.DisableSource.ConfigMap
+	for _, ds := range disableSources(fw.Spec) {
+		cm := ds.ConfigMap
 		if cm != nil {
 			gvk := v1alpha1.SchemeGroupVersion.WithKind("ConfigMap")
 			result = append(result, indexer.Key{
@@ -292,5 +737,49 @@ func indexFw(obj ctrlclient.Object) []indexer.Key {
 		}
 	}
 
+	for _, ignore := range fw.Spec.Ignores {
+		if cm := ignore.PatternsConfigMap; cm != nil {
+			gvk := v1alpha1.SchemeGroupVersion.WithKind("ConfigMap")
+			result = append(result, indexer.Key{
+				Name: types.NamespacedName{Name: cm.Name},
+				GVK:  gvk,
+			})
+		}
+	}
+
 	return result
 }
+
+// resolveIgnores returns a copy of the spec's ignores with patterns read from ConfigMaps added to Patterns,
+// and patterns without a BasePath anchored to the watched paths, along with the number of patterns read
+// from ConfigMaps.
+//
+// A missing ConfigMap or key adds no patterns, since the ConfigMap may be created later.
+func (c *Controller) resolveIgnores(ctx context.Context, spec v1alpha1.FileWatchSpec) ([]v1alpha1.IgnoreDef, int32, error) {
+	var result []v1alpha1.IgnoreDef
+	var configMapPatterns int32
+	for _, def := range spec.Ignores {
+		def := *def.DeepCopy()
+		if src := def.PatternsConfigMap; src != nil {
+			var cm v1alpha1.ConfigMap
+			err := c.Client.Get(ctx, types.NamespacedName{Name: src.Name}, &cm)
+			if err != nil && !apierrors.IsNotFound(err) {
+				return nil, 0, fmt.Errorf("reading ignore patterns from ConfigMap %q: %v", src.Name, err)
+			}
+			patterns := parsePatterns(cm.Data[src.Key])
+			configMapPatterns += int32(len(patterns))
+			def.Patterns = append(def.Patterns, patterns...)
+			def.PatternsConfigMap = nil
+		}
+		result = append(result, def)
+	}
+	return rootIgnores(result, spec.WatchedPaths), configMapPatterns, nil
+}
+
+// disableSources merges the singular DisableSource with DisableSources.
+func disableSources(spec v1alpha1.FileWatchSpec) []v1alpha1.DisableSource {
+	if spec.DisableSource == nil {
+		return spec.DisableSources
+	}
+	return append([]v1alpha1.DisableSource{*spec.DisableSource}, spec.DisableSources...)
+}
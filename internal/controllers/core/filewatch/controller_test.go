@@ -1,26 +1,45 @@
 package filewatch
 
 import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
 
 	"github.com/jonboulle/clockwork"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/component-base/metrics/testutil"
+	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/tilt-dev/fsnotify"
+	"github.com/tilt-dev/tilt/internal/controllers/apis/configmap"
 	"github.com/tilt-dev/tilt/internal/controllers/core/filewatch/fsevent"
 	"github.com/tilt-dev/tilt/internal/controllers/fake"
+	"github.com/tilt-dev/tilt/internal/ignore"
 	"github.com/tilt-dev/tilt/internal/store"
-	"github.com/tilt-dev/tilt/internal/testutils/configmap"
+	storefilewatches "github.com/tilt-dev/tilt/internal/store/filewatches"
+	tiltconfigmap "github.com/tilt-dev/tilt/internal/testutils/configmap"
+	"github.com/tilt-dev/tilt/internal/testutils/manifestbuilder"
 	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
 	"github.com/tilt-dev/tilt/internal/watch"
 	"github.com/tilt-dev/tilt/pkg/apis"
@@ -60,6 +79,7 @@ type fixture struct {
 	fakeMultiWatcher *fsevent.FakeMultiWatcher
 	fakeTimerMaker   fsevent.FakeTimerMaker
 	clock            clockwork.FakeClock
+	changeCount      int
 }
 
 func newFixture(t *testing.T) *fixture {
@@ -72,7 +92,7 @@ func newFixture(t *testing.T) *fixture {
 	cfb := fake.NewControllerFixtureBuilder(t)
 	testingStore := NewTestingStore(cfb.OutWriter())
 	clock := clockwork.NewFakeClock()
-	controller := NewController(cfb.Client, testingStore, fakeMultiWatcher.NewSub, timerMaker.Maker(), filewatches.NewScheme(), clock)
+	controller := NewController(cfb.Client, testingStore, fakeMultiWatcher.NewSub, timerMaker.Maker(), filewatches.NewScheme(), clock, 0)
 
 	return &fixture{
 		ControllerFixture: cfb.WithRequeuer(controller.requeuer).Build(controller),
@@ -96,11 +116,36 @@ func (f *fixture) ChangeFile(elem ...string) {
 	f.t.Helper()
 	path, err := filepath.Abs(f.tmpdir.JoinPath(elem...))
 	require.NoErrorf(f.t, err, "Could not get abs path for %q", path)
-	select {
-	case f.fakeMultiWatcher.Events <- watch.NewFileEvent(path):
-	default:
-		f.t.Fatal("emitting a FileEvent would block. Perhaps there are too many events or the buffer size is too small.")
-	}
+	f.fakeMultiWatcher.RequireEmit(f.t, watch.NewFileEvent(path))
+	f.changeCount++
+}
+
+// InOneBatch holds the debounce timers while changeFn emits file changes, so that they all
+// end up in the same batch.
+func (f *fixture) InOneBatch(key types.NamespacedName, changeFn func()) {
+	f.t.Helper()
+	f.controller.mu.Lock()
+	fakeWatcher := f.controller.targetWatches[key].notify.(*fsevent.FakeWatcher)
+	f.controller.mu.Unlock()
+	before := fakeWatcher.TotalEventCount()
+	changesBefore := f.changeCount
+
+	f.fakeTimerMaker.RestTimerLock.Lock()
+	f.fakeTimerMaker.MaxTimerLock.Lock()
+	defer f.fakeTimerMaker.MaxTimerLock.Unlock()
+	defer f.fakeTimerMaker.RestTimerLock.Unlock()
+
+	changeFn()
+	require.Eventually(f.t, func() bool {
+		return fakeWatcher.TotalEventCount() == before+uint64(f.changeCount-changesBefore) && fakeWatcher.QueuedCount() == 0
+	}, timeout, interval, "Events were never read")
+}
+
+// AdvancePoll advances the fake clock once the polling watcher is waiting on it.
+func (f *fixture) AdvancePoll(d time.Duration) {
+	f.t.Helper()
+	f.clock.BlockUntil(1)
+	f.clock.Advance(d)
 }
 
 func (f *fixture) WaitForSeenFile(key types.NamespacedName, pathElems ...string) {
@@ -129,32 +174,93 @@ func (f *fixture) WaitForSeenFile(key types.NamespacedName, pathElems ...string)
 	}, 2*time.Second, 20*time.Millisecond, "Did not find path %q, seen: %v", relPath, &seenPaths)
 }
 
+// AssertNoSeenFilesFor fails the test if the FileWatch records any file changes within d.
+//
+// The fake debounce timers fire right away, so file changes are recorded in real time,
+// and d is real time rather than the fake clock's.
+func (f *fixture) AssertNoSeenFilesFor(key types.NamespacedName, d time.Duration) bool {
+	f.t.Helper()
+	return f.assertNoSeenFilesFor(f.t, key, d)
+}
+
+func (f *fixture) assertNoSeenFilesFor(t assert.TestingT, key types.NamespacedName, d time.Duration) bool {
+	var before filewatches.FileWatch
+	f.MustGet(key, &before)
+
+	var seenPaths []string
+	return assert.Neverf(t, func() bool {
+		var fw filewatches.FileWatch
+		if !f.Get(key, &fw) || fw.Status.TotalFilesSeen == before.Status.TotalFilesSeen {
+			return false
+		}
+		seenPaths = nil
+		for _, e := range fw.Status.FileEvents {
+			if e.Seq > before.Status.TotalEventCount {
+				seenPaths = append(seenPaths, e.SeenFiles...)
+			}
+		}
+		return true
+	}, d, 20*time.Millisecond, "Expected no file changes, seen: %v", &seenPaths)
+}
+
 func (f *fixture) CreateSimpleFileWatch() (types.NamespacedName, *filewatches.FileWatch) {
+	f.t.Helper()
+	return f.CreateFileWatch(f.SimpleSpec())
+}
+
+// SimpleSpec is the spec used by CreateSimpleFileWatch, for tests that need to tweak it before creation.
+func (f *fixture) SimpleSpec() filewatches.FileWatchSpec {
+	return filewatches.FileWatchSpec{
+		WatchedPaths: []string{f.tmpdir.JoinPath("a"), f.tmpdir.JoinPath("b", "c")},
+		DisableSource: &filewatches.DisableSource{
+			ConfigMap: &filewatches.ConfigMapDisableSource{
+				Name: "disable-test-file-watch",
+				Key:  "isDisabled",
+			},
+		},
+	}
+}
+
+func (f *fixture) CreateFileWatch(spec filewatches.FileWatchSpec) (types.NamespacedName, *filewatches.FileWatch) {
 	f.t.Helper()
 	fw := &filewatches.FileWatch{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: apis.SanitizeName(f.t.Name()),
 			Name:      "test-file-watch",
 		},
-		Spec: filewatches.FileWatchSpec{
-			WatchedPaths: []string{f.tmpdir.JoinPath("a"), f.tmpdir.JoinPath("b", "c")},
-			DisableSource: &filewatches.DisableSource{
-				ConfigMap: &filewatches.ConfigMapDisableSource{
-					Name: "disable-test-file-watch",
-					Key:  "isDisabled",
-				},
-			},
-		},
+		Spec: spec,
 	}
 	f.Create(fw)
 
-	f.setDisabled(types.NamespacedName{Namespace: fw.Namespace, Name: fw.Name}, false)
-	return f.KeyForObject(fw), fw
+	key := f.KeyForObject(fw)
+	if spec.DisableSource != nil && spec.DisableSource.ConfigMap != nil {
+		f.setDisabled(key, false)
+	} else {
+		f.reconcileFw(key)
+	}
+	return key, fw
 }
 
-func (f *fixture) reconcileFw(key types.NamespacedName) {
-	_, err := f.controller.Reconcile(f.Context(), ctrl.Request{NamespacedName: key})
+func (f *fixture) reconcileFw(key types.NamespacedName) ctrl.Result {
+	result, err := f.controller.Reconcile(f.Context(), ctrl.Request{NamespacedName: key})
 	require.NoError(f.T(), err)
+	return result
+}
+
+// failWatcher sends an error to the filesystem monitor for key and waits for the monitor to shut down.
+func (f *fixture) failWatcher(key types.NamespacedName, err error) {
+	f.t.Helper()
+	f.controller.mu.Lock()
+	w := f.controller.targetWatches[key]
+	f.controller.mu.Unlock()
+	require.NotNilf(f.t, w, "Watcher does not exist for %q", key.String())
+
+	f.fakeMultiWatcher.Errors <- err
+	require.Eventuallyf(f.t, func() bool {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		return w.done
+	}, time.Second, 10*time.Millisecond, "Watcher was never stopped")
 }
 
 func (f *fixture) setDisabled(key types.NamespacedName, isDisabled bool) {
@@ -167,7 +273,7 @@ func (f *fixture) setDisabled(key types.NamespacedName, isDisabled bool) {
 	require.NotNil(f.T(), fw.Spec.DisableSource.ConfigMap)
 
 	ds := fw.Spec.DisableSource.ConfigMap
-	err = configmap.UpsertDisableConfigMap(f.Context(), f.Client, ds.Name, ds.Key, isDisabled)
+	err = tiltconfigmap.UpsertDisableConfigMap(f.Context(), f.Client, ds.Name, ds.Key, isDisabled)
 	require.NoError(f.T(), err)
 
 	f.reconcileFw(key)
@@ -181,225 +287,3145 @@ func (f *fixture) setDisabled(key types.NamespacedName, isDisabled bool) {
 }
 
 func TestController_LimitFileEventsHistory(t *testing.T) {
-	f := newFixture(t)
+	for _, tc := range []struct {
+		name            string
+		maxEventHistory *int32
+		expected        int
+	}{
+		{"default", nil, MaxFileEventHistory},
+		{"override", pointer.Int32(5), 5},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newFixture(t)
+
+			spec := f.SimpleSpec()
+			spec.MaxEventHistory = tc.maxEventHistory
+			key, fw := f.CreateFileWatch(spec)
+
+			const eventOverflowCount = 5
+			for i := 0; i < tc.expected+eventOverflowCount; i++ {
+				// need to wait for each file 1-by-1 to prevent batching
+				f.ChangeAndWaitForSeenFile(key, "a", strconv.Itoa(i))
+			}
 
+			f.MustGet(key, fw)
+			require.Equal(t, tc.expected, len(fw.Status.FileEvents), "Wrong number of file events")
+			assert.Equal(t, int64(tc.expected+eventOverflowCount), fw.Status.TotalEventCount)
+			assert.Equal(t, int64(tc.expected+eventOverflowCount), fw.Status.TotalFilesSeen)
+			assert.Greater(t, fw.Status.TotalEventCount, int64(len(fw.Status.FileEvents)))
+			for i := 0; i < len(fw.Status.FileEvents); i++ {
+				p := f.tmpdir.JoinPath("a", strconv.Itoa(i+eventOverflowCount))
+				assert.Contains(t, fw.Status.FileEvents[i].SeenFiles, p)
+			}
+		})
+	}
+}
+
+func TestController_EventSeq(t *testing.T) {
+	f := newFixture(t)
 	key, fw := f.CreateSimpleFileWatch()
 
-	const eventOverflowCount = 5
+	const eventOverflowCount = 3
 	for i := 0; i < MaxFileEventHistory+eventOverflowCount; i++ {
 		// need to wait for each file 1-by-1 to prevent batching
 		f.ChangeAndWaitForSeenFile(key, "a", strconv.Itoa(i))
 	}
 
 	f.MustGet(key, fw)
-	require.Equal(t, MaxFileEventHistory, len(fw.Status.FileEvents), "Wrong number of file events")
-	for i := 0; i < len(fw.Status.FileEvents); i++ {
-		p := f.tmpdir.JoinPath("a", strconv.Itoa(i+eventOverflowCount))
-		assert.Contains(t, fw.Status.FileEvents[i].SeenFiles, p)
+	require.Equal(t, MaxFileEventHistory, len(fw.Status.FileEvents))
+	// The first retained event shows how many were trimmed before it.
+	assert.Equal(t, int64(eventOverflowCount+1), fw.Status.FileEvents[0].Seq)
+	for i, e := range fw.Status.FileEvents {
+		assert.Equal(t, fw.Status.FileEvents[0].Seq+int64(i), e.Seq, "seq should be contiguous")
 	}
+	assert.Equal(t, fw.Status.TotalEventCount, fw.Status.FileEvents[len(fw.Status.FileEvents)-1].Seq)
 }
 
-func TestController_ShortRead(t *testing.T) {
+func TestController_WatchEventTypes(t *testing.T) {
 	f := newFixture(t)
-	key, _ := f.CreateSimpleFileWatch()
+	spec := f.SimpleSpec()
+	spec.WatchEventTypes = []filewatches.FileWatchEventType{filewatches.FileWatchEventTypeWrite}
+	key, _ := f.CreateFileWatch(spec)
 
-	f.fakeMultiWatcher.Errors <- fmt.Errorf("short read on readEvents()")
+	emit := func(op watch.Op, elem ...string) {
+		f.fakeMultiWatcher.RequireEmit(t, watch.NewFileEventWithOp(f.tmpdir.JoinPath(elem...), op))
+	}
+	emit(watch.OpCreate, "a", "created")
+	emit(watch.OpRemove, "a", "removed")
+	emit(watch.OpCreate|watch.OpWrite, "a", "created-and-written")
+	emit(watch.OpWrite, "a", "written")
+	f.WaitForSeenFile(key, "a", "written")
 
-	require.Eventuallyf(t, func() bool {
-		return strings.Contains(f.Stdout(), "short read")
-	}, time.Second, 10*time.Millisecond, "short read error was not propagated")
+	var fw filewatches.FileWatch
+	f.MustGet(key, &fw)
+	assert.ElementsMatch(t, []string{
+		f.tmpdir.JoinPath("a", "created-and-written"),
+		f.tmpdir.JoinPath("a", "written"),
+	}, seenFiles(&fw))
 
-	if runtime.GOOS == "windows" {
-		assert.Contains(t, f.Stdout(), "https://github.com/tilt-dev/tilt/issues/3556")
-	}
+	// Changes the monitor can't classify are always reported.
+	f.ChangeAndWaitForSeenFile(key, "a", "unknown")
+}
+
+func TestController_CollapseTo(t *testing.T) {
+	f := newFixture(t)
+	f.tmpdir.MkdirAll(filepath.Join("b", "c"))
+	spec := f.SimpleSpec()
+	spec.CollapseTo = []string{f.tmpdir.JoinPath("b")}
+	key, _ := f.CreateFileWatch(spec)
+
+	f.InOneBatch(key, func() {
+		f.ChangeFile("b", "c", "1")
+		f.ChangeFile("b", "c", "2")
+		f.ChangeFile("b", "c", "d", "3")
+		f.ChangeFile("a", "4")
+	})
+	f.WaitForSeenFile(key, "a", "4")
 
 	var fw filewatches.FileWatch
 	f.MustGet(key, &fw)
-	assert.Contains(t, fw.Status.Error, "short read on readEvents()")
+	require.Equal(t, 1, len(fw.Status.FileEvents), "Wrong file event count")
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "4"), f.tmpdir.JoinPath("b")}, fw.Status.FileEvents[0].SeenFiles)
+	// b itself wasn't deleted, even though the files reported under it don't exist
+	assert.NotContains(t, fw.Status.FileEvents[0].DeletedFiles, f.tmpdir.JoinPath("b"))
 }
 
-func TestController_IgnoreEphemeralFiles(t *testing.T) {
+func TestController_FileModTime(t *testing.T) {
 	f := newFixture(t)
-	key, orig := f.CreateSimpleFileWatch()
-	// spec should have no ignores - these are purely implicit ignores
-	require.Empty(t, orig.Spec.Ignores)
+	key, _ := f.CreateSimpleFileWatch()
 
-	// sandwich in some ignored files with seen files on the outside as synchronization
-	f.ChangeAndWaitForSeenFile(key, "a", "start")
-	// see internal/ignore/ephemeral.go for where these come from - they're NOT part of a FileWatch spec
-	// but are always included at the filesystem watcher level by Tilt
-	f.ChangeFile("a", ".idea", "workspace.xml")
-	f.ChangeFile("b", "c", ".vim.swp")
-	f.ChangeAndWaitForSeenFile(key, "b", "c", "stop")
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	f.tmpdir.WriteFile(filepath.Join("a", "old"), "old")
+	require.NoError(t, os.Chtimes(f.tmpdir.JoinPath("a", "old"), old, old))
+	f.ChangeAndWaitForSeenFile(key, "a", "old")
 
 	var fw filewatches.FileWatch
 	f.MustGet(key, &fw)
-	require.Equal(t, 2, len(fw.Status.FileEvents), "Wrong file event count")
-	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "start")}, fw.Status.FileEvents[0].SeenFiles)
-	assert.Equal(t, []string{f.tmpdir.JoinPath("b", "c", "stop")}, fw.Status.FileEvents[1].SeenFiles)
+	e := fw.Status.FileEvents[len(fw.Status.FileEvents)-1]
+	assert.WithinDuration(t, old, e.FileModTime.Time, time.Millisecond)
+	assert.True(t, e.Time.After(e.FileModTime.Time), "event should be recorded after the file changed")
+
+	// Files that don't exist don't have a mod time.
+	f.ChangeAndWaitForSeenFile(key, "a", "missing")
+	f.MustGet(key, &fw)
+	assert.True(t, fw.Status.FileEvents[len(fw.Status.FileEvents)-1].FileModTime.IsZero())
 }
 
-// TestController_Watcher_Cancel peeks into internal/unexported portions of the controller to inspect the actual
-// filesystem monitor so it can ensure reconciler is not leaking resources; other tests should prefer observing
-// desired state!
-func TestController_Watcher_Cancel(t *testing.T) {
+func TestController_DeletedFiles(t *testing.T) {
 	f := newFixture(t)
 	key, _ := f.CreateSimpleFileWatch()
 
-	assert.Equalf(t, 1, len(f.controller.targetWatches), "There should be exactly one file watcher")
-	watcher := f.controller.targetWatches[key]
-	require.NotNilf(t, watcher, "Watcher does not exist for %q", key.String())
+	f.tmpdir.WriteFile(filepath.Join("a", "keep"), "keep")
+	f.tmpdir.WriteFile(filepath.Join("a", "delete"), "delete")
+	f.ChangeAndWaitForSeenFile(key, "a", "keep")
 
-	// cancel the root context, which should propagate to the watcher
-	f.Cancel()
+	f.tmpdir.Rm(filepath.Join("a", "delete"))
+	f.ChangeAndWaitForSeenFile(key, "a", "delete")
 
-	require.Eventuallyf(t, func() bool {
-		watcher.mu.Lock()
-		defer watcher.mu.Unlock()
-		return watcher.done
-	}, time.Second, 10*time.Millisecond, "Watcher was never cleaned up")
+	var fw filewatches.FileWatch
+	f.MustGet(key, &fw)
+	require.Equal(t, 2, len(fw.Status.FileEvents), "Wrong file event count")
+	assert.Empty(t, fw.Status.FileEvents[0].DeletedFiles)
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "delete")}, fw.Status.FileEvents[1].SeenFiles)
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "delete")}, fw.Status.FileEvents[1].DeletedFiles)
 }
 
-func TestController_Reconcile_Create(t *testing.T) {
+func TestController_IgnoresWithoutBasePath(t *testing.T) {
 	f := newFixture(t)
-	key, fw := f.CreateSimpleFileWatch()
+	spec := f.SimpleSpec()
+	spec.Ignores = []filewatches.IgnoreDef{{
+		Patterns: []string{"build", "*.tmp"},
+	}}
+	key, fw := f.CreateFileWatch(spec)
+
+	f.ChangeFile("a", "build", "out")
+	f.ChangeFile("a", "x.tmp")
+	f.ChangeFile("b", "c", "build", "out")
+	f.ChangeFile("b", "c", "y.tmp")
+	// patterns are anchored to each watched path, so they don't match at other depths
+	f.ChangeFile("a", "nested", "build")
+	f.ChangeAndWaitForSeenFile(key, "a", "1")
+	f.ChangeAndWaitForSeenFile(key, "b", "c", "2")
 
 	f.MustGet(key, fw)
-	assert.NotZero(t, fw.Status.MonitorStartTime, "Filesystem monitor was not started")
+	assert.ElementsMatch(t, []string{
+		f.tmpdir.JoinPath("a", "nested", "build"),
+		f.tmpdir.JoinPath("a", "1"),
+		f.tmpdir.JoinPath("b", "c", "2"),
+	}, seenFiles(fw))
 }
 
-// TestController_Reconcile_Delete peeks into internal/unexported portions of the controller to inspect the actual
-// filesystem monitor so it can ensure reconciler is not leaking resources; other tests should prefer observing
-// desired state!
-func TestController_Reconcile_Delete(t *testing.T) {
+func TestController_IgnoresWithoutBasePathFromEmptyConfigMap(t *testing.T) {
 	f := newFixture(t)
-	key, fw := f.CreateSimpleFileWatch()
+	cm := &filewatches.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "ignore-patterns"},
+		Data:       map[string]string{"patterns": "# nothing yet\n"},
+	}
+	require.NoError(t, f.Client.Create(f.Context(), cm))
 
-	assert.Equalf(t, 1, len(f.controller.targetWatches), "There should be exactly one file watcher")
-	watcher := f.controller.targetWatches[key]
-	require.NotNilf(t, watcher, "Watcher does not exist for %q", key.String())
+	spec := f.SimpleSpec()
+	spec.Ignores = []filewatches.IgnoreDef{
+		{PatternsConfigMap: &filewatches.ConfigMapPatternsSource{Name: "ignore-patterns", Key: "patterns"}},
+		{PatternsConfigMap: &filewatches.ConfigMapPatternsSource{Name: "missing", Key: "patterns"}},
+	}
+	key, fw := f.CreateFileWatch(spec)
 
-	deleted, _ := f.Delete(fw)
-	require.True(t, deleted, "FileWatch was not deleted")
+	// ignores with nothing to match don't ignore the whole project
+	f.ChangeAndWaitForSeenFile(key, "a", "1")
+	f.MustGet(key, fw)
+	assert.Empty(t, fw.Status.SetupError)
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "1")}, seenFiles(fw))
+}
 
-	watcher.mu.Lock()
-	defer watcher.mu.Unlock()
-	require.True(t, watcher.done, "Watcher was not stopped")
-	require.Empty(t, f.controller.targetWatches, "There should not be any remaining file watchers")
+func TestRootIgnores(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "Tiltfile")
+	require.NoError(t, os.WriteFile(file, nil, 0644))
+	gitignore := filepath.Join(dir, ".gitignore")
+
+	ignores := rootIgnores([]filewatches.IgnoreDef{
+		{Patterns: []string{"*.tmp"}},
+		{GitignoreFile: gitignore},
+		{},
+	}, []string{file, dir})
+	assert.Equal(t, []filewatches.IgnoreDef{
+		// a watched file's patterns are anchored to its directory, and only once
+		{BasePath: dir, Patterns: []string{"*.tmp"}},
+		{GitignoreFile: gitignore},
+	}, ignores)
 }
 
-func TestController_Reconcile_Watches(t *testing.T) {
+func TestController_JSONLogs(t *testing.T) {
 	f := newFixture(t)
-	key, fw := f.CreateSimpleFileWatch()
+	f.store.WithState(func(state *store.EngineState) {
+		state.LogFormat = store.LogFormatJSON
+	})
+	key, _ := f.CreateSimpleFileWatch()
+
+	f.ChangeAndWaitForSeenFile(key, "a", "1")
+
+	var entries []map[string]interface{}
+	require.Eventually(t, func() bool {
+		entries = nil
+		for _, line := range strings.Split(strings.TrimSpace(f.Stdout()), "\n") {
+			var entry map[string]interface{}
+			if json.Unmarshal([]byte(line), &entry) != nil {
+				return false
+			}
+			entries = append(entries, entry)
+		}
+		return len(entries) != 0 && entries[len(entries)-1]["msg"] == "files changed"
+	}, timeout, interval, "file change never logged as JSON:\n%s", f.Stdout())
+
+	assert.Equal(t, map[string]interface{}{
+		"level":      "info",
+		"fileWatch":  key.Name,
+		"msg":        "files changed",
+		"eventCount": float64(1),
+		"paths":      []interface{}{f.tmpdir.JoinPath("a", "1")},
+	}, entries[len(entries)-1])
+	assert.Equal(t, key.Name, entries[0]["fileWatch"])
+	assert.Contains(t, entries[0]["msg"], "disabled, file changes will be ignored")
+}
+
+func TestController_TextLogsOmitFileChanges(t *testing.T) {
+	f := newFixture(t)
+	key, _ := f.CreateSimpleFileWatch()
 
 	f.ChangeAndWaitForSeenFile(key, "a", "1")
+	assert.NotContains(t, f.Stdout(), "files changed")
+	assert.NotContains(t, f.Stdout(), "{")
+}
+
+func TestController_ReportDirectoryEvents(t *testing.T) {
+	f := newFixture(t)
+	f.tmpdir.MkdirAll(filepath.Join("a", "existing", "nested"))
+	spec := f.SimpleSpec()
+	spec.ReportDirectoryEvents = true
+	key, fw := f.CreateFileWatch(spec)
+
+	directoryEvents := func() (created []string, deleted []string) {
+		f.MustGet(key, fw)
+		for _, e := range fw.Status.FileEvents {
+			created = append(created, e.CreatedDirectories...)
+			deleted = append(deleted, e.DeletedDirectories...)
+		}
+		return created, deleted
+	}
+
+	f.tmpdir.MkdirAll(filepath.Join("a", "plugin"))
+	f.ChangeAndWaitForSeenFile(key, "a", "plugin")
+	created, deleted := directoryEvents()
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "plugin")}, created)
+	assert.Empty(t, deleted)
+
+	// directories that existed before the watch started are recognized when deleted
+	f.tmpdir.Rm(filepath.Join("a", "plugin"))
+	f.tmpdir.Rm(filepath.Join("a", "existing"))
+	f.ChangeFile("a", "plugin")
+	f.ChangeFile("a", "existing")
+	// files aren't reported as directories
+	f.tmpdir.WriteFile(filepath.Join("a", "file.txt"), "hello")
+	f.ChangeAndWaitForSeenFile(key, "a", "file.txt")
+
+	created, deleted = directoryEvents()
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "plugin")}, created)
+	assert.ElementsMatch(t, []string{f.tmpdir.JoinPath("a", "plugin"), f.tmpdir.JoinPath("a", "existing")}, deleted)
+}
+
+func TestController_DirectoryEventsNotReportedByDefault(t *testing.T) {
+	f := newFixture(t)
+	key, fw := f.CreateSimpleFileWatch()
 
+	f.tmpdir.MkdirAll(filepath.Join("a", "plugin"))
+	f.ChangeAndWaitForSeenFile(key, "a", "plugin")
 	f.MustGet(key, fw)
-	originalStart := fw.Status.MonitorStartTime.Time
-	assert.NotZero(t, originalStart, "Filesystem monitor was not started")
+	assert.Empty(t, fw.Status.FileEvents[0].CreatedDirectories)
+}
 
-	fw.Spec.Ignores = []filewatches.IgnoreDef{
-		{
-			BasePath: f.tmpdir.Path(),
-			Patterns: []string{"**/ignore_me"},
-		},
-		{
-			// no patterns means ignore the path recursively
-			BasePath: f.tmpdir.JoinPath("d", "ignore_dir"),
-		},
+func TestController_IgnoredEventsSummary(t *testing.T) {
+	f := newFixture(t)
+	spec := f.SimpleSpec()
+	spec.Ignores = []filewatches.IgnoreDef{{
+		BasePath: f.tmpdir.JoinPath("a"),
+		Patterns: []string{"node_modules"},
+	}}
+	key, _ := f.CreateFileWatch(spec)
+
+	events := f.fakeMultiWatcher.Events
+	for i := 0; i < 150; i++ {
+		// more events than the fake watcher can buffer
+		require.Eventually(t, func() bool { return len(events) < cap(events) }, timeout, time.Millisecond)
+		f.ChangeFile("a", "node_modules", "pkg", fmt.Sprintf("%d.js", i))
 	}
-	fw.Spec.WatchedPaths = []string{f.tmpdir.JoinPath("d")}
-	f.Update(fw)
+	f.ChangeAndWaitForSeenFile(key, "a", "1")
+	assert.NotContains(t, f.Stdout(), "events under")
+
+	f.clock.BlockUntil(1)
+	f.clock.Advance(ignoreSummaryInterval)
+	expected := fmt.Sprintf("filewatch %s: ignored 150 events under %s", key.Name, f.tmpdir.JoinPath("a", "node_modules"))
+	require.Eventually(t, func() bool {
+		return strings.Contains(f.Stdout(), expected)
+	}, timeout, interval, "summary of ignored events never logged")
+
+	// nothing more to report until enough events are ignored again
+	f.ChangeFile("a", "node_modules", "pkg", "again.js")
+	f.ChangeAndWaitForSeenFile(key, "a", "2")
+	f.clock.BlockUntil(1)
+	f.clock.Advance(ignoreSummaryInterval)
+	f.ChangeAndWaitForSeenFile(key, "a", "3")
+	assert.Equal(t, 1, strings.Count(f.Stdout(), "events under"))
+}
 
-	// sandwich in some ignored files with seen files on the outside as synchronization
-	f.ChangeAndWaitForSeenFile(key, "d", "1")
-	f.ChangeFile("a", "2")
-	f.ChangeFile("d", "ignore_me")
-	f.ChangeFile("d", "ignore_dir", "file")
-	f.ChangeAndWaitForSeenFile(key, "d", "2")
+func TestController_RecentlyIgnored(t *testing.T) {
+	f := newFixture(t)
+	spec := f.SimpleSpec()
+	spec.Ignores = []filewatches.IgnoreDef{{
+		BasePath: f.tmpdir.Path(),
+		Patterns: []string{"a/*.log"},
+	}}
+	key, fw := f.CreateFileWatch(spec)
+
+	f.ChangeFile("a", "debug.log")
+	f.ChangeFile("a", "error.log")
+	f.ChangeFile("a", "debug.log")
+	f.ChangeAndWaitForSeenFile(key, "a", "1")
 
-	var updated filewatches.FileWatch
-	f.MustGet(key, &updated)
-	updatedStart := updated.Status.MonitorStartTime.Time
-	assert.Truef(t, updatedStart.After(originalStart),
-		"Monitor start time should be more recent after update, (original: %s, updated: %s)",
-		originalStart, updatedStart)
-	if assert.Equal(t, 3, len(updated.Status.FileEvents)) {
-		// ensure ONLY the expected files were seen
-		assert.NotZero(t, updated.Status.FileEvents[0].Time.Time)
-		mostRecentEventTime := updated.Status.FileEvents[2].Time.Time
-		assert.NotZero(t, mostRecentEventTime)
-		assert.Equal(t, []string{f.tmpdir.JoinPath("a", "1")}, updated.Status.FileEvents[0].SeenFiles)
-		assert.Equal(t, []string{f.tmpdir.JoinPath("d", "1")}, updated.Status.FileEvents[1].SeenFiles)
-		assert.Equal(t, []string{f.tmpdir.JoinPath("d", "2")}, updated.Status.FileEvents[2].SeenFiles)
-		assert.Equal(t, mostRecentEventTime, updated.Status.LastEventTime.Time)
+	f.MustGet(key, fw)
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "error.log"), f.tmpdir.JoinPath("a", "debug.log")},
+		fw.Status.RecentlyIgnored)
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "1")}, seenFiles(fw))
+
+	// only the most recent are kept
+	events := f.fakeMultiWatcher.Events
+	var expected []string
+	for i := 0; i < MaxRecentlyIgnored+5; i++ {
+		// more events than the fake watcher can buffer
+		require.Eventually(t, func() bool { return len(events) < cap(events) }, timeout, time.Millisecond)
+		f.ChangeFile("a", fmt.Sprintf("%d.log", i))
+		expected = append(expected, f.tmpdir.JoinPath("a", fmt.Sprintf("%d.log", i)))
 	}
+	f.ChangeAndWaitForSeenFile(key, "a", "2")
+	f.MustGet(key, fw)
+	assert.Equal(t, expected[len(expected)-MaxRecentlyIgnored:], fw.Status.RecentlyIgnored)
+	assert.ElementsMatch(t, []string{f.tmpdir.JoinPath("a", "1"), f.tmpdir.JoinPath("a", "2")}, seenFiles(fw))
 }
 
-func TestController_Disable_By_Configmap(t *testing.T) {
+func TestController_MaxFileSize(t *testing.T) {
 	f := newFixture(t)
-	key, _ := f.CreateSimpleFileWatch()
+	spec := f.SimpleSpec()
+	spec.MaxFileSize = "1Ki"
+	spec.DebugIgnores = true
+	key, _ := f.CreateFileWatch(spec)
 
-	// when enabling the configmap, the filewatch object is enabled
-	f.setDisabled(key, false)
+	f.tmpdir.WriteFile(filepath.Join("a", "large.bin"), strings.Repeat("x", 2048))
+	f.tmpdir.WriteFile(filepath.Join("a", "small.txt"), "hello")
+	f.ChangeFile("a", "large.bin")
+	f.ChangeAndWaitForSeenFile(key, "a", "small.txt")
 
-	// when disabling the configmap, the filewatch object is disabled
-	f.setDisabled(key, true)
+	var fw filewatches.FileWatch
+	f.MustGet(key, &fw)
+	for _, e := range fw.Status.FileEvents {
+		assert.NotContains(t, e.SeenFiles, f.tmpdir.JoinPath("a", "large.bin"))
+	}
+	assert.Contains(t, f.Stdout(), "larger than maxFileSize")
 
-	// when enabling the configmap, the filewatch object is enabled
-	f.setDisabled(key, false)
+	// a deleted file can't be stat'd, so it's never ignored by size
+	f.tmpdir.Rm(filepath.Join("a", "large.bin"))
+	f.ChangeAndWaitForSeenFile(key, "a", "large.bin")
 }
 
-func TestController_Disable_Ignores_File_Changes(t *testing.T) {
+func TestController_IgnoreOlderThan(t *testing.T) {
 	f := newFixture(t)
-	key, _ := f.CreateSimpleFileWatch()
+	spec := f.SimpleSpec()
+	spec.IgnoreOlderThan = metav1.Duration{Duration: time.Hour}
+	spec.DebugIgnores = true
+	key, _ := f.CreateFileWatch(spec)
+
+	// File ages are measured against the controller's clock, not the wall clock.
+	touch := func(name string) {
+		f.tmpdir.WriteFile(filepath.Join("a", name), "hello")
+		require.NoError(t, os.Chtimes(f.tmpdir.JoinPath("a", name), f.clock.Now(), f.clock.Now()))
+	}
+	touch("old.log")
+	f.clock.Advance(2 * time.Hour)
+	touch("new.log")
 
-	// Disable the filewatch
-	f.setDisabled(key, true)
-	// Change a watched file
-	f.ChangeFile("a", "1")
+	f.ChangeFile("a", "old.log")
+	f.ChangeAndWaitForSeenFile(key, "a", "new.log")
 
-	// Expect that no file events were triggered
-	var fwAfterDisable filewatches.FileWatch
-	f.MustGet(key, &fwAfterDisable)
-	require.Equal(t, 0, len(fwAfterDisable.Status.FileEvents))
+	var fw filewatches.FileWatch
+	f.MustGet(key, &fw)
+	assert.NotContains(t, seenFiles(&fw), f.tmpdir.JoinPath("a", "old.log"))
+	assert.Contains(t, f.Stdout(), "older than ignoreOlderThan")
 }
 
-func TestCreateSubError(t *testing.T) {
+func TestController_IncludeExtensions(t *testing.T) {
 	f := newFixture(t)
-	f.controller.fsWatcherMaker = fsevent.WatcherMaker(func(paths []string, ignore watch.PathMatcher, _ logger.Logger) (watch.Notify, error) {
-		var nilWatcher *fsevent.FakeWatcher = nil
-		return nilWatcher, fmt.Errorf("Unusual watcher error")
+	spec := f.SimpleSpec()
+	spec.IncludeExtensions = []string{".go", "proto"}
+	spec.DebugIgnores = true
+	key, _ := f.CreateFileWatch(spec)
+
+	f.ChangeFile("a", "README.md")
+	f.ChangeFile("a", "Makefile")
+	f.ChangeFile("a", "pkg", "api.proto")
+	f.ChangeAndWaitForSeenFile(key, "a", "pkg", "main.go")
+
+	var fw filewatches.FileWatch
+	f.MustGet(key, &fw)
+	var seen []string
+	for _, e := range fw.Status.FileEvents {
+		seen = append(seen, e.SeenFiles...)
+	}
+	assert.Contains(t, seen, f.tmpdir.JoinPath("a", "pkg", "api.proto"))
+	assert.NotContains(t, seen, f.tmpdir.JoinPath("a", "README.md"))
+	assert.NotContains(t, seen, f.tmpdir.JoinPath("a", "Makefile"))
+	assert.Contains(t, f.Stdout(), "extension not in includeExtensions")
+}
+
+func TestController_IncludeExtensionsWithIgnores(t *testing.T) {
+	f := newFixture(t)
+	spec := f.SimpleSpec()
+	spec.IncludeExtensions = []string{".go"}
+	spec.Ignores = []filewatches.IgnoreDef{{
+		BasePath: f.tmpdir.Path(),
+		Patterns: []string{"a/testdata"},
+	}}
+	spec.DebugIgnores = true
+	key, fw := f.CreateFileWatch(spec)
+
+	f.ChangeFile("a", "testdata", "fixture.go")
+	f.ChangeFile("a", "testdata", "fixture.json")
+	f.ChangeFile("a", "README.md")
+	f.ChangeAndWaitForSeenFile(key, "a", "main.go")
+
+	f.MustGet(key, fw)
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "main.go")}, seenFiles(fw))
+
+	// the ignore wins for a file with an included extension, but the extension is
+	// checked first for everything else
+	out := f.Stdout()
+	assert.Contains(t, out, fmt.Sprintf("ignoring %s (matched ignores[0]", f.tmpdir.JoinPath("a", "testdata", "fixture.go")))
+	assert.Contains(t, out, fmt.Sprintf("ignoring %s (extension not in includeExtensions)", f.tmpdir.JoinPath("a", "testdata", "fixture.json")))
+	assert.Contains(t, out, fmt.Sprintf("ignoring %s (extension not in includeExtensions)", f.tmpdir.JoinPath("a", "README.md")))
+}
+
+func TestController_RelativeTo(t *testing.T) {
+	f := newFixture(t)
+	spec := f.SimpleSpec()
+	spec.RelativeTo = f.tmpdir.Path()
+	key, _ := f.CreateFileWatch(spec)
+
+	f.InOneBatch(key, func() {
+		f.ChangeFile("a", "1")
+		f.ChangeFile("b", "c", "2")
 	})
-	key, _ := f.CreateSimpleFileWatch()
+	f.WaitForSeenFile(key, "b", "c", "2")
 
-	// Expect that no file events were triggered
 	var fw filewatches.FileWatch
 	f.MustGet(key, &fw)
-	assert.Contains(t, fw.Status.Error, "filewatch init: Unusual watcher error")
+	require.Len(t, fw.Status.FileEvents, 1)
+	e := fw.Status.FileEvents[0]
+	var expected []string
+	for _, p := range e.SeenFiles {
+		require.True(t, filepath.IsAbs(p), "SeenFiles should stay absolute")
+		rel, err := filepath.Rel(f.tmpdir.Path(), p)
+		require.NoError(t, err)
+		expected = append(expected, rel)
+	}
+	assert.Equal(t, expected, e.RelSeenFiles)
+	assert.ElementsMatch(t, []string{filepath.Join("a", "1"), filepath.Join("b", "c", "2")}, e.RelSeenFiles)
 }
 
-func TestStartSubError(t *testing.T) {
+func TestController_PathRewrite(t *testing.T) {
 	f := newFixture(t)
-	maker := f.controller.fsWatcherMaker
-	var ffw *fsevent.FakeWatcher
-	f.controller.fsWatcherMaker = fsevent.WatcherMaker(func(paths []string, ignore watch.PathMatcher, l logger.Logger) (watch.Notify, error) {
-		w, err := maker(paths, ignore, l)
-		ffw = w.(*fsevent.FakeWatcher)
-		ffw.StartErr = fmt.Errorf("Unusual start error")
-		return w, err
+	remote := filepath.Join(string(filepath.Separator), "remote", "src")
+	spec := f.SimpleSpec()
+	spec.PathRewrite = &filewatches.FileWatchPathRewrite{From: f.tmpdir.JoinPath("a"), To: remote}
+	spec.RelativeTo = f.tmpdir.JoinPath("a")
+	key, _ := f.CreateFileWatch(spec)
+
+	f.InOneBatch(key, func() {
+		f.ChangeFile("a", "1")
+		f.ChangeFile("a", "sub", "2")
+		f.ChangeFile("b", "c", "3")
 	})
-	key, _ := f.CreateSimpleFileWatch()
+
+	var fw filewatches.FileWatch
+	require.Eventually(t, func() bool {
+		f.MustGet(key, &fw)
+		return fw.Status.TotalEventCount == 1
+	}, timeout, interval, "file changes were never recorded")
+	e := fw.Status.FileEvents[0]
+	assert.Equal(t, []string{
+		filepath.Join(remote, "1"),
+		filepath.Join(remote, "sub", "2"),
+		f.tmpdir.JoinPath("b", "c", "3"),
+	}, e.SeenFiles, "only paths under the From prefix should be rewritten")
+	assert.Equal(t, []string{"1", filepath.Join("sub", "2")}, e.RelSeenFiles[:2],
+		"relative paths should be computed from the rewritten paths and RelativeTo")
+}
+
+func TestController_IgnoreNegation(t *testing.T) {
+	f := newFixture(t)
+	spec := f.SimpleSpec()
+	spec.Ignores = []filewatches.IgnoreDef{{
+		BasePath: f.tmpdir.JoinPath("a"),
+		Patterns: []string{"node_modules/**", "!node_modules/mypkg/dist/**"},
+	}}
+	key, _ := f.CreateFileWatch(spec)
+
+	f.ChangeFile("a", "node_modules", "mypkg", "index.js")
+	f.ChangeAndWaitForSeenFile(key, "a", "node_modules", "mypkg", "dist", "index.js")
+
+	// ignore decisions are only logged when DebugIgnores is set
+	assert.NotContains(t, f.Stdout(), "ignoring")
 
 	var fw filewatches.FileWatch
 	f.MustGet(key, &fw)
-	assert.Contains(t, fw.Status.Error, "filewatch init: Unusual start error")
-	assert.False(t, ffw.Running)
+	require.Equal(t, 1, len(fw.Status.FileEvents), "Wrong file event count")
+	assert.Equal(t,
+		[]string{f.tmpdir.JoinPath("a", "node_modules", "mypkg", "dist", "index.js")},
+		fw.Status.FileEvents[0].SeenFiles)
+}
 
-	fw.Spec.WatchedPaths = []string{f.tmpdir.JoinPath("d")}
-	f.Update(&fw)
+func TestController_IgnoreRegex(t *testing.T) {
+	f := newFixture(t)
+	spec := f.SimpleSpec()
+	spec.Ignores = []filewatches.IgnoreDef{{
+		Regex: []string{`(^|/)[^/]*[0-9]{12}[^/]*$`},
+	}}
+	key, _ := f.CreateFileWatch(spec)
+
+	f.ChangeFile("a", "backup-202401021504.sql")
+	f.ChangeFile("b", "c", "nested", "snapshot-202401021504")
+	f.ChangeAndWaitForSeenFile(key, "a", "backup-2024.sql")
+	f.ChangeAndWaitForSeenFile(key, "b", "c", "202401021504", "main.go")
 
+	var fw filewatches.FileWatch
 	f.MustGet(key, &fw)
-	assert.Contains(t, fw.Status.Error, "filewatch init: Unusual start error")
-	assert.False(t, ffw.Running)
+	assert.Empty(t, fw.Status.SetupError)
+	assert.ElementsMatch(t, []string{
+		f.tmpdir.JoinPath("a", "backup-2024.sql"),
+		f.tmpdir.JoinPath("b", "c", "202401021504", "main.go"),
+	}, seenFiles(&fw))
+}
+
+func TestController_IgnoreRegexInvalid(t *testing.T) {
+	f := newFixture(t)
+	spec := f.SimpleSpec()
+	spec.Ignores = []filewatches.IgnoreDef{
+		{BasePath: f.tmpdir.JoinPath("a"), Patterns: []string{"*.log"}},
+		{BasePath: f.tmpdir.JoinPath("a"), Regex: []string{`\.tmp$`, `build(`}},
+	}
+	key, fw := f.CreateFileWatch(spec)
+
+	f.MustGet(key, fw)
+	assert.Contains(t, fw.Status.SetupError, "filewatch init: ignores[1]: invalid regex \"build(\"")
+	assert.Contains(t, fw.Status.SetupError, "missing closing )")
+	assert.Equal(t, "SetupFailed", apimeta.FindStatusCondition(fw.Status.Conditions, filewatches.FileWatchConditionReady).Reason)
+	assert.Zero(t, fw.Status.MonitorStartTime, "Filesystem monitor should not have been started")
+}
+
+func TestController_DebugIgnores(t *testing.T) {
+	f := newFixture(t)
+	spec := f.SimpleSpec()
+	spec.Ignores = []filewatches.IgnoreDef{{BasePath: f.tmpdir.JoinPath("a"), Patterns: []string{"*.log"}}}
+	spec.DebugIgnores = true
+	key, _ := f.CreateFileWatch(spec)
+
+	f.ChangeFile("a", "debug.log")
+	f.ChangeFile("a", ".main.go.swp")
+	f.ChangeAndWaitForSeenFile(key, "a", "main.go")
+
+	out := f.Stdout()
+	assert.Contains(t, out, fmt.Sprintf("ignoring %s (matched ignores[0]: basePath=%q patterns=[\"*.log\"])",
+		f.tmpdir.JoinPath("a", "debug.log"), f.tmpdir.JoinPath("a")))
+	assert.Contains(t, out, fmt.Sprintf("ignoring %s (ephemeral file)", f.tmpdir.JoinPath("a", ".main.go.swp")))
+	assert.Contains(t, out, fmt.Sprintf("not ignoring %s", f.tmpdir.JoinPath("a", "main.go")))
+}
+
+func TestController_GitignoreFile(t *testing.T) {
+	f := newFixture(t)
+	f.tmpdir.WriteFile(filepath.Join("a", ".gitignore"), "*.log\n!important.log\n")
+	spec := f.SimpleSpec()
+	spec.Ignores = []filewatches.IgnoreDef{{GitignoreFile: f.tmpdir.JoinPath("a", ".gitignore")}}
+	key, _ := f.CreateFileWatch(spec)
+
+	f.ChangeFile("a", "debug.log")
+	f.ChangeAndWaitForSeenFile(key, "a", "important.log")
+
+	var fw filewatches.FileWatch
+	f.MustGet(key, &fw)
+	require.Equal(t, 1, len(fw.Status.FileEvents), "Wrong file event count")
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "important.log")}, fw.Status.FileEvents[0].SeenFiles)
+	originalStart := fw.Status.MonitorStartTime.Time
+
+	// changing the gitignore file should restart the monitor with the new rules
+	f.tmpdir.WriteFile(filepath.Join("a", ".gitignore"), "*.txt\n")
+	f.ChangeAndWaitForSeenFile(key, "a", ".gitignore")
+	require.Eventually(t, func() bool {
+		f.MustGet(key, &fw)
+		return fw.Status.MonitorStartTime.Time.After(originalStart)
+	}, timeout, interval, "Monitor was not restarted")
+
+	f.ChangeFile("a", "notes.txt")
+	f.ChangeAndWaitForSeenFile(key, "a", "debug.log")
+
+	f.MustGet(key, &fw)
+	assert.Empty(t, fw.Status.Error)
+	for _, e := range fw.Status.FileEvents {
+		assert.NotContains(t, e.SeenFiles, f.tmpdir.JoinPath("a", "notes.txt"))
+	}
+}
+
+func TestController_GitignoreFileOutsideWatchedPaths(t *testing.T) {
+	f := newFixture(t)
+	f.tmpdir.WriteFile(".gitignore", "*.log\n")
+	spec := f.SimpleSpec()
+	spec.Ignores = []filewatches.IgnoreDef{{GitignoreFile: f.tmpdir.JoinPath(".gitignore")}}
+	key, fw := f.CreateFileWatch(spec)
+	f.MustGet(key, fw)
+	originalStart := fw.Status.MonitorStartTime.Time
+
+	f.tmpdir.WriteFile(".gitignore", "*.txt\n")
+	f.ChangeFile(".gitignore")
+	require.Eventually(t, func() bool {
+		f.MustGet(key, fw)
+		return fw.Status.MonitorStartTime.Time.After(originalStart)
+	}, timeout, interval, "Monitor was not restarted")
+
+	f.ChangeFile("a", "notes.txt")
+	f.ChangeAndWaitForSeenFile(key, "a", "debug.log")
+
+	// the gitignore file isn't watched by the spec, so it shouldn't be reported
+	f.MustGet(key, fw)
+	require.Equal(t, 1, len(fw.Status.FileEvents), "Wrong file event count")
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "debug.log")}, fw.Status.FileEvents[0].SeenFiles)
+}
+
+func TestController_EventMetrics(t *testing.T) {
+	f := newFixture(t)
+	key, _ := f.CreateSimpleFileWatch()
+
+	received, err := testutil.GetCounterMetricValue(fileEventsReceived.WithLabelValues(key.Name))
+	require.NoError(t, err)
+	recorded, err := testutil.GetCounterMetricValue(fileEventsRecorded.WithLabelValues(key.Name))
+	require.NoError(t, err)
+	batches, err := testutil.GetHistogramMetricCount(fileEventBatchSize.WithLabelValues(key.Name))
+	require.NoError(t, err)
+
+	f.ChangeAndWaitForSeenFile(key, "a", "1")
+	f.ChangeAndWaitForSeenFile(key, "a", "2")
+	f.ChangeAndWaitForSeenFile(key, "a", "3")
+
+	newReceived, err := testutil.GetCounterMetricValue(fileEventsReceived.WithLabelValues(key.Name))
+	require.NoError(t, err)
+	assert.Equal(t, 3.0, newReceived-received)
+
+	newRecorded, err := testutil.GetCounterMetricValue(fileEventsRecorded.WithLabelValues(key.Name))
+	require.NoError(t, err)
+	assert.Equal(t, 3.0, newRecorded-recorded)
+
+	newBatches, err := testutil.GetHistogramMetricCount(fileEventBatchSize.WithLabelValues(key.Name))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), newBatches-batches)
+}
+
+func TestController_WriteLatencyMetric(t *testing.T) {
+	f := newFixture(t)
+	spec := f.SimpleSpec()
+	spec.StatusUpdateInterval = metav1.Duration{Duration: time.Minute}
+	key, _ := f.CreateFileWatch(spec)
+
+	f.ChangeFile("a", "1")
+	require.Eventually(t, func() bool {
+		f.controller.mu.Lock()
+		w := f.controller.targetWatches[key]
+		f.controller.mu.Unlock()
+		return len(w.copyStatus().FileEvents) == 1
+	}, timeout, interval, "file event was never recorded")
+
+	// hold the event back from the object for a while
+	const delay = 50 * time.Millisecond
+	time.Sleep(delay)
+	f.clock.BlockUntil(2) // the status write and the ignore summary
+	f.clock.Advance(time.Minute)
+	f.WaitForSeenFile(key, "a", "1")
+
+	// the metric is set just after the write
+	var latency float64
+	require.Eventually(t, func() bool {
+		latency, _ = testutil.GetGaugeMetricValue(fileEventWriteLatency.WithLabelValues(key.Name))
+		return latency != 0
+	}, timeout, interval, "latency was never recorded")
+	assert.GreaterOrEqual(t, latency, delay.Seconds())
+}
+
+func TestController_ActiveWatchesMetric(t *testing.T) {
+	f := newFixture(t)
+	key, fw := f.CreateSimpleFileWatch()
+	gauge := activeWatches.WithLabelValues(key.Namespace)
+
+	active, err := testutil.GetGaugeMetricValue(gauge)
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, active)
+
+	fw2 := &filewatches.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: "test-file-watch-2"},
+		Spec:       f.SimpleSpec(),
+	}
+	f.Create(fw2)
+	f.reconcileFw(f.KeyForObject(fw2))
+	active, err = testutil.GetGaugeMetricValue(gauge)
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, active)
+
+	// updating a FileWatch replaces its monitor without changing the count
+	f.MustGet(key, fw)
+	fw.Spec.WatchedPaths = []string{f.tmpdir.JoinPath("d")}
+	f.Update(fw)
+	active, err = testutil.GetGaugeMetricValue(gauge)
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, active)
+
+	f.Delete(fw)
+	active, err = testutil.GetGaugeMetricValue(gauge)
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, active)
+
+	f.Delete(fw2)
+	active, err = testutil.GetGaugeMetricValue(gauge)
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, active)
+}
+
+func TestController_ShortRead(t *testing.T) {
+	f := newFixture(t)
+	key, _ := f.CreateSimpleFileWatch()
+
+	f.fakeMultiWatcher.Errors <- fmt.Errorf("short read on readEvents()")
+
+	require.Eventuallyf(t, func() bool {
+		return strings.Contains(f.Stdout(), "short read")
+	}, time.Second, 10*time.Millisecond, "short read error was not propagated")
+
+	if runtime.GOOS == "windows" {
+		assert.Contains(t, f.Stdout(), "https://github.com/tilt-dev/tilt/issues/3556")
+	}
+
+	var fw filewatches.FileWatch
+	f.MustGet(key, &fw)
+	assert.Contains(t, fw.Status.Error, "short read on readEvents()")
+}
+
+func TestController_LogPrefix(t *testing.T) {
+	f := newFixture(t)
+	spec := f.SimpleSpec()
+	spec.LogPrefix = "[frontend watch]"
+	key, _ := f.CreateFileWatch(spec)
+
+	f.fakeMultiWatcher.Errors <- fmt.Errorf("short read on readEvents()")
+
+	require.Eventuallyf(t, func() bool {
+		return strings.Contains(f.Stdout(), "[frontend watch]: short read on readEvents()")
+	}, time.Second, 10*time.Millisecond, "short read error was not logged with the prefix")
+	assert.NotContains(t, f.Stdout(), "filewatch "+key.Name)
+}
+
+func TestController_WatchLimit(t *testing.T) {
+	f := newFixture(t)
+	key, _ := f.CreateSimpleFileWatch()
+
+	f.fakeMultiWatcher.Errors <- fmt.Errorf("inotify_add_watch: %w", syscall.ENOSPC)
+
+	var fw filewatches.FileWatch
+	require.Eventuallyf(t, func() bool {
+		f.MustGet(key, &fw)
+		return fw.Status.Error != ""
+	}, time.Second, 10*time.Millisecond, "watch limit error was not propagated")
+
+	assert.True(t, strings.HasPrefix(fw.Status.Error, WatchLimitErrReason+": "),
+		"Error should start with a machine-parseable reason: %s", fw.Status.Error)
+	assert.Contains(t, fw.Status.Error, "fs.inotify.max_user_watches")
+	assert.Contains(t, fw.Status.Error, "no space left on device")
+}
+
+func TestController_RestartAfterError(t *testing.T) {
+	f := newFixture(t)
+	key, fw := f.CreateSimpleFileWatch()
+	f.MustGet(key, fw)
+	originalStart := fw.Status.MonitorStartTime.Time
+
+	f.failWatcher(key, fmt.Errorf("fatal read error"))
+
+	// still backing off, so the error should be preserved
+	f.reconcileFw(key)
+	f.MustGet(key, fw)
+	assert.Contains(t, fw.Status.Error, "fatal read error")
+	ready := apimeta.FindStatusCondition(fw.Status.Conditions, filewatches.FileWatchConditionReady)
+	require.NotNil(t, ready)
+	assert.Equal(t, metav1.ConditionFalse, ready.Status)
+	assert.Equal(t, "SetupInProgress", ready.Reason)
+
+	f.clock.Advance(maxRestartBackoff)
+	f.reconcileFw(key)
+	f.ChangeAndWaitForSeenFile(key, "a", "1")
+
+	f.MustGet(key, fw)
+	assert.Empty(t, fw.Status.Error)
+	assert.Truef(t, fw.Status.MonitorStartTime.Time.After(originalStart),
+		"Monitor start time should be more recent after restart, (original: %s, restarted: %s)",
+		originalStart, fw.Status.MonitorStartTime.Time)
+	assert.True(t, apimeta.IsStatusConditionTrue(fw.Status.Conditions, filewatches.FileWatchConditionReady))
+}
+
+func TestController_RestartGivesUpAfterRepeatedErrors(t *testing.T) {
+	f := newFixture(t)
+	key, fw := f.CreateSimpleFileWatch()
+
+	for i := 0; i <= maxRestartAttempts; i++ {
+		f.failWatcher(key, fmt.Errorf("fatal read error %d", i))
+		f.clock.Advance(maxRestartBackoff)
+		f.reconcileFw(key)
+	}
+
+	f.MustGet(key, fw)
+	assert.Contains(t, fw.Status.Error, fmt.Sprintf("stopped after %d failed restarts", maxRestartAttempts))
+	assert.Contains(t, fw.Status.Error, fmt.Sprintf("fatal read error %d", maxRestartAttempts))
+
+	f.controller.mu.Lock()
+	w := f.controller.targetWatches[key]
+	f.controller.mu.Unlock()
+	shouldRestart, result := w.shouldRestart()
+	assert.False(t, shouldRestart)
+	assert.Zero(t, result.RequeueAfter)
+}
+
+func TestController_RestartCountResetsAfterStableMonitor(t *testing.T) {
+	f := newFixture(t)
+	key, fw := f.CreateSimpleFileWatch()
+
+	// Occasional failures of a monitor that stays up in between never add up to a give up.
+	for i := 0; i <= maxRestartAttempts; i++ {
+		f.clock.Advance(stableMonitorDuration)
+		f.failWatcher(key, fmt.Errorf("fatal read error %d", i))
+		f.clock.Advance(maxRestartBackoff)
+		f.reconcileFw(key)
+	}
+
+	f.ChangeAndWaitForSeenFile(key, "a", "1")
+	f.MustGet(key, fw)
+	assert.Empty(t, fw.Status.Error)
+	assert.True(t, apimeta.IsStatusConditionTrue(fw.Status.Conditions, filewatches.FileWatchConditionReady))
+}
+
+func TestController_OverflowKeepsMonitorRunning(t *testing.T) {
+	f := newFixture(t)
+	key, fw := f.CreateSimpleFileWatch()
+	f.MustGet(key, fw)
+	start := fw.Status.MonitorStartTime
+
+	f.fakeMultiWatcher.Errors <- fsnotify.ErrEventOverflow
+	require.Eventually(t, func() bool {
+		f.MustGet(key, fw)
+		return strings.Contains(fw.Status.Error, DetectedOverflowErrMsg)
+	}, timeout, interval, "overflow error was not propagated")
+
+	// The same monitor keeps delivering events.
+	f.ChangeAndWaitForSeenFile(key, "a", "1")
+	f.MustGet(key, fw)
+	assert.Empty(t, fw.Status.Error)
+	assert.Equal(t, start, fw.Status.MonitorStartTime)
+}
+
+func TestController_IgnoreEphemeralFiles(t *testing.T) {
+	f := newFixture(t)
+	key, orig := f.CreateSimpleFileWatch()
+	// spec should have no ignores - these are purely implicit ignores
+	require.Empty(t, orig.Spec.Ignores)
+
+	// sandwich in some ignored files with seen files on the outside as synchronization
+	f.ChangeAndWaitForSeenFile(key, "a", "start")
+	// see internal/ignore/ephemeral.go for where these come from - they're NOT part of a FileWatch spec
+	// but are always included at the filesystem watcher level by Tilt
+	f.ChangeFile("a", ".idea", "workspace.xml")
+	f.ChangeFile("b", "c", ".vim.swp")
+	f.ChangeAndWaitForSeenFile(key, "b", "c", "stop")
+
+	var fw filewatches.FileWatch
+	f.MustGet(key, &fw)
+	require.Equal(t, 2, len(fw.Status.FileEvents), "Wrong file event count")
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "start")}, fw.Status.FileEvents[0].SeenFiles)
+	assert.Equal(t, []string{f.tmpdir.JoinPath("b", "c", "stop")}, fw.Status.FileEvents[1].SeenFiles)
+}
+
+func TestController_DisableEphemeralIgnores(t *testing.T) {
+	f := newFixture(t)
+	spec := f.SimpleSpec()
+	spec.DisableEphemeralIgnores = true
+	spec.Ignores = []filewatches.IgnoreDef{{
+		BasePath: f.tmpdir.Path(),
+		Patterns: []string{"**/ignore_me"},
+	}}
+	key, _ := f.CreateFileWatch(spec)
+
+	f.ChangeAndWaitForSeenFile(key, "a", ".idea", "workspace.xml")
+	f.ChangeFile("a", "ignore_me")
+	f.ChangeAndWaitForSeenFile(key, "b", "c", ".vim.swp")
+
+	var fw filewatches.FileWatch
+	f.MustGet(key, &fw)
+	for _, e := range fw.Status.FileEvents {
+		assert.NotContains(t, e.SeenFiles, f.tmpdir.JoinPath("a", "ignore_me"), "spec ignores should still apply")
+	}
+}
+
+func TestController_WatchHiddenFiles(t *testing.T) {
+	f := newFixture(t)
+	key, _ := f.CreateSimpleFileWatch()
+
+	// Plain hidden files are watched by default; hidden editor temp files aren't.
+	f.ChangeFile("a", ".#main.go")
+	f.ChangeAndWaitForSeenFile(key, "a", ".env")
+	var fw filewatches.FileWatch
+	f.MustGet(key, &fw)
+	assert.NotContains(t, seenFiles(&fw), f.tmpdir.JoinPath("a", ".#main.go"))
+
+	fw.Spec.WatchHiddenFiles = true
+	f.Update(&fw)
+	f.reconcileFw(key)
+
+	f.ChangeAndWaitForSeenFile(key, "a", ".#main.go")
+	f.ChangeAndWaitForSeenFile(key, "a", ".env")
+
+	// Visible editor temp files are still ignored.
+	f.ChangeFile("a", "main.go~")
+	f.ChangeAndWaitForSeenFile(key, "a", "stop")
+	f.MustGet(key, &fw)
+	assert.NotContains(t, seenFiles(&fw), f.tmpdir.JoinPath("a", "main.go~"))
+}
+
+func TestController_CollapseRenames(t *testing.T) {
+	f := newFixture(t)
+	key, _ := f.CreateSimpleFileWatch()
+	f.ChangeAndWaitForSeenFile(key, "a", "start")
+
+	// simulate an editor writing a temp file and renaming it over the target
+	f.tmpdir.WriteFile(filepath.Join("a", "main.go"), "package main")
+	f.InOneBatch(key, func() {
+		f.ChangeFile("a", "main.go.tmp1234")
+		f.ChangeFile("a", "main.go.tmp1234")
+		f.ChangeFile("a", "main.go")
+	})
+	f.WaitForSeenFile(key, "a", "main.go")
+
+	var fw filewatches.FileWatch
+	f.MustGet(key, &fw)
+	require.Equal(t, 2, len(fw.Status.FileEvents), "Wrong file event count")
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "main.go")}, fw.Status.FileEvents[1].SeenFiles)
+	assert.Empty(t, fw.Status.FileEvents[1].DeletedFiles)
+}
+
+func TestController_SaveProfiles(t *testing.T) {
+	f := newFixture(t)
+	spec := f.SimpleSpec()
+	spec.DisableEphemeralIgnores = true
+	spec.SaveProfiles = []filewatches.FileWatchSaveProfile{filewatches.FileWatchSaveProfileVim, filewatches.FileWatchSaveProfileEmacs}
+	key, _ := f.CreateFileWatch(spec)
+	f.ChangeAndWaitForSeenFile(key, "a", "start")
+
+	// simulate Vim checking permissions, writing its swap and backup files, and then the target
+	f.tmpdir.WriteFile(filepath.Join("a", "main.go"), "package main")
+	f.InOneBatch(key, func() {
+		f.ChangeFile("a", "4913")
+		f.ChangeFile("a", ".main.go.swp")
+		f.ChangeFile("a", "main.go~")
+		f.ChangeFile("a", "main.go")
+		f.ChangeFile("a", ".main.go.swp")
+	})
+	f.WaitForSeenFile(key, "a", "main.go")
+
+	var fw filewatches.FileWatch
+	f.MustGet(key, &fw)
+	require.Equal(t, 2, len(fw.Status.FileEvents), "Wrong file event count")
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "main.go")}, fw.Status.FileEvents[1].SeenFiles)
+
+	// simulate Emacs auto-saving, where only its temp files change
+	f.tmpdir.WriteFile(filepath.Join("a", "notes.txt"), "hello")
+	f.InOneBatch(key, func() {
+		f.ChangeFile("a", ".#notes.txt")
+		f.ChangeFile("a", "#notes.txt#")
+	})
+	f.WaitForSeenFile(key, "a", "notes.txt")
+
+	f.MustGet(key, &fw)
+	require.Equal(t, 3, len(fw.Status.FileEvents), "Wrong file event count")
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "notes.txt")}, fw.Status.FileEvents[2].SeenFiles)
+	assert.Empty(t, fw.Status.FileEvents[2].DeletedFiles)
+}
+
+func TestController_Subscribe(t *testing.T) {
+	f := newFixture(t)
+	key, _ := f.CreateSimpleFileWatch()
+
+	var mu sync.Mutex
+	var seen []string
+	unsubscribe := f.controller.Subscribe(key, func(e filewatches.FileEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		seen = append(seen, e.SeenFiles...)
+	})
+	seenBySubscriber := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), seen...)
+	}
+
+	f.ChangeAndWaitForSeenFile(key, "a", "1")
+	require.Eventually(t, func() bool {
+		return len(seenBySubscriber()) == 1
+	}, timeout, interval, "subscriber was never called")
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "1")}, seenBySubscriber())
+
+	// other watches' events aren't delivered
+	otherKey := types.NamespacedName{Name: "other"}
+	otherCalled := false
+	defer f.controller.Subscribe(otherKey, func(e filewatches.FileEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		otherCalled = true
+	})()
+
+	unsubscribe()
+	unsubscribe()
+	f.ChangeAndWaitForSeenFile(key, "a", "2")
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "1")}, seenBySubscriber())
+	mu.Lock()
+	assert.False(t, otherCalled)
+	mu.Unlock()
+}
+
+func TestController_SortSeenFiles(t *testing.T) {
+	f := newFixture(t)
+	spec := f.SimpleSpec()
+	spec.RelativeTo = f.tmpdir.Path()
+	key, _ := f.CreateFileWatch(spec)
+
+	f.InOneBatch(key, func() {
+		f.ChangeFile("b", "c", "1")
+		f.ChangeFile("a", "2")
+		f.ChangeFile("a", "10")
+		f.ChangeFile("a", "1")
+	})
+	f.WaitForSeenFile(key, "a", "1")
+
+	var fw filewatches.FileWatch
+	f.MustGet(key, &fw)
+	require.Equal(t, 1, len(fw.Status.FileEvents), "Wrong file event count")
+	assert.Equal(t, []string{
+		f.tmpdir.JoinPath("a", "1"),
+		f.tmpdir.JoinPath("a", "10"),
+		f.tmpdir.JoinPath("a", "2"),
+		f.tmpdir.JoinPath("b", "c", "1"),
+	}, fw.Status.FileEvents[0].SeenFiles)
+	assert.Equal(t, []string{
+		filepath.Join("a", "1"),
+		filepath.Join("a", "10"),
+		filepath.Join("a", "2"),
+		filepath.Join("b", "c", "1"),
+	}, fw.Status.FileEvents[0].RelSeenFiles)
+	assert.Equal(t, fw.Status.FileEvents[0].SeenFiles, fw.Status.FileEvents[0].DeletedFiles)
+}
+
+func TestController_DedupeSeenFiles(t *testing.T) {
+	f := newFixture(t)
+	spec := f.SimpleSpec()
+	spec.PreserveSeenFilesOrder = true
+	key, _ := f.CreateFileWatch(spec)
+
+	f.InOneBatch(key, func() {
+		f.ChangeFile("a", "2")
+		f.ChangeFile("a", "1")
+		f.ChangeFile("a", "2")
+		f.ChangeFile("a", "1")
+		f.ChangeFile("a", "2")
+	})
+	f.WaitForSeenFile(key, "a", "1")
+
+	var fw filewatches.FileWatch
+	f.MustGet(key, &fw)
+	require.Equal(t, 1, len(fw.Status.FileEvents), "Wrong file event count")
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "2"), f.tmpdir.JoinPath("a", "1")}, fw.Status.FileEvents[0].SeenFiles)
+}
+
+func TestController_MaxBatchSize(t *testing.T) {
+	f := newFixture(t)
+	spec := f.SimpleSpec()
+	spec.MaxBatchSize = 2
+	key, _ := f.CreateFileWatch(spec)
+
+	f.InOneBatch(key, func() {
+		for i := 0; i < 5; i++ {
+			f.ChangeFile("a", strconv.Itoa(i))
+		}
+	})
+	f.WaitForSeenFile(key, "a", "4")
+
+	var fw filewatches.FileWatch
+	f.MustGet(key, &fw)
+	require.Equal(t, 3, len(fw.Status.FileEvents), "Wrong file event count")
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "0"), f.tmpdir.JoinPath("a", "1")}, fw.Status.FileEvents[0].SeenFiles)
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "2"), f.tmpdir.JoinPath("a", "3")}, fw.Status.FileEvents[1].SeenFiles)
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "4")}, fw.Status.FileEvents[2].SeenFiles)
+
+	// Full batches were split off because of their size, while the rest was flushed by a timer.
+	assert.Equal(t, filewatches.FileEventFlushReasonBatchSize, fw.Status.FileEvents[0].FlushReason)
+	assert.Equal(t, filewatches.FileEventFlushReasonBatchSize, fw.Status.FileEvents[1].FlushReason)
+	assert.Contains(t, []filewatches.FileEventFlushReason{
+		filewatches.FileEventFlushReasonQuietPeriod,
+		filewatches.FileEventFlushReasonTimer,
+	}, fw.Status.FileEvents[2].FlushReason)
+}
+
+func TestController_MaxBatchSizeFlushesBeforeTimers(t *testing.T) {
+	f := newFixture(t)
+	spec := f.SimpleSpec()
+	spec.MaxBatchSize = 2
+	key, _ := f.CreateFileWatch(spec)
+
+	// Neither debounce timer fires, so full batches are only recorded because of their size.
+	f.fakeTimerMaker.RestTimerLock.Lock()
+	defer f.fakeTimerMaker.RestTimerLock.Unlock()
+	f.fakeTimerMaker.MaxTimerLock.Lock()
+	defer f.fakeTimerMaker.MaxTimerLock.Unlock()
+	for i := 0; i < 5; i++ {
+		f.ChangeFile("a", strconv.Itoa(i))
+	}
+	f.WaitForSeenFile(key, "a", "3")
+
+	var fw filewatches.FileWatch
+	f.MustGet(key, &fw)
+	require.Equal(t, 2, len(fw.Status.FileEvents), "Wrong file event count")
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "0"), f.tmpdir.JoinPath("a", "1")}, fw.Status.FileEvents[0].SeenFiles)
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "2"), f.tmpdir.JoinPath("a", "3")}, fw.Status.FileEvents[1].SeenFiles)
+	for _, e := range fw.Status.FileEvents {
+		assert.Equal(t, filewatches.FileEventFlushReasonBatchSize, e.FlushReason)
+	}
+}
+
+func TestController_FlushReason(t *testing.T) {
+	f := newFixture(t)
+	key, _ := f.CreateSimpleFileWatch()
+
+	// Hold the max timer, so the batch is only flushed once the rest timer fires.
+	f.fakeTimerMaker.MaxTimerLock.Lock()
+	f.ChangeAndWaitForSeenFile(key, "a", "1")
+	f.fakeTimerMaker.MaxTimerLock.Unlock()
+
+	// Hold the rest timer, as if changes kept coming in, so the batch is flushed by the max timer.
+	f.fakeTimerMaker.RestTimerLock.Lock()
+	f.ChangeAndWaitForSeenFile(key, "a", "2")
+	f.fakeTimerMaker.RestTimerLock.Unlock()
+
+	var fw filewatches.FileWatch
+	f.MustGet(key, &fw)
+	require.Len(t, fw.Status.FileEvents, 2)
+	assert.Equal(t, filewatches.FileEventFlushReasonQuietPeriod, fw.Status.FileEvents[0].FlushReason)
+	assert.Equal(t, filewatches.FileEventFlushReasonTimer, fw.Status.FileEvents[1].FlushReason)
+}
+
+func TestController_ForceRescan(t *testing.T) {
+	f := newFixture(t)
+	f.tmpdir.WriteFile(filepath.Join("a", "1"), "hello")
+	f.tmpdir.WriteFile(filepath.Join("a", "sub", "2"), "hello")
+	f.tmpdir.WriteFile(filepath.Join("b", "c", "3"), "hello")
+	f.tmpdir.WriteFile(filepath.Join("b", "unwatched"), "hello")
+	f.tmpdir.WriteFile(filepath.Join("a", ".1.swp"), "hello")
+
+	key, fw := f.CreateSimpleFileWatch()
+	f.MustGet(key, fw)
+	require.Empty(t, fw.Status.FileEvents)
+
+	fw.Spec.ForceRescanToken = "1"
+	f.Update(fw)
+	f.WaitForSeenFile(key, "b", "c", "3")
+
+	f.MustGet(key, fw)
+	assert.Equal(t, "1", fw.Status.LastRescanToken)
+	require.Equal(t, 1, len(fw.Status.FileEvents), "Wrong file event count")
+	assert.ElementsMatch(t, []string{
+		f.tmpdir.JoinPath("a", "1"),
+		f.tmpdir.JoinPath("a", "sub", "2"),
+		f.tmpdir.JoinPath("b", "c", "3"),
+	}, fw.Status.FileEvents[0].SeenFiles)
+	assert.Equal(t, filewatches.FileEventFlushReasonForceRescan, fw.Status.FileEvents[0].FlushReason)
+
+	// reconciling again with the same token doesn't rescan
+	fw.Spec.MaxEventHistory = pointer.Int32(10)
+	f.Update(fw)
+	f.ChangeAndWaitForSeenFile(key, "a", "4")
+	f.MustGet(key, fw)
+	require.Equal(t, 2, len(fw.Status.FileEvents), "Wrong file event count")
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "4")}, fw.Status.FileEvents[1].SeenFiles)
+}
+
+func TestController_ReplayToken(t *testing.T) {
+	f := newFixture(t)
+	key, fw := f.CreateSimpleFileWatch()
+
+	f.InOneBatch(key, func() {
+		f.ChangeFile("a", "1")
+		f.ChangeFile("b", "c", "2")
+	})
+	f.WaitForSeenFile(key, "a", "1")
+	f.WaitForSeenFile(key, "b", "c", "2")
+	f.MustGet(key, fw)
+	require.Equal(t, 1, len(fw.Status.FileEvents), "Wrong file event count")
+	original := fw.Status.FileEvents[0]
+	monitorStart := fw.Status.MonitorStartTime
+
+	var replayed []filewatches.FileEvent
+	var activeWatches []WatchSnapshot
+	unsubscribe := f.controller.Subscribe(key, func(e filewatches.FileEvent) {
+		replayed = append(replayed, e)
+		// Subscribers can call back into the controller.
+		activeWatches = f.controller.ActiveWatches()
+	})
+	defer unsubscribe()
+
+	fw.Spec.ReplayToken = "1"
+	f.Update(fw)
+	f.MustGet(key, fw)
+	assert.Equal(t, "1", fw.Status.LastReplayToken)
+	assert.Equal(t, monitorStart, fw.Status.MonitorStartTime, "Filesystem monitor should not have been restarted")
+	require.Equal(t, 2, len(fw.Status.FileEvents), "Wrong file event count")
+	replay := fw.Status.FileEvents[1]
+	assert.Equal(t, original.SeenFiles, replay.SeenFiles)
+	assert.Equal(t, original.Seq+1, replay.Seq)
+	assert.True(t, replay.Time.After(original.Time.Time), "Replayed event should have a new timestamp")
+	assert.Equal(t, replay.Time, fw.Status.LastEventTime)
+	require.Len(t, replayed, 1)
+	assert.Equal(t, replay.Seq, replayed[0].Seq)
+	assert.Len(t, activeWatches, 1)
+
+	// reconciling again with the same token doesn't replay
+	f.reconcileFw(key)
+	f.MustGet(key, fw)
+	assert.Equal(t, 2, len(fw.Status.FileEvents), "Wrong file event count")
+}
+
+func TestController_StatusWatchedPaths(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no user-space symlinks on windows")
+	}
+	f := newFixture(t)
+	f.tmpdir.MkdirAll("real")
+	require.NoError(t, os.Symlink(f.tmpdir.JoinPath("real"), f.tmpdir.JoinPath("link")))
+	realPath, err := filepath.EvalSymlinks(f.tmpdir.JoinPath("real"))
+	require.NoError(t, err)
+
+	spec := f.SimpleSpec()
+	spec.WatchedPaths = []string{f.tmpdir.JoinPath("link"), f.tmpdir.JoinPath("src", "**", "*.go")}
+	key, fw := f.CreateFileWatch(spec)
+
+	f.MustGet(key, fw)
+	// src doesn't exist yet, so it's reported as-is
+	assert.Equal(t, []string{realPath, f.tmpdir.JoinPath("src")}, fw.Status.WatchedPaths)
+}
+
+func TestController_FollowSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no user-space symlinks on windows")
+	}
+	f := newFixture(t)
+	f.tmpdir.WriteFile(filepath.Join("vendor-real", "lib.go"), "package lib")
+	f.tmpdir.MkdirAll("a")
+	require.NoError(t, os.Symlink(f.tmpdir.JoinPath("vendor-real"), f.tmpdir.JoinPath("a", "vendor")))
+
+	spec := f.SimpleSpec()
+	spec.FollowSymlinks = true
+	key, fw := f.CreateFileWatch(spec)
+	f.MustGet(key, fw)
+	realVendor, err := filepath.EvalSymlinks(f.tmpdir.JoinPath("vendor-real"))
+	require.NoError(t, err)
+	assert.Contains(t, fw.Status.WatchedPaths, realVendor)
+
+	// changes behind the symlink are reported under the symlink
+	f.fakeMultiWatcher.Events <- watch.NewFileEvent(filepath.Join(realVendor, "lib.go"))
+	f.WaitForSeenFile(key, "a", "vendor", "lib.go")
+}
+
+func TestController_FollowSymlinksLoop(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no user-space symlinks on windows")
+	}
+	f := newFixture(t)
+	f.tmpdir.MkdirAll("a")
+	f.tmpdir.MkdirAll("outside")
+	require.NoError(t, os.Symlink(f.tmpdir.JoinPath("a"), f.tmpdir.JoinPath("a", "self")))
+	require.NoError(t, os.Symlink(f.tmpdir.JoinPath("outside"), f.tmpdir.JoinPath("a", "out")))
+	require.NoError(t, os.Symlink(f.tmpdir.JoinPath("a"), f.tmpdir.JoinPath("outside", "back")))
+	require.NoError(t, os.Symlink(f.tmpdir.JoinPath("a", "y"), f.tmpdir.JoinPath("a", "x")))
+	require.NoError(t, os.Symlink(f.tmpdir.JoinPath("a", "x"), f.tmpdir.JoinPath("a", "y")))
+
+	links := followSymlinks([]string{f.tmpdir.JoinPath("a")}, watch.EmptyMatcher{})
+	realOutside, err := filepath.EvalSymlinks(f.tmpdir.JoinPath("outside"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{f.tmpdir.JoinPath("a", "out"): realOutside}, links)
+
+	spec := f.SimpleSpec()
+	spec.FollowSymlinks = true
+	key, fw := f.CreateFileWatch(spec)
+	f.MustGet(key, fw)
+	assert.Empty(t, fw.Status.Error)
+	assert.False(t, fw.Status.MonitorStartTime.IsZero())
+	f.ChangeAndWaitForSeenFile(key, "a", "1")
+}
+
+func TestController_GlobWatchedPaths(t *testing.T) {
+	f := newFixture(t)
+	spec := f.SimpleSpec()
+	spec.WatchedPaths = []string{f.tmpdir.JoinPath("src", "**", "*.go")}
+	key, _ := f.CreateFileWatch(spec)
+
+	// files are created after the watch has started
+	f.tmpdir.WriteFile(filepath.Join("src", "main.go"), "package main")
+	f.tmpdir.WriteFile(filepath.Join("src", "README.md"), "# readme")
+	f.tmpdir.WriteFile(filepath.Join("src", "pkg", "lib.go"), "package pkg")
+	f.tmpdir.WriteFile(filepath.Join("src", "pkg", ".lib.go.swp"), "")
+
+	f.ChangeAndWaitForSeenFile(key, "src", "main.go")
+	f.ChangeFile("src", "README.md")
+	f.ChangeFile("src", "pkg", ".lib.go.swp")
+	f.ChangeAndWaitForSeenFile(key, "src", "pkg", "lib.go")
+
+	var fw filewatches.FileWatch
+	f.MustGet(key, &fw)
+	require.Equal(t, 2, len(fw.Status.FileEvents), "Wrong file event count")
+	assert.Equal(t, []string{f.tmpdir.JoinPath("src", "main.go")}, fw.Status.FileEvents[0].SeenFiles)
+	assert.Equal(t, []string{f.tmpdir.JoinPath("src", "pkg", "lib.go")}, fw.Status.FileEvents[1].SeenFiles)
+}
+
+// TestController_Watcher_Cancel peeks into internal/unexported portions of the controller to inspect the actual
+// filesystem monitor so it can ensure reconciler is not leaking resources; other tests should prefer observing
+// desired state!
+func TestController_Watcher_Cancel(t *testing.T) {
+	f := newFixture(t)
+	key, _ := f.CreateSimpleFileWatch()
+
+	assert.Equalf(t, 1, len(f.controller.targetWatches), "There should be exactly one file watcher")
+	watcher := f.controller.targetWatches[key]
+	require.NotNilf(t, watcher, "Watcher does not exist for %q", key.String())
+
+	// cancel the root context, which should propagate to the watcher
+	f.Cancel()
+
+	require.Eventuallyf(t, func() bool {
+		watcher.mu.Lock()
+		defer watcher.mu.Unlock()
+		return watcher.done
+	}, time.Second, 10*time.Millisecond, "Watcher was never cleaned up")
+}
+
+func TestController_Watcher_CancelDrainsEvents(t *testing.T) {
+	drainWindow := 2 * time.Second
+	t.Setenv(ShutdownDrainTimeoutEnvVar, drainWindow.String())
+	f := newFixture(t)
+	key, _ := f.CreateSimpleFileWatch()
+
+	w := f.controller.targetWatches[key]
+	fakeWatcher := w.notify.(*fsevent.FakeWatcher)
+
+	// Hold the debounce timers, so that the events are still buffered when the context is cancelled.
+	f.fakeTimerMaker.RestTimerLock.Lock()
+	f.fakeTimerMaker.MaxTimerLock.Lock()
+	defer f.fakeTimerMaker.MaxTimerLock.Unlock()
+	defer f.fakeTimerMaker.RestTimerLock.Unlock()
+
+	f.ChangeFile("a", "1")
+	f.ChangeFile("a", "2")
+	require.Eventually(t, func() bool {
+		return fakeWatcher.TotalEventCount() == 2 && fakeWatcher.QueuedCount() == 0
+	}, timeout, interval, "Events were never read")
+
+	start := time.Now()
+	f.Cancel()
+
+	require.Eventuallyf(t, func() bool {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		return w.done
+	}, drainWindow, 10*time.Millisecond, "Watcher was never cleaned up")
+	assert.Less(t, time.Since(start), drainWindow, "Drain took longer than the drain window")
+
+	// The fixture's context is cancelled, so read with a fresh one.
+	var fw filewatches.FileWatch
+	require.NoError(t, f.Client.Get(context.Background(), key, &fw))
+	require.NotEmpty(t, fw.Status.FileEvents, "Final status was never written")
+	assert.ElementsMatch(t,
+		[]string{f.tmpdir.JoinPath("a", "1"), f.tmpdir.JoinPath("a", "2")},
+		fw.Status.FileEvents[len(fw.Status.FileEvents)-1].SeenFiles)
+	assert.Equal(t, filewatches.FileEventFlushReasonShutdownDrain,
+		fw.Status.FileEvents[len(fw.Status.FileEvents)-1].FlushReason)
+}
+
+func TestShutdownDrainTimeoutFromEnv(t *testing.T) {
+	for _, tc := range []struct {
+		value    string
+		expected time.Duration
+	}{
+		{"", defaultShutdownDrainTimeout},
+		{"5s", 5 * time.Second},
+		{"0", 0},
+		{"-1s", defaultShutdownDrainTimeout},
+		{"soon", defaultShutdownDrainTimeout},
+	} {
+		t.Run(tc.value, func(t *testing.T) {
+			t.Setenv(ShutdownDrainTimeoutEnvVar, tc.value)
+			assert.Equal(t, tc.expected, shutdownDrainTimeoutFromEnv())
+		})
+	}
+}
+
+func TestController_Reconcile_Create(t *testing.T) {
+	f := newFixture(t)
+	key, fw := f.CreateSimpleFileWatch()
+
+	f.MustGet(key, fw)
+	assert.NotZero(t, fw.Status.MonitorStartTime, "Filesystem monitor was not started")
+	assert.Equal(t, fsevent.BufferMinRestDuration, fw.Status.DebounceDuration.Duration)
+
+	ready := apimeta.FindStatusCondition(fw.Status.Conditions, filewatches.FileWatchConditionReady)
+	require.NotNil(t, ready, "Ready condition was not set")
+	assert.Equal(t, metav1.ConditionTrue, ready.Status)
+	assert.Equal(t, "MonitorStarted", ready.Reason)
+}
+
+func TestController_DebounceDuration(t *testing.T) {
+	f := newFixture(t)
+
+	var mu sync.Mutex
+	var requested []time.Duration
+	debounceTimers := f.controller.debounceTimers
+	f.controller.debounceTimers = func(d time.Duration) fsevent.DebounceTimers {
+		mu.Lock()
+		requested = append(requested, d)
+		mu.Unlock()
+		return debounceTimers(d)
+	}
+
+	spec := f.SimpleSpec()
+	spec.DebounceDuration = metav1.Duration{Duration: 50 * time.Millisecond}
+	key, fw := f.CreateFileWatch(spec)
+
+	f.MustGet(key, fw)
+	assert.Equal(t, 50*time.Millisecond, fw.Status.DebounceDuration.Duration)
+
+	f.ChangeAndWaitForSeenFile(key, "a", "1")
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Contains(t, requested, 50*time.Millisecond)
+	assert.NotContains(t, requested, fsevent.BufferMinRestDuration)
+}
+
+func TestController_DebounceDuration_PerWatch(t *testing.T) {
+	f := newFixture(t)
+
+	// drive the debounce timers from their own clock, so that the only waiters on it are timers
+	timerClock := clockwork.NewFakeClock()
+	f.controller.debounceTimers = fsevent.NewDebounceTimersMaker(timerClock.After)
+
+	newWatch := func(name string, debounce time.Duration) types.NamespacedName {
+		fw := &filewatches.FileWatch{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: apis.SanitizeName(t.Name()),
+				Name:      name,
+			},
+			Spec: filewatches.FileWatchSpec{
+				WatchedPaths:     []string{f.tmpdir.JoinPath("a")},
+				DebounceDuration: metav1.Duration{Duration: debounce},
+			},
+		}
+		f.Create(fw)
+		key := f.KeyForObject(fw)
+		f.reconcileFw(key)
+		return key
+	}
+	fast := newWatch("fast", time.Second)
+	slow := newWatch("slow", 5*time.Second)
+
+	// each watch starts a rest and a max timer for the first change
+	f.ChangeFile("a", "1")
+	timerClock.BlockUntil(4)
+
+	// the fast watch's window has passed, so it emits its batch
+	timerClock.Advance(2 * time.Second)
+	f.WaitForSeenFile(fast, "a", "1")
+
+	// the fast watch starts a new batch, while the slow watch extends its current one
+	f.ChangeFile("a", "2")
+	timerClock.BlockUntil(6)
+	timerClock.Advance(2 * time.Second)
+	f.WaitForSeenFile(fast, "a", "2")
+
+	timerClock.Advance(4 * time.Second)
+	f.WaitForSeenFile(slow, "a", "2")
+
+	var fw filewatches.FileWatch
+	f.MustGet(fast, &fw)
+	require.Len(t, fw.Status.FileEvents, 2)
+	assert.Len(t, fw.Status.FileEvents[0].SeenFiles, 1)
+	assert.Len(t, fw.Status.FileEvents[1].SeenFiles, 1)
+
+	f.MustGet(slow, &fw)
+	require.Len(t, fw.Status.FileEvents, 1)
+	assert.ElementsMatch(t,
+		[]string{f.tmpdir.JoinPath("a", "1"), f.tmpdir.JoinPath("a", "2")},
+		fw.Status.FileEvents[0].SeenFiles)
+}
+
+func TestController_QuietPeriod(t *testing.T) {
+	f := newFixture(t)
+
+	timerClock := clockwork.NewFakeClock()
+	f.controller.debounceTimers = fsevent.NewDebounceTimersMaker(timerClock.After)
+
+	spec := f.SimpleSpec()
+	spec.QuietPeriod = metav1.Duration{Duration: time.Second}
+	key, fw := f.CreateFileWatch(spec)
+
+	// keep changing files for longer than BufferMaxDuration, never leaving a 1s gap
+	n := int(2*fsevent.BufferMaxDuration/time.Second) + 2
+	var expected []string
+	for i := 0; i < n; i++ {
+		f.ChangeFile("a", strconv.Itoa(i))
+		expected = append(expected, f.tmpdir.JoinPath("a", strconv.Itoa(i)))
+
+		// the rest timer from the previous change is still pending (if any), plus the new one
+		timerClock.BlockUntil(min(i+1, 2))
+		timerClock.Advance(500 * time.Millisecond)
+	}
+
+	f.MustGet(key, fw)
+	assert.Empty(t, fw.Status.FileEvents, "batch flushed before the quiet period elapsed")
+
+	// a gap triggers the flush
+	timerClock.Advance(time.Second)
+	f.WaitForSeenFile(key, "a", strconv.Itoa(n-1))
+
+	f.MustGet(key, fw)
+	require.Len(t, fw.Status.FileEvents, 1)
+	assert.ElementsMatch(t, expected, fw.Status.FileEvents[0].SeenFiles)
+}
+
+func TestController_FileEventIdentifiesFileWatch(t *testing.T) {
+	f := newFixture(t)
+	key, fw := f.CreateSimpleFileWatch()
+
+	f.ChangeAndWaitForSeenFile(key, "a", "1")
+
+	f.MustGet(key, fw)
+	require.Len(t, fw.Status.FileEvents, 1)
+	assert.Equal(t, key.Name, fw.Status.FileEvents[0].FileWatchName)
+	assert.Equal(t, key.Namespace, fw.Status.FileEvents[0].FileWatchNamespace)
+}
+
+func TestController_WatchSymlinkTargets(t *testing.T) {
+	f := newFixture(t)
+	f.tmpdir.MkdirAll(filepath.Join("releases", "1"))
+	f.tmpdir.MkdirAll(filepath.Join("releases", "2"))
+	current := f.tmpdir.JoinPath("current")
+	require.NoError(t, os.Symlink(filepath.Join("releases", "1"), current))
+
+	spec := filewatches.FileWatchSpec{
+		WatchedPaths:        []string{current},
+		WatchSymlinkTargets: true,
+	}
+	key, fw := f.CreateFileWatch(spec)
+
+	// re-point the symlink atomically, the way deploy scripts usually do
+	next := f.tmpdir.JoinPath("current.next")
+	require.NoError(t, os.Symlink(filepath.Join("releases", "2"), next))
+	require.NoError(t, os.Rename(next, current))
+	f.ChangeAndWaitForSeenFile(key, "current")
+
+	f.MustGet(key, fw)
+	require.Len(t, fw.Status.FileEvents, 1)
+	assert.Equal(t, []filewatches.SymlinkTargetChange{{
+		Path:      current,
+		OldTarget: f.tmpdir.JoinPath("releases", "1"),
+		NewTarget: f.tmpdir.JoinPath("releases", "2"),
+	}}, fw.Status.FileEvents[0].SymlinkTargetChanges)
+
+	// the watch is restarted to follow the new target
+	require.Eventually(t, func() bool {
+		f.reconcileFw(key)
+		f.MustGet(key, fw)
+		return slices.Contains(fw.Status.WatchedPaths, f.tmpdir.JoinPath("releases", "2"))
+	}, time.Second, 10*time.Millisecond)
+	assert.Len(t, fw.Status.FileEvents, 1, "event history should survive the restart")
+}
+
+func TestController_DiffArchives(t *testing.T) {
+	f := newFixture(t)
+	archive := f.tmpdir.JoinPath("app.zip")
+	writeZip(t, archive, map[string]string{"a.txt": "1", "lib/b.txt": "1"})
+
+	spec := filewatches.FileWatchSpec{
+		WatchedPaths: []string{archive, f.tmpdir.JoinPath("src")},
+		DiffArchives: true,
+	}
+	key, fw := f.CreateFileWatch(spec)
+
+	writeZip(t, archive, map[string]string{"a.txt": "1", "lib/b.txt": "2", "c.txt": "1"})
+	f.ChangeAndWaitForSeenFile(key, "app.zip")
+	f.MustGet(key, fw)
+	require.Len(t, fw.Status.FileEvents, 1)
+	assert.Equal(t, []filewatches.ArchiveChange{{Path: archive, Entries: []string{"c.txt", "lib/b.txt"}}},
+		fw.Status.FileEvents[0].ArchiveChanges)
+
+	// Rebuilding the archive with the same contents isn't a change. Events are handled in
+	// order, so once the next change has been seen, the rebuild has been dropped.
+	writeZip(t, archive, map[string]string{"c.txt": "1", "lib/b.txt": "2", "a.txt": "1"})
+	f.ChangeFile("app.zip")
+	f.ChangeAndWaitForSeenFile(key, "src", "main.go")
+	f.MustGet(key, fw)
+	require.Len(t, fw.Status.FileEvents, 2)
+	assert.Equal(t, []string{f.tmpdir.JoinPath("src", "main.go")}, fw.Status.FileEvents[1].SeenFiles)
+	assert.Empty(t, fw.Status.FileEvents[1].ArchiveChanges)
+
+	writeZip(t, archive, map[string]string{"c.txt": "1", "lib/b.txt": "2"})
+	f.ChangeAndWaitForSeenFile(key, "app.zip")
+	f.MustGet(key, fw)
+	require.Len(t, fw.Status.FileEvents, 3)
+	assert.Equal(t, []filewatches.ArchiveChange{{Path: archive, Entries: []string{"a.txt"}}},
+		fw.Status.FileEvents[2].ArchiveChanges)
+}
+
+func TestController_TrackMoves(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("moves are tracked by inode, which this test only relies on under Linux")
+	}
+	f := newFixture(t)
+	f.tmpdir.WriteFile(filepath.Join("a", "old.go"), "package a")
+	f.tmpdir.WriteFile(filepath.Join("a", "other.go"), "package a")
+
+	spec := f.SimpleSpec()
+	spec.TrackMoves = true
+	key, fw := f.CreateFileWatch(spec)
+
+	oldPath := f.tmpdir.JoinPath("a", "old.go")
+	newPath := f.tmpdir.JoinPath("b", "c", "new.go")
+	require.NoError(t, os.MkdirAll(filepath.Dir(newPath), 0755))
+	require.NoError(t, os.Rename(oldPath, newPath))
+	// A file deleted alongside a new one isn't a move unless it's the same file. The new
+	// file may be handed the deleted one's inode, but not its size.
+	require.NoError(t, os.Remove(f.tmpdir.JoinPath("a", "other.go")))
+	f.tmpdir.WriteFile(filepath.Join("b", "c", "other.go"), "package c // replaces a/other.go")
+	f.InOneBatch(key, func() {
+		f.ChangeFile("a", "old.go")
+		f.ChangeFile("b", "c", "new.go")
+		f.ChangeFile("a", "other.go")
+		f.ChangeFile("b", "c", "other.go")
+	})
+	f.WaitForSeenFile(key, "b", "c", "new.go")
+
+	f.MustGet(key, fw)
+	require.Len(t, fw.Status.FileEvents, 1)
+	event := fw.Status.FileEvents[0]
+	assert.Equal(t, []filewatches.FileMove{{OldPath: oldPath, NewPath: newPath}}, event.Moves)
+	assert.Contains(t, event.DeletedFiles, oldPath)
+	assert.Contains(t, event.SeenFiles, newPath)
+}
+
+func TestCheckMoves_ReusedInode(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("moves are tracked by inode, which this test only relies on under Linux")
+	}
+	f := tempdir.NewTempDirFixture(t)
+	oldPath := f.WriteFile("old.go", "package a")
+	w := &watcher{fileIDs: listFileIDs([]string{f.Path()}, watch.EmptyMatcher{})}
+	require.Contains(t, w.fileIDs, oldPath)
+
+	require.NoError(t, os.Remove(oldPath))
+	newPath := f.WriteFile("new.go", "package a // new")
+	info, err := os.Lstat(newPath)
+	require.NoError(t, err)
+	newID, ok := fileIDOf(info)
+	require.True(t, ok)
+
+	// Pretend the new file was handed the deleted one's inode.
+	oldID := w.fileIDs[oldPath]
+	oldID.dev, oldID.ino = newID.dev, newID.ino
+	w.fileIDs[oldPath] = oldID
+
+	moves := w.checkMoves([]string{oldPath, newPath}, map[string]bool{newPath: true})
+	assert.Empty(t, moves)
+	assert.NotContains(t, w.fileIDs, oldPath)
+	assert.Equal(t, newID, w.fileIDs[newPath])
+}
+
+func writeZip(t *testing.T, path string, entries map[string]string) {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, contents := range entries {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = io.WriteString(w, contents)
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0644))
+}
+
+func TestWatchSymlinkTargets_IgnoresSiblings(t *testing.T) {
+	dir := t.TempDir()
+	current := filepath.Join(dir, "current")
+	links := map[string]string{current: filepath.Join(dir, "releases", "1")}
+
+	paths, m := watchSymlinkTargets([]string{current}, watch.EmptyMatcher{}, links)
+	assert.Equal(t, []string{current, dir}, paths)
+
+	for _, tc := range []struct {
+		path         string
+		ignored      bool
+		ignoredAsDir bool
+	}{
+		{current, false, false},
+		{filepath.Join(current, "main.go"), false, false},
+		{filepath.Join(dir, "current.next"), true, true},
+		{filepath.Join(dir, "releases"), true, true},
+	} {
+		ignored, err := m.Matches(tc.path)
+		require.NoError(t, err)
+		assert.Equal(t, tc.ignored, ignored, "Matches(%q)", tc.path)
+		ignoredAsDir, err := m.MatchesEntireDir(tc.path)
+		require.NoError(t, err)
+		assert.Equal(t, tc.ignoredAsDir, ignoredAsDir, "MatchesEntireDir(%q)", tc.path)
+	}
+}
+
+func TestController_EventBufferSize_Full(t *testing.T) {
+	f := newFixture(t)
+	spec := f.SimpleSpec()
+	spec.EventBufferSize = 1
+	key, _ := f.CreateFileWatch(spec)
+
+	f.controller.mu.Lock()
+	w := f.controller.targetWatches[key]
+	f.controller.mu.Unlock()
+
+	// Stall the dispatch loop while it records the first batch, so that the next batch
+	// backs up in the debouncer and the buffer fills behind it.
+	w.mu.Lock()
+	var changed []string
+	require.Eventually(t, func() bool {
+		name := strconv.Itoa(len(changed))
+		f.ChangeFile("a", name)
+		changed = append(changed, name)
+		return strings.Contains(f.Stdout(), "event buffer is full")
+	}, time.Second, 10*time.Millisecond)
+	w.mu.Unlock()
+
+	assert.Contains(t, f.Stdout(), "Consider increasing spec.eventBufferSize (currently 1)")
+	for _, name := range changed {
+		f.WaitForSeenFile(key, "a", name)
+	}
+}
+
+func TestController_IgnorePatternsFromConfigMap(t *testing.T) {
+	f := newFixture(t)
+	cm := &filewatches.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "ignore-patterns"},
+		Data:       map[string]string{"patterns": "# generated\na/*.log\n\na/build\n"},
+	}
+	require.NoError(t, f.Client.Create(f.Context(), cm))
+
+	spec := f.SimpleSpec()
+	spec.Ignores = []filewatches.IgnoreDef{{
+		BasePath: f.tmpdir.Path(),
+		Patterns: []string{"a/tmp"},
+		PatternsConfigMap: &filewatches.ConfigMapPatternsSource{
+			Name: "ignore-patterns",
+			Key:  "patterns",
+		},
+	}}
+	key, fw := f.CreateFileWatch(spec)
+
+	f.ChangeFile("a", "tmp")
+	f.ChangeFile("a", "debug.log")
+	f.ChangeFile("a", "build")
+	f.ChangeAndWaitForSeenFile(key, "a", "1")
+	f.MustGet(key, fw)
+	assert.ElementsMatch(t, []string{f.tmpdir.JoinPath("a", "1")}, seenFiles(fw))
+
+	// the new rules take effect once the ConfigMap changes
+	cm.Data["patterns"] = "a/*.txt"
+	require.NoError(t, f.Client.Update(f.Context(), cm))
+	f.reconcileFw(key)
+
+	f.ChangeFile("a", "notes.txt")
+	f.ChangeAndWaitForSeenFile(key, "a", "debug.log")
+	f.MustGet(key, fw)
+	assert.ElementsMatch(t, []string{f.tmpdir.JoinPath("a", "1"), f.tmpdir.JoinPath("a", "debug.log")}, seenFiles(fw))
+}
+
+func TestController_ActiveIgnoreRules(t *testing.T) {
+	f := newFixture(t)
+	cm := &filewatches.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "ignore-patterns"},
+		Data:       map[string]string{"patterns": "# generated\n*.log\n\nbuild\n"},
+	}
+	require.NoError(t, f.Client.Create(f.Context(), cm))
+	f.tmpdir.WriteFile(filepath.Join("a", ".gitignore"), "# deps\nnode_modules/\n*.tmp\n!keep.tmp\n")
+
+	spec := f.SimpleSpec()
+	spec.Ignores = []filewatches.IgnoreDef{
+		// Patterns without a BasePath apply to both watched paths, but are still counted once.
+		{Patterns: []string{"*.bak", "dist"}},
+		{BasePath: f.tmpdir.JoinPath("a"), Regex: []string{`\.swp$`}},
+		{BasePath: f.tmpdir.JoinPath("b", "c", "vendor")},
+		{PatternsConfigMap: &filewatches.ConfigMapPatternsSource{Name: "ignore-patterns", Key: "patterns"}},
+		{GitignoreFile: f.tmpdir.JoinPath("a", ".gitignore")},
+		// A gitignore file that doesn't exist (yet) has no rules.
+		{GitignoreFile: f.tmpdir.JoinPath("b", ".gitignore")},
+	}
+	key, fw := f.CreateFileWatch(spec)
+	f.MustGet(key, fw)
+	require.Empty(t, fw.Status.SetupError)
+	assert.Equal(t, &filewatches.IgnoreRuleCounts{
+		Inline:    4,
+		File:      5,
+		Ephemeral: int32(len(ignore.EphemeralPatterns)),
+	}, fw.Status.ActiveIgnoreRules)
+
+	fw.Spec.DisableEphemeralIgnores = true
+	fw.Spec.Ignores = nil
+	f.Update(fw)
+	f.MustGet(key, fw)
+	assert.Equal(t, &filewatches.IgnoreRuleCounts{}, fw.Status.ActiveIgnoreRules)
+}
+
+func seenFiles(fw *filewatches.FileWatch) []string {
+	var result []string
+	for _, e := range fw.Status.FileEvents {
+		result = append(result, e.SeenFiles...)
+	}
+	return result
+}
+
+func TestController_StaleAfter(t *testing.T) {
+	f := newFixture(t)
+	spec := f.SimpleSpec()
+	spec.StaleAfter = metav1.Duration{Duration: time.Minute}
+	key, fw := f.CreateFileWatch(spec)
+
+	f.MustGet(key, fw)
+	assert.True(t, apimeta.IsStatusConditionFalse(fw.Status.Conditions, filewatches.FileWatchConditionStale))
+
+	result := f.reconcileFw(key)
+	assert.Equal(t, time.Minute, result.RequeueAfter)
+
+	// no events for longer than StaleAfter
+	f.clock.Advance(result.RequeueAfter)
+	f.reconcileFw(key)
+	f.MustGet(key, fw)
+	assert.True(t, apimeta.IsStatusConditionTrue(fw.Status.Conditions, filewatches.FileWatchConditionStale))
+
+	f.ChangeAndWaitForSeenFile(key, "a", "1")
+	result = f.reconcileFw(key)
+	assert.Equal(t, time.Minute, result.RequeueAfter)
+	f.MustGet(key, fw)
+	assert.True(t, apimeta.IsStatusConditionFalse(fw.Status.Conditions, filewatches.FileWatchConditionStale))
+}
+
+func TestController_MaxEventsPerSecond(t *testing.T) {
+	f := newFixture(t)
+	spec := f.SimpleSpec()
+	spec.MaxEventsPerSecond = 2
+	key, fw := f.CreateFileWatch(spec)
+
+	// a burst of batches, all within the same second
+	for i := 1; i <= 5; i++ {
+		f.ChangeAndWaitForSeenFile(key, "a", strconv.Itoa(i))
+	}
+
+	f.MustGet(key, fw)
+	require.Len(t, fw.Status.FileEvents, 2)
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "1")}, fw.Status.FileEvents[0].SeenFiles)
+	assert.Equal(t, []string{
+		f.tmpdir.JoinPath("a", "2"),
+		f.tmpdir.JoinPath("a", "3"),
+		f.tmpdir.JoinPath("a", "4"),
+		f.tmpdir.JoinPath("a", "5"),
+	}, fw.Status.FileEvents[1].SeenFiles)
+	throttled := apimeta.FindStatusCondition(fw.Status.Conditions, filewatches.FileWatchConditionThrottled)
+	require.NotNil(t, throttled)
+	assert.Equal(t, metav1.ConditionTrue, throttled.Status)
+	assert.Contains(t, throttled.Message, "merged 3 file events")
+
+	// the next second starts a new window
+	f.clock.Advance(time.Second)
+	f.ChangeAndWaitForSeenFile(key, "a", "6")
+	f.MustGet(key, fw)
+	require.Len(t, fw.Status.FileEvents, 3)
+	assert.True(t, apimeta.IsStatusConditionFalse(fw.Status.Conditions, filewatches.FileWatchConditionThrottled))
+}
+
+func TestController_SelfTestPasses(t *testing.T) {
+	f := newFixture(t)
+	spec := f.SimpleSpec()
+	spec.SelfTest = true
+	key, fw := f.CreateFileWatch(spec)
+
+	f.MustGet(key, fw)
+	selfTest := apimeta.FindStatusCondition(fw.Status.Conditions, filewatches.FileWatchConditionSelfTestPassed)
+	require.NotNil(t, selfTest)
+	assert.Equal(t, metav1.ConditionUnknown, selfTest.Status)
+
+	f.fakeMultiWatcher.RequireEmit(t, watch.NewFileEvent(f.selfTestProbe(key)))
+	require.Eventually(t, func() bool {
+		f.MustGet(key, fw)
+		return apimeta.IsStatusConditionTrue(fw.Status.Conditions, filewatches.FileWatchConditionSelfTestPassed)
+	}, timeout, interval, "self-test never passed")
+	assert.NotContains(t, f.Stdout(), "self-test failed")
+
+	// the probe isn't reported as a change to the watched paths
+	f.ChangeAndWaitForSeenFile(key, "a", "1")
+	f.MustGet(key, fw)
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "1")}, seenFiles(fw))
+}
+
+func TestController_SelfTestTimesOut(t *testing.T) {
+	f := newFixture(t)
+	spec := f.SimpleSpec()
+	spec.SelfTest = true
+	key, fw := f.CreateFileWatch(spec)
+
+	f.selfTestProbe(key)
+	f.clock.BlockUntil(2) // the self-test and the ignore summary
+	f.clock.Advance(selfTestTimeout)
+
+	var selfTest *metav1.Condition
+	require.Eventually(t, func() bool {
+		f.MustGet(key, fw)
+		selfTest = apimeta.FindStatusCondition(fw.Status.Conditions, filewatches.FileWatchConditionSelfTestPassed)
+		return selfTest != nil && selfTest.Status == metav1.ConditionFalse
+	}, timeout, interval, "self-test never failed")
+	assert.Equal(t, "NoEvents", selfTest.Reason)
+	assert.Contains(t, selfTest.Message, "Consider setting spec.watchMode to Poll")
+	assert.Contains(t, f.Stdout(), "self-test failed")
+
+	// the watch itself keeps running
+	f.ChangeAndWaitForSeenFile(key, "a", "1")
+}
+
+// selfTestProbe waits for the self-test for key to write its probe file, and returns its path.
+func (f *fixture) selfTestProbe(key types.NamespacedName) string {
+	f.t.Helper()
+	f.controller.mu.Lock()
+	w := f.controller.targetWatches[key]
+	f.controller.mu.Unlock()
+	require.NotNilf(f.t, w, "Watcher does not exist for %q", key.String())
+
+	var probe string
+	require.Eventually(f.t, func() bool {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		probe = w.selfTestProbe
+		return probe != ""
+	}, timeout, interval, "self-test never wrote its probe file")
+	return probe
+}
+
+func TestMergeFileEvent(t *testing.T) {
+	dst := filewatches.FileEvent{
+		Time:         metav1.NewMicroTime(time.Unix(1, 0)),
+		SeenFiles:    []string{"/a", "/b"},
+		DeletedFiles: []string{"/a", "/b"},
+	}
+	src := filewatches.FileEvent{
+		Time:         metav1.NewMicroTime(time.Unix(2, 0)),
+		SeenFiles:    []string{"/b", "/c"},
+		DeletedFiles: []string{"/c"},
+	}
+	mergeFileEvent(&dst, src)
+
+	assert.Equal(t, []string{"/a", "/b", "/c"}, dst.SeenFiles)
+	// /b was re-created after it was deleted
+	assert.Equal(t, []string{"/a", "/c"}, dst.DeletedFiles)
+	assert.Equal(t, src.Time, dst.Time)
+}
+
+func TestController_RestartKeepsHistory(t *testing.T) {
+	f := newFixture(t)
+	key, fw := f.CreateSimpleFileWatch()
+	f.ChangeAndWaitForSeenFile(key, "a", "1")
+	f.ChangeAndWaitForSeenFile(key, "a", "2")
+	f.MustGet(key, fw)
+	before := fw.Status.DeepCopy()
+
+	// Simulate a controller restart, which loses all in-memory state but not the object.
+	f.controller.mu.Lock()
+	w := f.controller.targetWatches[key]
+	w.cleanupWatch(context.Background())
+	f.controller.removeWatch(w)
+	f.controller.mu.Unlock()
+	f.reconcileFw(key)
+
+	f.MustGet(key, fw)
+	assert.Equal(t, before.FileEvents, fw.Status.FileEvents)
+	assert.Equal(t, before.LastEventTime, fw.Status.LastEventTime)
+	assert.True(t, fw.Status.MonitorStartTime.After(before.MonitorStartTime.Time),
+		"MonitorStartTime should be reset for the new monitor")
+
+	// history isn't duplicated by new events either
+	f.ChangeAndWaitForSeenFile(key, "a", "3")
+	f.MustGet(key, fw)
+	require.Len(t, fw.Status.FileEvents, 3)
+	assert.Equal(t, before.FileEvents, fw.Status.FileEvents[:2])
+}
+
+func TestProcessFileWatchStatus_IgnoresEventsFromPreviousMonitor(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	m := manifestbuilder.New(f, "fe").WithLocalResource("echo hi", []string{f.Path()}).Build()
+	state := store.NewState()
+	state.UpsertManifestTarget(store.NewManifestTarget(m))
+	meta := &metav1.ObjectMeta{
+		Name:        "fe",
+		Annotations: map[string]string{filewatches.AnnotationTargetID: m.LocalTarget().ID().String()},
+	}
+
+	eventTime := time.Unix(100, 0)
+	status := &filewatches.FileWatchStatus{
+		MonitorStartTime: metav1.NewMicroTime(eventTime.Add(time.Minute)),
+		FileEvents: []filewatches.FileEvent{{
+			Time:      metav1.NewMicroTime(eventTime),
+			SeenFiles: []string{f.JoinPath("main.go")},
+		}},
+	}
+	processFileWatchStatus(context.Background(), state, meta, status)
+	ms, _ := state.ManifestState("fe")
+	assert.False(t, ms.HasPendingFileChanges(), "events from a previous monitor should not be re-reported")
+
+	status.MonitorStartTime = metav1.NewMicroTime(eventTime.Add(-time.Minute))
+	processFileWatchStatus(context.Background(), state, meta, status)
+	assert.True(t, ms.HasPendingFileChanges())
+}
+
+// TestController_Reconcile_Delete peeks into internal/unexported portions of the controller to inspect the actual
+// filesystem monitor so it can ensure reconciler is not leaking resources; other tests should prefer observing
+// desired state!
+func TestController_Reconcile_Delete(t *testing.T) {
+	f := newFixture(t)
+	key, fw := f.CreateSimpleFileWatch()
+
+	assert.Equalf(t, 1, len(f.controller.targetWatches), "There should be exactly one file watcher")
+	watcher := f.controller.targetWatches[key]
+	require.NotNilf(t, watcher, "Watcher does not exist for %q", key.String())
+
+	deleted, _ := f.Delete(fw)
+	require.True(t, deleted, "FileWatch was not deleted")
+
+	watcher.mu.Lock()
+	defer watcher.mu.Unlock()
+	require.True(t, watcher.done, "Watcher was not stopped")
+	require.Empty(t, f.controller.targetWatches, "There should not be any remaining file watchers")
+}
+
+func TestController_Reconcile_DeleteDrainsEvents(t *testing.T) {
+	f := newFixture(t)
+	key, fw := f.CreateSimpleFileWatch()
+
+	fakeWatcher := f.controller.targetWatches[key].notify.(*fsevent.FakeWatcher)
+
+	// Hold the debounce timers, so that the events are still buffered when the object is deleted.
+	f.fakeTimerMaker.RestTimerLock.Lock()
+	f.fakeTimerMaker.MaxTimerLock.Lock()
+	defer f.fakeTimerMaker.MaxTimerLock.Unlock()
+	defer f.fakeTimerMaker.RestTimerLock.Unlock()
+
+	f.ChangeFile("a", "1")
+	f.ChangeFile("a", "2")
+	require.Eventually(t, func() bool {
+		return fakeWatcher.TotalEventCount() == 2 && fakeWatcher.QueuedCount() == 0
+	}, timeout, interval, "Events were never read")
+
+	deleted, _ := f.Delete(fw)
+	require.True(t, deleted, "FileWatch was not deleted")
+
+	var lastStatus *filewatches.FileWatchStatus
+	deleteIndex := -1
+	for i, a := range f.store.Actions() {
+		switch a := a.(type) {
+		case FileWatchUpdateStatusAction:
+			lastStatus = a.Status
+		case storefilewatches.FileWatchDeleteAction:
+			deleteIndex = i
+		}
+		if deleteIndex != -1 {
+			break
+		}
+	}
+	require.NotEqual(t, -1, deleteIndex, "FileWatch delete was never dispatched")
+	require.NotNil(t, lastStatus, "Drained events were never dispatched")
+	require.NotEmpty(t, lastStatus.FileEvents)
+	assert.ElementsMatch(t,
+		[]string{f.tmpdir.JoinPath("a", "1"), f.tmpdir.JoinPath("a", "2")},
+		lastStatus.FileEvents[len(lastStatus.FileEvents)-1].SeenFiles)
+	require.Empty(t, f.controller.targetWatches, "There should not be any remaining file watchers")
+}
+
+func TestController_Reconcile_RecordingSpecChangeKeepsMonitor(t *testing.T) {
+	f := newFixture(t)
+	key, fw := f.CreateSimpleFileWatch()
+
+	f.ChangeAndWaitForSeenFile(key, "a", "1")
+	f.ChangeAndWaitForSeenFile(key, "a", "2")
+
+	f.MustGet(key, fw)
+	originalStart := fw.Status.MonitorStartTime
+	require.NotZero(t, originalStart.Time, "Filesystem monitor was not started")
+
+	// none of these change what the monitor watches
+	fw.Spec.MaxEventHistory = pointer.Int32(1)
+	fw.Spec.RelativeTo = f.tmpdir.Path()
+	fw.Spec.StaleAfter = metav1.Duration{Duration: time.Hour}
+	f.Update(fw)
+
+	f.MustGet(key, fw)
+	assert.Equal(t, originalStart, fw.Status.MonitorStartTime)
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "2")}, seenFiles(fw))
+
+	// the new settings apply to the running monitor
+	f.ChangeAndWaitForSeenFile(key, "a", "3")
+	f.MustGet(key, fw)
+	assert.Equal(t, originalStart, fw.Status.MonitorStartTime)
+	require.Len(t, fw.Status.FileEvents, 1)
+	assert.Equal(t, []string{filepath.Join("a", "3")}, fw.Status.FileEvents[0].RelSeenFiles)
+
+	// changing what's watched still restarts it
+	fw.Spec.WatchedPaths = []string{f.tmpdir.JoinPath("d")}
+	f.Update(fw)
+	f.MustGet(key, fw)
+	assert.True(t, fw.Status.MonitorStartTime.After(originalStart.Time))
+}
+
+func TestController_StatusUpdateInterval(t *testing.T) {
+	f := newFixture(t)
+	spec := f.SimpleSpec()
+	spec.StatusUpdateInterval = metav1.Duration{Duration: time.Minute}
+	key, _ := f.CreateFileWatch(spec)
+
+	statusUpdates := func() int {
+		n := 0
+		for _, a := range f.store.Actions() {
+			if _, ok := a.(FileWatchUpdateStatusAction); ok {
+				n++
+			}
+		}
+		return n
+	}
+	initialUpdates := statusUpdates()
+
+	const changes = 15
+	var expected []string
+	for i := 0; i < changes; i++ {
+		f.ChangeFile("a", strconv.Itoa(i))
+		expected = append(expected, f.tmpdir.JoinPath("a", strconv.Itoa(i)))
+	}
+
+	// the events are recorded right away, but held back from the object
+	require.Eventually(t, func() bool {
+		f.controller.mu.Lock()
+		w := f.controller.targetWatches[key]
+		f.controller.mu.Unlock()
+		return len(seenFiles(&filewatches.FileWatch{Status: *w.copyStatus()})) == changes
+	}, timeout, interval, "file events were never recorded")
+
+	var fw filewatches.FileWatch
+	f.MustGet(key, &fw)
+	assert.Empty(t, fw.Status.FileEvents)
+	assert.Equal(t, initialUpdates, statusUpdates())
+
+	// once the interval has passed, they're all written together
+	f.clock.BlockUntil(2) // the status write and the ignore summary
+	f.clock.Advance(time.Minute)
+	require.Eventually(t, func() bool {
+		f.MustGet(key, &fw)
+		return len(seenFiles(&fw)) == changes
+	}, timeout, interval, "file events were never written")
+	assert.ElementsMatch(t, expected, seenFiles(&fw))
+	// the status is dispatched to the store after it's written to the object
+	require.Eventually(t, func() bool {
+		return statusUpdates() > initialUpdates
+	}, timeout, interval, "status update was never dispatched")
+	assert.Equal(t, initialUpdates+1, statusUpdates())
+}
+
+func TestController_RecordBaseline(t *testing.T) {
+	f := newFixture(t)
+	f.tmpdir.WriteFile(filepath.Join("a", "1"), "1")
+	f.tmpdir.WriteFile(filepath.Join("b", "c", "2"), "2")
+	f.tmpdir.WriteFile(filepath.Join("b", "unwatched"), "unwatched")
+	spec := f.SimpleSpec()
+	spec.RecordBaseline = true
+	key, fw := f.CreateFileWatch(spec)
+
+	expected := []string{f.tmpdir.JoinPath("a", "1"), f.tmpdir.JoinPath("b", "c", "2")}
+	require.Eventually(t, func() bool {
+		f.MustGet(key, fw)
+		return len(fw.Status.Baseline) != 0
+	}, timeout, interval, "baseline was never recorded")
+	assert.Equal(t, expected, fw.Status.Baseline)
+	assert.Empty(t, fw.Status.FileEvents)
+
+	// Later changes are reported as events, and don't touch the baseline.
+	f.ChangeAndWaitForSeenFile(key, "a", "3")
+	f.MustGet(key, fw)
+	assert.Equal(t, expected, fw.Status.Baseline)
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "3")}, seenFiles(fw))
+}
+
+func TestController_WatchRestartInterval(t *testing.T) {
+	f := newFixture(t)
+	spec := f.SimpleSpec()
+	spec.WatchRestartInterval = metav1.Duration{Duration: time.Hour}
+	key, fw := f.CreateFileWatch(spec)
+
+	f.ChangeAndWaitForSeenFile(key, "a", "1")
+	f.MustGet(key, fw)
+	originalStart := fw.Status.MonitorStartTime
+	require.NotZero(t, originalStart.Time, "Filesystem monitor was not started")
+
+	f.clock.BlockUntil(2) // the restart and the ignore summary
+	f.clock.Advance(time.Hour)
+	require.Eventually(t, func() bool {
+		f.MustGet(key, fw)
+		return fw.Status.MonitorStartTime.After(originalStart.Time)
+	}, timeout, interval, "Filesystem monitor was never restarted")
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "1")}, seenFiles(fw))
+	assert.Equal(t, int64(1), fw.Status.TotalEventCount)
+	assert.True(t, apimeta.IsStatusConditionTrue(fw.Status.Conditions, filewatches.FileWatchConditionReady))
+	assert.Empty(t, fw.Status.Error)
+
+	// the new monitor picks up where the old one left off
+	f.ChangeAndWaitForSeenFile(key, "a", "2")
+	f.MustGet(key, fw)
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "1"), f.tmpdir.JoinPath("a", "2")}, seenFiles(fw))
+}
+
+func TestController_RootDeletedAndRecreated(t *testing.T) {
+	f := newFixture(t)
+	f.tmpdir.MkdirAll("a")
+	f.tmpdir.MkdirAll(filepath.Join("b", "c"))
+	key, fw := f.CreateSimpleFileWatch()
+	f.ChangeAndWaitForSeenFile(key, "a", "1")
+	f.MustGet(key, fw)
+	assert.Nil(t, apimeta.FindStatusCondition(fw.Status.Conditions, filewatches.FileWatchConditionRootMissing))
+	originalStart := fw.Status.MonitorStartTime
+
+	// Deleting a watched directory takes its OS-level watches with it, so the monitor
+	// is restarted to watch for it to come back.
+	f.tmpdir.Rm("a")
+	f.ChangeFile("a")
+	require.Eventually(t, func() bool {
+		f.MustGet(key, fw)
+		return fw.Status.MonitorStartTime.After(originalStart.Time) &&
+			apimeta.IsStatusConditionTrue(fw.Status.Conditions, filewatches.FileWatchConditionRootMissing)
+	}, timeout, interval, "Filesystem monitor was never restarted")
+	missing := apimeta.FindStatusCondition(fw.Status.Conditions, filewatches.FileWatchConditionRootMissing)
+	assert.Equal(t, "NotFound", missing.Reason)
+	assert.Contains(t, missing.Message, f.tmpdir.JoinPath("a"))
+	assert.Empty(t, fw.Status.Error)
+
+	f.tmpdir.MkdirAll("a")
+	f.ChangeAndWaitForSeenFile(key, "a", "2")
+	f.MustGet(key, fw)
+	assert.True(t, apimeta.IsStatusConditionFalse(fw.Status.Conditions, filewatches.FileWatchConditionRootMissing))
+}
+
+func TestController_Reconcile_Watches(t *testing.T) {
+	f := newFixture(t)
+	key, fw := f.CreateSimpleFileWatch()
+
+	f.ChangeAndWaitForSeenFile(key, "a", "1")
+
+	f.MustGet(key, fw)
+	originalStart := fw.Status.MonitorStartTime.Time
+	assert.NotZero(t, originalStart, "Filesystem monitor was not started")
+
+	fw.Spec.Ignores = []filewatches.IgnoreDef{
+		{
+			BasePath: f.tmpdir.Path(),
+			Patterns: []string{"**/ignore_me"},
+		},
+		{
+			// no patterns means ignore the path recursively
+			BasePath: f.tmpdir.JoinPath("d", "ignore_dir"),
+		},
+	}
+	fw.Spec.WatchedPaths = []string{f.tmpdir.JoinPath("d")}
+	f.Update(fw)
+
+	// sandwich in some ignored files with seen files on the outside as synchronization
+	f.ChangeAndWaitForSeenFile(key, "d", "1")
+	f.ChangeFile("a", "2")
+	f.ChangeFile("d", "ignore_me")
+	f.ChangeFile("d", "ignore_dir", "file")
+	f.ChangeAndWaitForSeenFile(key, "d", "2")
+
+	var updated filewatches.FileWatch
+	f.MustGet(key, &updated)
+	updatedStart := updated.Status.MonitorStartTime.Time
+	assert.Truef(t, updatedStart.After(originalStart),
+		"Monitor start time should be more recent after update, (original: %s, updated: %s)",
+		originalStart, updatedStart)
+	if assert.Equal(t, 3, len(updated.Status.FileEvents)) {
+		// ensure ONLY the expected files were seen
+		assert.NotZero(t, updated.Status.FileEvents[0].Time.Time)
+		mostRecentEventTime := updated.Status.FileEvents[2].Time.Time
+		assert.NotZero(t, mostRecentEventTime)
+		assert.Equal(t, []string{f.tmpdir.JoinPath("a", "1")}, updated.Status.FileEvents[0].SeenFiles)
+		assert.Equal(t, []string{f.tmpdir.JoinPath("d", "1")}, updated.Status.FileEvents[1].SeenFiles)
+		assert.Equal(t, []string{f.tmpdir.JoinPath("d", "2")}, updated.Status.FileEvents[2].SeenFiles)
+		assert.Equal(t, mostRecentEventTime, updated.Status.LastEventTime.Time)
+	}
+}
+
+func TestController_Disable_By_Configmap(t *testing.T) {
+	f := newFixture(t)
+	key, _ := f.CreateSimpleFileWatch()
+
+	// when enabling the configmap, the filewatch object is enabled
+	f.setDisabled(key, false)
+
+	// when disabling the configmap, the filewatch object is disabled
+	f.setDisabled(key, true)
+
+	// when enabling the configmap, the filewatch object is enabled
+	f.setDisabled(key, false)
+}
+
+func TestController_Disable_LogsTransitions(t *testing.T) {
+	f := newFixture(t)
+	require.NoError(t, tiltconfigmap.UpsertDisableConfigMap(f.Context(), f.Client, "disable-test-file-watch", "isDisabled", false))
+	key, _ := f.CreateSimpleFileWatch()
+
+	f.setDisabled(key, true)
+	f.reconcileFw(key)
+	f.setDisabled(key, false)
+	f.reconcileFw(key)
+
+	assert.Equal(t, 1, strings.Count(f.Stdout(),
+		`filewatch test-file-watch: disabled, file changes will be ignored (ConfigMap/key "disable-test-file-watch"/"isDisabled" is true)`))
+	assert.Equal(t, 1, strings.Count(f.Stdout(),
+		`filewatch test-file-watch: enabled (ConfigMap/key "disable-test-file-watch"/"isDisabled" is false)`))
+}
+
+func TestController_Disable_ConfigMapReadErrors(t *testing.T) {
+	f := newFixture(t)
+	key, fw := f.CreateSimpleFileWatch()
+	client := &flakyConfigMapClient{Client: f.controller.Client}
+	f.controller.Client = client
+
+	require.NoError(t, tiltconfigmap.UpsertDisableConfigMap(f.Context(), f.Client, "disable-test-file-watch", "isDisabled", true))
+
+	// a read that keeps failing leaves the watch as it was, and records the error
+	client.setFailures(100)
+	_, err := f.controller.Reconcile(f.Context(), ctrl.Request{NamespacedName: key})
+	require.Error(t, err)
+	f.MustGet(key, fw)
+	assert.False(t, fw.Status.DisableStatus.Disabled, "disable state should not change on a read error")
+	assert.Contains(t, fw.Status.Error, "resolving disable status")
+	f.ChangeAndWaitForSeenFile(key, "a", "1")
+
+	// a transient error is retried, and the watch is disabled once the read succeeds
+	client.setFailures(1)
+	f.reconcileFw(key)
+	f.MustGet(key, fw)
+	assert.True(t, fw.Status.DisableStatus.Disabled)
+	assert.Empty(t, fw.Status.Error)
+}
+
+// flakyConfigMapClient fails ConfigMap reads with a transient error.
+type flakyConfigMapClient struct {
+	ctrlclient.Client
+	mu       sync.Mutex
+	failures int
+}
+
+func (c *flakyConfigMapClient) setFailures(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failures = n
+}
+
+func (c *flakyConfigMapClient) Get(ctx context.Context, key ctrlclient.ObjectKey, obj ctrlclient.Object, opts ...ctrlclient.GetOption) error {
+	if _, ok := obj.(*filewatches.ConfigMap); ok {
+		c.mu.Lock()
+		fail := c.failures > 0
+		if fail {
+			c.failures--
+		}
+		c.mu.Unlock()
+		if fail {
+			return apierrors.NewServiceUnavailable("apiserver is restarting")
+		}
+	}
+	return c.Client.Get(ctx, key, obj, opts...)
+}
+
+func TestController_Disable_By_Multiple_Sources(t *testing.T) {
+	for _, tc := range []struct {
+		policy        filewatches.DisableSourcePolicy
+		disabledByOne bool
+	}{
+		{filewatches.DisableSourcePolicyAnyDisabled, true},
+		{filewatches.DisableSourcePolicyAllDisabled, false},
+	} {
+		t.Run(string(tc.policy), func(t *testing.T) {
+			f := newFixture(t)
+			spec := f.SimpleSpec()
+			spec.DisableSources = []filewatches.DisableSource{{
+				ConfigMap: &filewatches.ConfigMapDisableSource{Name: "disable-test-file-watch-2", Key: "isDisabled"},
+			}}
+			spec.DisableSourcePolicy = tc.policy
+
+			// both enabled
+			require.NoError(t, tiltconfigmap.UpsertDisableConfigMap(f.Context(), f.Client, "disable-test-file-watch-2", "isDisabled", false))
+			key, fw := f.CreateFileWatch(spec)
+			f.MustGet(key, fw)
+			assert.False(t, fw.Status.DisableStatus.Disabled)
+
+			// one disabled
+			require.NoError(t, tiltconfigmap.UpsertDisableConfigMap(f.Context(), f.Client, "disable-test-file-watch-2", "isDisabled", true))
+			f.reconcileFw(key)
+			f.MustGet(key, fw)
+			assert.Equal(t, tc.disabledByOne, fw.Status.DisableStatus.Disabled)
+
+			// both disabled
+			f.setDisabled(key, true)
+			f.MustGet(key, fw)
+			assert.True(t, fw.Status.DisableStatus.Disabled)
+		})
+	}
+}
+
+func TestController_Disable_By_File(t *testing.T) {
+	f := newFixture(t)
+	sentinel := f.tmpdir.JoinPath("disabled")
+	spec := f.SimpleSpec()
+	spec.DisableSource = &filewatches.DisableSource{
+		File: &filewatches.FileDisableSource{Path: sentinel},
+	}
+	key, fw := f.CreateFileWatch(spec)
+
+	result := f.reconcileFw(key)
+	assert.Equal(t, configmap.FileDisablePollInterval, result.RequeueAfter)
+	f.MustGet(key, fw)
+	require.NotNil(t, fw.Status.DisableStatus)
+	assert.False(t, fw.Status.DisableStatus.Disabled)
+	f.ChangeAndWaitForSeenFile(key, "a", "1")
+
+	// when the sentinel file is created, the filewatch object is disabled
+	f.tmpdir.WriteFile("disabled", "")
+	f.reconcileFw(key)
+	f.MustGet(key, fw)
+	assert.True(t, fw.Status.DisableStatus.Disabled)
+	assert.Contains(t, fw.Status.DisableStatus.Reason, "exists")
+
+	// when the sentinel file is removed, the filewatch object is enabled
+	f.tmpdir.Rm("disabled")
+	f.reconcileFw(key)
+	f.MustGet(key, fw)
+	assert.False(t, fw.Status.DisableStatus.Disabled)
+	f.ChangeAndWaitForSeenFile(key, "a", "2")
+}
+
+func TestController_Disable_By_File_Invert(t *testing.T) {
+	f := newFixture(t)
+	spec := f.SimpleSpec()
+	spec.DisableSource = &filewatches.DisableSource{
+		File: &filewatches.FileDisableSource{Path: f.tmpdir.JoinPath("enabled"), Invert: true},
+	}
+	key, fw := f.CreateFileWatch(spec)
+
+	f.reconcileFw(key)
+	f.MustGet(key, fw)
+	require.NotNil(t, fw.Status.DisableStatus)
+	assert.True(t, fw.Status.DisableStatus.Disabled)
+
+	f.tmpdir.WriteFile("enabled", "")
+	f.reconcileFw(key)
+	f.MustGet(key, fw)
+	assert.False(t, fw.Status.DisableStatus.Disabled)
+	f.ChangeAndWaitForSeenFile(key, "a", "1")
+}
+
+func TestController_Disable_By_EnvVar(t *testing.T) {
+	const name = "TILT_TEST_FILEWATCH_DISABLED"
+	f := newFixture(t)
+	spec := f.SimpleSpec()
+	spec.DisableSource = &filewatches.DisableSource{
+		EnvVar: &filewatches.EnvVarDisableSource{Name: name},
+	}
+	key, fw := f.CreateFileWatch(spec)
+	f.MustGet(key, fw)
+	require.NotNil(t, fw.Status.DisableStatus)
+	assert.False(t, fw.Status.DisableStatus.Disabled)
+	f.ChangeAndWaitForSeenFile(key, "a", "1")
+
+	t.Setenv(name, "true")
+	f.reconcileFw(key)
+	f.MustGet(key, fw)
+	assert.True(t, fw.Status.DisableStatus.Disabled)
+	assert.Contains(t, fw.Status.DisableStatus.Reason, name)
+
+	require.NoError(t, os.Unsetenv(name))
+	f.reconcileFw(key)
+	f.MustGet(key, fw)
+	assert.False(t, fw.Status.DisableStatus.Disabled)
+	f.ChangeAndWaitForSeenFile(key, "a", "2")
+}
+
+func TestController_Disable_By_Schedule(t *testing.T) {
+	f := newFixture(t)
+
+	// start just before the window opens
+	now := f.clock.Now().In(time.UTC)
+	start := time.Date(now.Year(), now.Month(), now.Day()+1, 8, 59, 0, 0, time.UTC)
+	f.clock.Advance(start.Sub(now))
+
+	spec := f.SimpleSpec()
+	spec.DisableSource = &filewatches.DisableSource{
+		Schedule: &filewatches.ScheduleDisableSource{ActiveStart: "09:00", ActiveEnd: "17:00", TimeZone: "UTC"},
+	}
+	key, fw := f.CreateFileWatch(spec)
+	f.MustGet(key, fw)
+	require.NotNil(t, fw.Status.DisableStatus)
+	assert.True(t, fw.Status.DisableStatus.Disabled)
+	assert.Contains(t, fw.Status.DisableStatus.Reason, "Outside active window")
+
+	// the window opens
+	result := f.reconcileFw(key)
+	assert.Equal(t, time.Minute, result.RequeueAfter)
+	f.clock.Advance(result.RequeueAfter)
+	result = f.reconcileFw(key)
+	assert.Equal(t, 8*time.Hour, result.RequeueAfter)
+	f.MustGet(key, fw)
+	assert.False(t, fw.Status.DisableStatus.Disabled)
+	f.ChangeAndWaitForSeenFile(key, "a", "1")
+
+	// the window closes, so file changes are ignored
+	f.clock.Advance(result.RequeueAfter)
+	f.reconcileFw(key)
+	f.MustGet(key, fw)
+	assert.True(t, fw.Status.DisableStatus.Disabled)
+
+	f.ChangeFile("a", "2")
+	f.MustGet(key, fw)
+	require.Equal(t, 0, len(fw.Status.FileEvents))
+}
+
+func TestController_Disable_Ignores_File_Changes(t *testing.T) {
+	f := newFixture(t)
+	key, _ := f.CreateSimpleFileWatch()
+
+	// Disable the filewatch
+	f.setDisabled(key, true)
+	// Change a watched file
+	f.ChangeFile("a", "1")
+
+	// Expect that no file events were triggered
+	f.AssertNoSeenFilesFor(key, 100*time.Millisecond)
+}
+
+func TestFixture_AssertNoSeenFilesFor(t *testing.T) {
+	f := newFixture(t)
+	key, _ := f.CreateSimpleFileWatch()
+
+	f.setDisabled(key, true)
+	f.ChangeFile("a", "1")
+	f.AssertNoSeenFilesFor(key, 100*time.Millisecond)
+
+	f.setDisabled(key, false)
+	f.ChangeFile("a", "2")
+	rt := &recordingT{}
+	assert.False(t, f.assertNoSeenFilesFor(rt, key, timeout), "a file change slipped through unnoticed")
+	assert.Contains(t, rt.errors, f.tmpdir.JoinPath("a", "2"))
+}
+
+// recordingT records assertion failures instead of failing the test.
+type recordingT struct {
+	errors string
+}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors += fmt.Sprintf(format, args...)
+}
+
+func TestCreateSubError(t *testing.T) {
+	f := newFixture(t)
+	f.controller.fsWatcherMaker = fsevent.WatcherMaker(func(paths []string, ignore watch.PathMatcher, _ logger.Logger) (watch.Notify, error) {
+		var nilWatcher *fsevent.FakeWatcher = nil
+		return nilWatcher, fmt.Errorf("Unusual watcher error")
+	})
+	key, _ := f.CreateSimpleFileWatch()
+
+	// Expect that no file events were triggered
+	var fw filewatches.FileWatch
+	f.MustGet(key, &fw)
+	assert.Contains(t, fw.Status.SetupError, "filewatch init: Unusual watcher error")
+	assert.False(t, fw.Status.SetupErrorTime.IsZero())
+	assert.Empty(t, fw.Status.Error)
+	ready := apimeta.FindStatusCondition(fw.Status.Conditions, filewatches.FileWatchConditionReady)
+	require.NotNil(t, ready)
+	assert.Equal(t, metav1.ConditionFalse, ready.Status)
+	assert.Equal(t, "SetupFailed", ready.Reason)
+}
+
+func TestStartSubError(t *testing.T) {
+	f := newFixture(t)
+	maker := f.controller.fsWatcherMaker
+	var ffw *fsevent.FakeWatcher
+	f.controller.fsWatcherMaker = fsevent.WatcherMaker(func(paths []string, ignore watch.PathMatcher, l logger.Logger) (watch.Notify, error) {
+		w, err := maker(paths, ignore, l)
+		ffw = w.(*fsevent.FakeWatcher)
+		ffw.StartErr = fmt.Errorf("Unusual start error")
+		return w, err
+	})
+	key, _ := f.CreateSimpleFileWatch()
+
+	var fw filewatches.FileWatch
+	f.MustGet(key, &fw)
+	assert.Contains(t, fw.Status.SetupError, "filewatch init: Unusual start error")
+	assert.Empty(t, fw.Status.Error)
+	assert.False(t, ffw.Running)
+
+	fw.Spec.WatchedPaths = []string{f.tmpdir.JoinPath("d")}
+	f.Update(&fw)
+
+	f.MustGet(key, &fw)
+	assert.Contains(t, fw.Status.SetupError, "filewatch init: Unusual start error")
+	assert.Empty(t, fw.Status.Error)
+	assert.False(t, ffw.Running)
+}
+
+func TestController_SetupRetryBackoff(t *testing.T) {
+	f := newFixture(t)
+	var attempts atomic.Int32
+	f.controller.fsWatcherMaker = fsevent.WatcherMaker(func(paths []string, ignore watch.PathMatcher, _ logger.Logger) (watch.Notify, error) {
+		attempts.Add(1)
+		return nil, fmt.Errorf("Unusual watcher error")
+	})
+	key, _ := f.CreateSimpleFileWatch()
+	require.Equal(t, int32(1), attempts.Load())
+
+	// Each retry waits twice as long as the last, until the circuit opens.
+	for i, backoff := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second} {
+		f.clock.BlockUntil(1)
+		f.clock.Advance(backoff - time.Millisecond)
+		assert.Equal(t, int32(i+1), attempts.Load(), "retried too early")
+		f.clock.Advance(time.Millisecond)
+		require.Eventually(t, func() bool {
+			return attempts.Load() == int32(i+2)
+		}, timeout, interval, "setup was never retried")
+	}
+
+	var fw filewatches.FileWatch
+	require.Eventually(t, func() bool {
+		f.MustGet(key, &fw)
+		ready := apimeta.FindStatusCondition(fw.Status.Conditions, filewatches.FileWatchConditionReady)
+		return ready != nil && ready.Reason == "SetupCircuitOpen"
+	}, timeout, interval, "circuit never opened")
+	assert.Contains(t, apimeta.FindStatusCondition(fw.Status.Conditions, filewatches.FileWatchConditionReady).Message,
+		"failed to start 5 times in a row")
+
+	f.clock.BlockUntil(1)
+	f.clock.Advance(setupCircuitOpenInterval - time.Millisecond)
+	assert.Equal(t, int32(5), attempts.Load(), "retried too early once the circuit opened")
+	f.clock.Advance(time.Millisecond)
+	require.Eventually(t, func() bool {
+		return attempts.Load() == 6
+	}, timeout, interval, "setup was never retried once the circuit opened")
+
+	// The same error is only logged once.
+	assert.Equal(t, 1, strings.Count(f.Stdout(), "Unusual watcher error"))
+
+	// A spec change resets the backoff. The retry may still be writing the status, so
+	// keep trying until the update doesn't conflict with it.
+	require.Eventually(t, func() bool {
+		f.MustGet(key, &fw)
+		fw.Spec.WatchedPaths = []string{f.tmpdir.JoinPath("d")}
+		return f.Client.Update(f.Context(), &fw) == nil
+	}, timeout, interval)
+	f.reconcileFw(key)
+	require.Equal(t, int32(7), attempts.Load())
+	f.MustGet(key, &fw)
+	assert.Equal(t, "SetupFailed", apimeta.FindStatusCondition(fw.Status.Conditions, filewatches.FileWatchConditionReady).Reason)
+	f.clock.BlockUntil(1)
+	f.clock.Advance(time.Second)
+	require.Eventually(t, func() bool {
+		return attempts.Load() == 8
+	}, timeout, interval, "setup was never retried after the spec changed")
+}
+
+func TestController_SetupErrorForbiddenDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no unix permissions on windows")
+	}
+	if os.Getuid() == 0 {
+		t.Skip("root can read any directory")
+	}
+	f := newFixture(t)
+	f.controller.fsWatcherMaker = fsevent.WatcherMaker(watch.NewWatcher)
+	f.tmpdir.MkdirAll(filepath.Join("a", "forbidden"))
+	require.NoError(t, os.Chmod(f.tmpdir.JoinPath("a", "forbidden"), 0))
+	t.Cleanup(func() { _ = os.Chmod(f.tmpdir.JoinPath("a", "forbidden"), 0700) })
+
+	key, fw := f.CreateSimpleFileWatch()
+	f.MustGet(key, fw)
+	assert.Contains(t, fw.Status.SetupError, "filewatch init:")
+	assert.Contains(t, fw.Status.SetupError, "permission denied")
+	assert.False(t, fw.Status.SetupErrorTime.IsZero())
+	assert.Empty(t, fw.Status.Error)
+	assert.True(t, fw.Status.ErrorTime.IsZero())
+}
+
+func TestController_SkipUnreadable(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no unix permissions on windows")
+	}
+	if os.Getuid() == 0 {
+		t.Skip("root can read any directory")
+	}
+	f := newFixture(t)
+	f.controller.fsWatcherMaker = fsevent.WatcherMaker(watch.NewWatcher)
+	f.tmpdir.MkdirAll(filepath.Join("a", "forbidden"))
+	f.tmpdir.MkdirAll(filepath.Join("a", "readable"))
+	require.NoError(t, os.Chmod(f.tmpdir.JoinPath("a", "forbidden"), 0))
+	t.Cleanup(func() { _ = os.Chmod(f.tmpdir.JoinPath("a", "forbidden"), 0700) })
+
+	spec := f.SimpleSpec()
+	spec.SkipUnreadable = true
+	key, fw := f.CreateFileWatch(spec)
+	f.MustGet(key, fw)
+	require.Empty(t, fw.Status.SetupError)
+	assert.NotZero(t, fw.Status.MonitorStartTime, "Filesystem monitor was not started")
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "forbidden")}, fw.Status.SkippedPaths)
+	assert.Contains(t, f.Stdout(), fmt.Sprintf("skipping %s, which can't be read", f.tmpdir.JoinPath("a", "forbidden")))
+
+	f.tmpdir.WriteFile(filepath.Join("a", "readable", "1"), "hello")
+	f.WaitForSeenFile(key, "a", "readable", "1")
+	f.tmpdir.WriteFile(filepath.Join("b", "c", "2"), "hello")
+	f.WaitForSeenFile(key, "b", "c", "2")
+}
+
+func TestController_WatchedPathCreatedLater(t *testing.T) {
+	f := newFixture(t)
+	f.controller.fsWatcherMaker = fsevent.WatcherMaker(watch.NewWatcher)
+
+	spec := f.SimpleSpec()
+	spec.WatchedPaths = []string{f.tmpdir.JoinPath("build", "out")}
+	key, fw := f.CreateFileWatch(spec)
+	f.MustGet(key, fw)
+	require.Empty(t, fw.Status.SetupError)
+
+	f.tmpdir.WriteFile(filepath.Join("build", "out", "main.js"), "hello")
+	f.WaitForSeenFile(key, "build", "out", "main.js")
+}
+
+func TestController_MaxDepth(t *testing.T) {
+	f := newFixture(t)
+	f.controller.fsWatcherMaker = fsevent.WatcherMaker(watch.NewWatcher)
+	f.tmpdir.MkdirAll(filepath.Join("a", "vendor", "github.com", "dep"))
+
+	spec := f.SimpleSpec()
+	spec.MaxDepth = 2
+	spec.DebugIgnores = true
+	key, fw := f.CreateFileWatch(spec)
+	f.MustGet(key, fw)
+	require.Empty(t, fw.Status.SetupError)
+
+	f.tmpdir.WriteFile(filepath.Join("a", "vendor", "github.com", "dep", "dep.go"), "package dep")
+	f.tmpdir.WriteFile(filepath.Join("a", "vendor", "modules.txt"), "hello")
+	f.WaitForSeenFile(key, "a", "vendor", "modules.txt")
+
+	f.MustGet(key, fw)
+	for _, e := range fw.Status.FileEvents {
+		assert.NotContains(t, e.SeenFiles, f.tmpdir.JoinPath("a", "vendor", "github.com", "dep", "dep.go"))
+	}
+}
+
+func TestController_WatchCount(t *testing.T) {
+	f := newFixture(t)
+	f.controller.fsWatcherMaker = fsevent.WatcherMaker(watch.NewWatcher)
+	f.tmpdir.MkdirAll(filepath.Join("a", "x", "y"))
+	f.tmpdir.MkdirAll(filepath.Join("a", "z"))
+
+	key, fw := f.CreateFileWatch(f.SimpleSpec())
+	f.MustGet(key, fw)
+	require.Empty(t, fw.Status.SetupError)
+	assert.Greater(t, fw.Status.WatchCount, int32(0))
+}
+
+func TestController_MaxWatches(t *testing.T) {
+	f := newFixture(t)
+	var madeForD atomic.Int32
+	f.controller.fsWatcherMaker = func(paths []string, ignore watch.PathMatcher, l logger.Logger) (watch.Notify, error) {
+		if slices.Contains(paths, f.tmpdir.JoinPath("d")) {
+			madeForD.Add(1)
+		}
+		return watch.NewWatcher(paths, ignore, l)
+	}
+	f.tmpdir.MkdirAll(filepath.Join("d", "x"))
+	f.tmpdir.MkdirAll(filepath.Join("d", "y"))
+
+	key, fw := f.CreateSimpleFileWatch()
+	f.MustGet(key, fw)
+	require.Empty(t, fw.Status.SetupError)
+	if fw.Status.WatchCount == 0 {
+		t.Skip("monitor doesn't count its watches")
+	}
+
+	// Leave room for one more watch, which isn't enough for d and its two subdirectories.
+	f.controller.mu.Lock()
+	f.controller.maxWatches = int(fw.Status.WatchCount) + 1
+	f.controller.mu.Unlock()
+
+	other := &filewatches.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: "other"},
+		Spec:       filewatches.FileWatchSpec{WatchedPaths: []string{f.tmpdir.JoinPath("d")}},
+	}
+	f.Create(other)
+	otherKey := f.KeyForObject(other)
+	f.MustGet(otherKey, other)
+	assert.Contains(t, other.Status.SetupError,
+		fmt.Sprintf("watch budget exceeded: needs 3 OS watches, but only 1 of %d are free", f.controller.maxWatches))
+	assert.Zero(t, other.Status.MonitorStartTime)
+	assert.Zero(t, madeForD.Load(), "monitor was created even though it was over budget")
+	ready := apimeta.FindStatusCondition(other.Status.Conditions, filewatches.FileWatchConditionReady)
+	require.NotNil(t, ready)
+	assert.Equal(t, "OverBudget", ready.Reason)
+
+	// The original watch can still be restarted within its share of the budget.
+	startTime := fw.Status.MonitorStartTime
+	fw.Spec.Ignores = []filewatches.IgnoreDef{{BasePath: f.tmpdir.Path(), Patterns: []string{"*.log"}}}
+	f.Update(fw)
+	f.MustGet(key, fw)
+	assert.Empty(t, fw.Status.SetupError)
+	assert.NotEqual(t, startTime, fw.Status.MonitorStartTime, "monitor was not restarted")
+
+	// Once the budget is freed up, the other watch is started without waiting for its backoff.
+	f.Delete(fw)
+	require.Eventually(t, func() bool {
+		f.MustGet(otherKey, other)
+		return !other.Status.MonitorStartTime.IsZero()
+	}, timeout, interval, "watch was never started once the budget was freed")
+	assert.Empty(t, other.Status.SetupError)
+	assert.Equal(t, int32(3), other.Status.WatchCount)
+
+	f.tmpdir.WriteFile(filepath.Join("d", "x", "1"), "hello")
+	f.WaitForSeenFile(otherKey, "d", "x", "1")
+}
+
+func TestProvideMaxWatches(t *testing.T) {
+	for _, tc := range []struct {
+		value    string
+		expected MaxWatches
+	}{
+		{"", 0},
+		{"8192", 8192},
+		{"-1", 0},
+		{"lots", 0},
+	} {
+		t.Run(tc.value, func(t *testing.T) {
+			t.Setenv(MaxWatchesEnvVar, tc.value)
+			assert.Equal(t, tc.expected, ProvideMaxWatches())
+		})
+	}
+}
+
+func TestController_DetectOverlaps(t *testing.T) {
+	f := newFixture(t)
+	f.controller.detectOverlaps = true
+	key, _ := f.CreateSimpleFileWatch()
+
+	create := func(name string, paths ...string) {
+		fw := &filewatches.FileWatch{
+			ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: name},
+			Spec:       filewatches.FileWatchSpec{WatchedPaths: paths},
+		}
+		f.Create(fw)
+		f.reconcileFw(f.KeyForObject(fw))
+	}
+
+	create("separate", f.tmpdir.JoinPath("d"))
+	assert.NotContains(t, f.Stdout(), "overlaps")
+
+	create("nested", f.tmpdir.JoinPath("a", "x"))
+	// Watched paths are reported with symlinks resolved, so only check the names.
+	out := f.Stdout()
+	assert.Contains(t, out, "filewatch nested: watched path ")
+	assert.Contains(t, out, fmt.Sprintf("watched by filewatch %s.", key.Name))
+	assert.Equal(t, 1, strings.Count(out, "overlaps"))
+}
+
+func TestController_ActiveWatches(t *testing.T) {
+	f := newFixture(t)
+	assert.Empty(t, f.controller.ActiveWatches())
+
+	// Create the watch that never sees an event first, so that the fake monitor checks
+	// whether it's running before handing events to the other one.
+	other := &filewatches.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{Namespace: apis.SanitizeName(t.Name()), Name: "other"},
+		Spec:       filewatches.FileWatchSpec{WatchedPaths: []string{f.tmpdir.JoinPath("d")}},
+	}
+	f.Create(other)
+	otherKey := f.KeyForObject(other)
+	spec := f.SimpleSpec()
+	spec.DisableMode = filewatches.FileWatchDisableModePause
+	key, fw := f.CreateFileWatch(spec)
+	f.ChangeAndWaitForSeenFile(key, "a", "1")
+
+	var current filewatches.FileWatch
+	f.MustGet(key, &current)
+	snapshot := f.controller.ActiveWatches()
+	require.Len(t, snapshot, 2)
+	assert.Equal(t, otherKey, snapshot[0].Name)
+	assert.Equal(t, []string{f.tmpdir.JoinPath("d")}, snapshot[0].Roots)
+	assert.Zero(t, snapshot[0].LastEventTime)
+	assert.Equal(t, key, snapshot[1].Name)
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a"), f.tmpdir.JoinPath("b", "c")}, snapshot[1].Roots)
+	assert.Equal(t, current.Status.WatchCount, snapshot[1].WatchCount)
+	assert.Equal(t, current.Status.LastEventTime, snapshot[1].LastEventTime)
+	assert.False(t, snapshot[1].Paused)
+
+	f.setDisabled(key, true)
+	snapshot = f.controller.ActiveWatches()
+	require.Len(t, snapshot, 2)
+	assert.True(t, snapshot[1].Paused)
+
+	deleted, _ := f.Delete(fw)
+	require.True(t, deleted, "FileWatch was not deleted")
+	snapshot = f.controller.ActiveWatches()
+	require.Len(t, snapshot, 1)
+	assert.Equal(t, otherKey, snapshot[0].Name)
+}
+
+func TestController_SharedMonitor(t *testing.T) {
+	f := newFixture(t)
+	made := 0
+	f.controller.fsWatcherMaker = fsevent.NewSharedWatcherMaker(func(paths []string, ignore watch.PathMatcher, l logger.Logger) (watch.Notify, error) {
+		made++
+		return f.fakeMultiWatcher.NewSub(paths, ignore, l)
+	})
+
+	create := func(name, ignored string) types.NamespacedName {
+		fw := &filewatches.FileWatch{
+			ObjectMeta: metav1.ObjectMeta{Namespace: apis.SanitizeName(t.Name()), Name: name},
+			Spec: filewatches.FileWatchSpec{
+				WatchedPaths: []string{f.tmpdir.JoinPath("a")},
+				Ignores:      []filewatches.IgnoreDef{{BasePath: f.tmpdir.Path(), Patterns: []string{ignored}}},
+			},
+		}
+		f.Create(fw)
+		key := f.KeyForObject(fw)
+		f.reconcileFw(key)
+		return key
+	}
+	goKey := create("go", "**/*.txt")
+	txtKey := create("txt", "**/*.go")
+	assert.Equal(t, 1, made, "watches on the same root should share a monitor")
+
+	f.ChangeFile("a", "main.go")
+	f.ChangeFile("a", "notes.txt")
+	f.WaitForSeenFile(goKey, "a", "main.go")
+	f.WaitForSeenFile(txtKey, "a", "notes.txt")
+
+	var goFw, txtFw filewatches.FileWatch
+	f.MustGet(goKey, &goFw)
+	f.MustGet(txtKey, &txtFw)
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "main.go")}, seenFiles(&goFw))
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "notes.txt")}, seenFiles(&txtFw))
+}
+
+func TestController_EstimatedWatchBytes(t *testing.T) {
+	if runtime.GOOS == "darwin" {
+		t.Skip("FSEvents watches a tree with a single stream, however many directories it has")
+	}
+	estimate := func(dirs int) int64 {
+		f := newFixture(t)
+		f.controller.fsWatcherMaker = fsevent.WatcherMaker(watch.NewWatcher)
+		for i := 0; i < dirs; i++ {
+			f.tmpdir.MkdirAll(filepath.Join("a", fmt.Sprintf("dir%d", i)))
+		}
+
+		key, fw := f.CreateFileWatch(f.SimpleSpec())
+		f.MustGet(key, fw)
+		require.Empty(t, fw.Status.SetupError)
+		assert.GreaterOrEqual(t, fw.Status.EstimatedWatchBytes, int64(fw.Status.WatchCount)*watchDescriptorBytes)
+		return fw.Status.EstimatedWatchBytes
+	}
+
+	small := estimate(1)
+	large := estimate(10)
+	assert.Greater(t, small, int64(0))
+	assert.GreaterOrEqual(t, large, small+9*watchDescriptorBytes,
+		"estimate should grow with each watched directory")
+}
+
+func TestController_WatchSingleFile(t *testing.T) {
+	f := newFixture(t)
+	f.controller.fsWatcherMaker = fsevent.WatcherMaker(watch.NewWatcher)
+	f.tmpdir.WriteFile(filepath.Join("config", "app.yaml"), "a: 1")
+	f.tmpdir.WriteFile(filepath.Join("config", "other.yaml"), "b: 1")
+
+	spec := f.SimpleSpec()
+	spec.WatchedPaths = []string{f.tmpdir.JoinPath("config", "app.yaml")}
+	key, fw := f.CreateFileWatch(spec)
+	f.MustGet(key, fw)
+	require.Empty(t, fw.Status.SetupError)
+
+	f.tmpdir.WriteFile(filepath.Join("config", "other.yaml"), "b: 2")
+	f.tmpdir.WriteFile(filepath.Join("config", "app.yaml"), "a: 2")
+	f.WaitForSeenFile(key, "config", "app.yaml")
+
+	f.MustGet(key, fw)
+	for _, e := range fw.Status.FileEvents {
+		assert.Equal(t, []string{f.tmpdir.JoinPath("config", "app.yaml")}, e.SeenFiles)
+	}
+}
+
+func TestController_RuntimeErrorIsNotSetupError(t *testing.T) {
+	f := newFixture(t)
+	key, _ := f.CreateSimpleFileWatch()
+
+	f.failWatcher(key, fmt.Errorf("fatal read error"))
+	f.reconcileFw(key)
+
+	var fw filewatches.FileWatch
+	f.MustGet(key, &fw)
+	assert.Contains(t, fw.Status.Error, "fatal read error")
+	assert.False(t, fw.Status.ErrorTime.IsZero())
+	assert.Empty(t, fw.Status.SetupError)
+	assert.True(t, fw.Status.SetupErrorTime.IsZero())
+}
+
+func TestController_PollWatchMode(t *testing.T) {
+	f := newFixture(t)
+	f.tmpdir.WriteFile(filepath.Join("a", "existing"), "hello")
+
+	spec := f.SimpleSpec()
+	spec.WatchMode = filewatches.FileWatchModePoll
+	spec.PollInterval = metav1.Duration{Duration: time.Second}
+	key, _ := f.CreateFileWatch(spec)
+
+	f.tmpdir.WriteFile(filepath.Join("a", "new"), "hello")
+	f.AdvancePoll(time.Second)
+	f.WaitForSeenFile(key, "a", "new")
+
+	f.tmpdir.WriteFile(filepath.Join("a", "existing"), "hello world")
+	f.AdvancePoll(time.Second)
+	f.WaitForSeenFile(key, "a", "existing")
+
+	var fw filewatches.FileWatch
+	f.MustGet(key, &fw)
+	require.Equal(t, 2, len(fw.Status.FileEvents), "Wrong file event count")
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "new")}, fw.Status.FileEvents[0].SeenFiles)
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "existing")}, fw.Status.FileEvents[1].SeenFiles)
+}
+
+func TestController_DisablePause(t *testing.T) {
+	f := newFixture(t)
+	// File changes are logged as JSON from the goroutine that records them, which lets
+	// the test tell when a change has been discarded.
+	f.store.WithState(func(state *store.EngineState) {
+		state.LogFormat = store.LogFormatJSON
+	})
+	spec := f.SimpleSpec()
+	spec.DisableMode = filewatches.FileWatchDisableModePause
+	key, _ := f.CreateFileWatch(spec)
+
+	f.ChangeAndWaitForSeenFile(key, "a", "1")
+	var before filewatches.FileWatch
+	f.MustGet(key, &before)
+	f.controller.mu.Lock()
+	w := f.controller.targetWatches[key]
+	f.controller.mu.Unlock()
+
+	f.setDisabled(key, true)
+	f.controller.mu.Lock()
+	assert.Same(t, w, f.controller.targetWatches[key], "monitor should not be torn down while paused")
+	f.controller.mu.Unlock()
+
+	// Events are handled in order, so once the second change has been logged, the first
+	// has been discarded.
+	f.ChangeFile("b", "c", "paused")
+	f.ChangeFile("b", "c", "paused2")
+	require.Eventually(t, func() bool {
+		return strings.Contains(f.Stdout(), f.tmpdir.JoinPath("b", "c", "paused2"))
+	}, timeout, interval, "paused change was never received")
+
+	var paused filewatches.FileWatch
+	f.MustGet(key, &paused)
+	assert.Equal(t, before.Status.MonitorStartTime, paused.Status.MonitorStartTime)
+	assert.Equal(t, before.Status.FileEvents, paused.Status.FileEvents)
+
+	f.setDisabled(key, false)
+	f.ChangeAndWaitForSeenFile(key, "a", "2")
+
+	var resumed filewatches.FileWatch
+	f.MustGet(key, &resumed)
+	assert.Equal(t, before.Status.MonitorStartTime, resumed.Status.MonitorStartTime,
+		"monitor should not be restarted on unpause")
+	assert.NotContains(t, seenFiles(&resumed), f.tmpdir.JoinPath("b", "c", "paused"))
+	f.controller.mu.Lock()
+	assert.Same(t, w, f.controller.targetWatches[key])
+	f.controller.mu.Unlock()
 }
@@ -0,0 +1,161 @@
+package filewatch
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/jonboulle/clockwork"
+	apiresource "k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/tilt-dev/tilt/internal/ignore"
+	"github.com/tilt-dev/tilt/internal/watch"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// ExplainIgnore reports whether a FileWatch with the given spec would ignore path, and
+// describes the rule that decided it.
+//
+// It doesn't need a running watch, so it can't read ignore patterns from ConfigMaps;
+// only the patterns listed in the spec are considered.
+func ExplainIgnore(spec v1alpha1.FileWatchSpec, path string) (bool, string) {
+	watchedPaths, globs, err := resolveWatchedPaths(spec.WatchedPaths)
+	if err != nil {
+		return false, fmt.Sprintf("invalid watchedPaths: %v", err)
+	}
+	if err := checkIgnoreRegexes(spec.Ignores); err != nil {
+		return false, fmt.Sprintf("invalid ignores: %v", err)
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	ignores := make([]v1alpha1.IgnoreDef, len(spec.Ignores))
+	for i, def := range spec.Ignores {
+		ignores[i] = *def.DeepCopy()
+		ignores[i].PatternsConfigMap = nil
+	}
+	rules := newIgnoreRules(spec, rootIgnores(ignores, spec.WatchedPaths), watchedPaths, globs, clockwork.NewRealClock())
+
+	ignored, reason := rules.explain(path)
+	if !ignored {
+		return false, "not ignored by any rule"
+	}
+	return true, reason
+}
+
+// ignoreRules are the rules a FileWatch uses to decide which paths to ignore. They're
+// kept separate, so that each decision can be attributed to the rule that made it.
+//
+// A path is ignored if any rule matches it. The rules are always checked in the same
+// order: include extensions, ignores, ephemeral files, then globs, file size, file age, and depth.
+type ignoreRules struct {
+	exts      watch.PathMatcher
+	ignores   []v1alpha1.IgnoreDef
+	defs      []watch.PathMatcher // one per ignore
+	ephemeral watch.PathMatcher
+	globs     watch.PathMatcher
+	sizes     watch.PathMatcher
+	ages      watch.PathMatcher
+	depths    watch.PathMatcher
+}
+
+// newIgnoreRules builds the rules for spec.
+//
+// ignores are the spec's ignores, with any patterns from ConfigMaps resolved, and globs
+// is the matcher from resolveWatchedPaths for watchedPaths. File ages are measured against clock.
+func newIgnoreRules(spec v1alpha1.FileWatchSpec, ignores []v1alpha1.IgnoreDef, watchedPaths []string, globs watch.PathMatcher, clock clockwork.Clock) ignoreRules {
+	r := ignoreRules{
+		ignores: ignores,
+		defs:    make([]watch.PathMatcher, len(ignores)),
+		globs:   globs,
+	}
+	if !spec.DisableEphemeralIgnores {
+		r.ephemeral = ignore.EphemeralPathMatcher
+		if spec.WatchHiddenFiles {
+			r.ephemeral = exceptHiddenMatcher{matcher: r.ephemeral}
+		}
+	}
+	for i, def := range ignores {
+		r.defs[i] = model.NewCompositeMatcher(ignore.ToMatchersBestEffort([]v1alpha1.IgnoreDef{def}))
+	}
+	if spec.MaxFileSize != "" {
+		// Validation has already rejected unparseable sizes.
+		maxSize, err := apiresource.ParseQuantity(spec.MaxFileSize)
+		if err == nil {
+			r.sizes = ignore.NewFileSizeMatcher(maxSize.Value())
+		}
+	}
+	if maxAge := spec.IgnoreOlderThan.Duration; maxAge > 0 {
+		r.ages = ignore.NewFileAgeMatcher(maxAge, clock)
+	}
+	if len(spec.IncludeExtensions) != 0 {
+		r.exts = ignore.NewExtensionMatcher(spec.IncludeExtensions)
+	}
+	if spec.MaxDepth > 0 {
+		r.depths = newDepthMatcher(watchedPaths, int(spec.MaxDepth))
+	}
+	return r
+}
+
+// matcher combines the rules into a single matcher for the filesystem monitor.
+func (r ignoreRules) matcher() model.PathMatcher {
+	var matchers []model.PathMatcher
+	if r.exts != nil {
+		matchers = append(matchers, r.exts)
+	}
+	matchers = append(matchers, ignore.ToMatchersBestEffort(r.ignores)...)
+	for _, rule := range []watch.PathMatcher{r.ephemeral, r.globs, r.sizes, r.ages, r.depths} {
+		if rule != nil {
+			matchers = append(matchers, rule)
+		}
+	}
+	return model.NewCompositeMatcher(matchers)
+}
+
+// explain reports whether f is ignored, and describes the first rule that matched it.
+func (r ignoreRules) explain(f string) (bool, string) {
+	if r.exts != nil {
+		if ok, _ := r.exts.Matches(f); ok {
+			return true, "extension not in includeExtensions"
+		}
+	}
+	for i, def := range r.defs {
+		if ok, _ := def.Matches(f); ok {
+			d := r.ignores[i]
+			if d.GitignoreFile != "" {
+				return true, fmt.Sprintf("matched ignores[%d]: gitignoreFile=%q", i, d.GitignoreFile)
+			}
+			if len(d.Regex) != 0 {
+				return true, fmt.Sprintf("matched ignores[%d]: basePath=%q patterns=%q regex=%q", i, d.BasePath, d.Patterns, d.Regex)
+			}
+			return true, fmt.Sprintf("matched ignores[%d]: basePath=%q patterns=%q", i, d.BasePath, d.Patterns)
+		}
+	}
+	if r.ephemeral != nil {
+		if ok, _ := r.ephemeral.Matches(f); ok {
+			return true, "ephemeral file"
+		}
+	}
+	if r.globs != nil {
+		if ok, _ := r.globs.Matches(f); ok {
+			return true, "doesn't match any glob in watchedPaths"
+		}
+	}
+	if r.sizes != nil {
+		if ok, _ := r.sizes.Matches(f); ok {
+			return true, "larger than maxFileSize"
+		}
+	}
+	if r.ages != nil {
+		if ok, _ := r.ages.Matches(f); ok {
+			return true, "older than ignoreOlderThan"
+		}
+	}
+	if r.depths != nil {
+		if ok, _ := r.depths.Matches(f); ok {
+			return true, "deeper than maxDepth"
+		}
+	}
+	return false, ""
+}
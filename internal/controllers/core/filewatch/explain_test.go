@@ -0,0 +1,120 @@
+package filewatch
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestExplainIgnore(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.WriteFile(filepath.Join("src", "large.go"), strings.Repeat("x", 2048))
+	f.WriteFile(filepath.Join("src", "small.go"), "package src")
+	f.WriteFile(filepath.Join("src", "old.go"), "package src")
+	old := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(f.JoinPath("src", "old.go"), old, old))
+
+	base := v1alpha1.FileWatchSpec{
+		WatchedPaths: []string{f.JoinPath("src")},
+		Ignores: []v1alpha1.IgnoreDef{
+			{BasePath: f.JoinPath("src"), Patterns: []string{"*.log"}},
+			{Patterns: []string{"build"}},
+		},
+	}
+	withSpec := func(modify func(spec *v1alpha1.FileWatchSpec)) v1alpha1.FileWatchSpec {
+		spec := *base.DeepCopy()
+		modify(&spec)
+		return spec
+	}
+
+	cases := []struct {
+		name     string
+		spec     v1alpha1.FileWatchSpec
+		path     string
+		expected bool
+		reason   string
+	}{
+		{"not ignored", base, "src/main.go", false, "not ignored by any rule"},
+		{"ignore def", base, "src/debug.log", true, "matched ignores[0]"},
+		{"ignore def without base path", base, "src/build/out.o", true, "matched ignores[1]"},
+		{"ignore def is anchored", base, "src/pkg/debug.log", false, "not ignored by any rule"},
+		{"ephemeral", base, "src/.main.go.swp", true, "ephemeral file"},
+		{"ephemeral disabled", withSpec(func(spec *v1alpha1.FileWatchSpec) {
+			spec.DisableEphemeralIgnores = true
+		}), "src/.main.go.swp", false, "not ignored by any rule"},
+		{"ephemeral hidden file watched", withSpec(func(spec *v1alpha1.FileWatchSpec) {
+			spec.WatchHiddenFiles = true
+		}), "src/.main.go.swp", false, "not ignored by any rule"},
+		{"ephemeral visible file with hidden files watched", withSpec(func(spec *v1alpha1.FileWatchSpec) {
+			spec.WatchHiddenFiles = true
+		}), "src/main.go~", true, "ephemeral file"},
+		{"ignore def before ephemeral", withSpec(func(spec *v1alpha1.FileWatchSpec) {
+			spec.Ignores[0].Patterns = []string{".*.swp"}
+		}), "src/.main.go.swp", true, "matched ignores[0]"},
+		{"glob", withSpec(func(spec *v1alpha1.FileWatchSpec) {
+			spec.WatchedPaths = []string{f.JoinPath("src", "**", "*.go")}
+		}), "src/README.md", true, "doesn't match any glob in watchedPaths"},
+		{"glob match", withSpec(func(spec *v1alpha1.FileWatchSpec) {
+			spec.WatchedPaths = []string{f.JoinPath("src", "**", "*.go")}
+		}), "src/pkg/main.go", false, "not ignored by any rule"},
+		{"file size", withSpec(func(spec *v1alpha1.FileWatchSpec) {
+			spec.MaxFileSize = "1Ki"
+		}), "src/large.go", true, "larger than maxFileSize"},
+		{"file size under limit", withSpec(func(spec *v1alpha1.FileWatchSpec) {
+			spec.MaxFileSize = "1Ki"
+		}), "src/small.go", false, "not ignored by any rule"},
+		{"file age", withSpec(func(spec *v1alpha1.FileWatchSpec) {
+			spec.IgnoreOlderThan = metav1.Duration{Duration: time.Hour}
+		}), "src/old.go", true, "older than ignoreOlderThan"},
+		{"file age under limit", withSpec(func(spec *v1alpha1.FileWatchSpec) {
+			spec.IgnoreOlderThan = metav1.Duration{Duration: time.Hour}
+		}), "src/small.go", false, "not ignored by any rule"},
+		{"extension", withSpec(func(spec *v1alpha1.FileWatchSpec) {
+			spec.IncludeExtensions = []string{".go"}
+		}), "src/README.md", true, "extension not in includeExtensions"},
+		{"depth", withSpec(func(spec *v1alpha1.FileWatchSpec) {
+			spec.MaxDepth = 1
+		}), "src/pkg/main.go", true, "deeper than maxDepth"},
+		{"ignore wins over include extensions", withSpec(func(spec *v1alpha1.FileWatchSpec) {
+			spec.IncludeExtensions = []string{".go"}
+		}), "src/build/main.go", true, "matched ignores[1]"},
+		{"include extensions checked before ignores", withSpec(func(spec *v1alpha1.FileWatchSpec) {
+			spec.IncludeExtensions = []string{".go"}
+		}), "src/build/out.o", true, "extension not in includeExtensions"},
+		{"include extensions checked before ephemeral", withSpec(func(spec *v1alpha1.FileWatchSpec) {
+			spec.IncludeExtensions = []string{".go"}
+		}), "src/.main.go.swp", true, "extension not in includeExtensions"},
+		{"ignores checked before ephemeral", base, "src/build/.main.go.swp", true, "matched ignores[1]"},
+		{"ephemeral wins over include extensions", withSpec(func(spec *v1alpha1.FileWatchSpec) {
+			spec.IncludeExtensions = []string{".go"}
+		}), "src/.idea/main.go", true, "ephemeral file"},
+		{"regex", withSpec(func(spec *v1alpha1.FileWatchSpec) {
+			spec.Ignores[1] = v1alpha1.IgnoreDef{Regex: []string{`[0-9]{12}\.sql$`}}
+		}), "src/pkg/backup-202401021504.sql", true, "matched ignores[1]"},
+		{"regex no match", withSpec(func(spec *v1alpha1.FileWatchSpec) {
+			spec.Ignores[1] = v1alpha1.IgnoreDef{Regex: []string{`[0-9]{12}\.sql$`}}
+		}), "src/pkg/backup-2024.sql", false, "not ignored by any rule"},
+		{"invalid regex", withSpec(func(spec *v1alpha1.FileWatchSpec) {
+			spec.Ignores[1] = v1alpha1.IgnoreDef{Regex: []string{`build(`}}
+		}), "src/build(/main.go", false, "invalid ignores: ignores[1]: invalid regex"},
+		{"configmap patterns are skipped", withSpec(func(spec *v1alpha1.FileWatchSpec) {
+			spec.Ignores[0].PatternsConfigMap = &v1alpha1.ConfigMapPatternsSource{Name: "ignores", Key: "patterns"}
+		}), "src/debug.log", true, "matched ignores[0]"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ignored, reason := ExplainIgnore(c.spec, f.JoinPath(c.path))
+			assert.Equal(t, c.expected, ignored)
+			assert.Contains(t, reason, c.reason)
+		})
+	}
+}
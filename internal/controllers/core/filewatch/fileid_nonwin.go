@@ -0,0 +1,23 @@
+//go:build !windows
+// +build !windows
+
+package filewatch
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileIDOf returns the device, inode, size and modification time of the file described by info.
+func fileIDOf(info os.FileInfo) (fileID, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileID{}, false
+	}
+	return fileID{
+		dev:   uint64(st.Dev),
+		ino:   uint64(st.Ino),
+		size:  info.Size(),
+		mtime: info.ModTime().UnixNano(),
+	}, true
+}
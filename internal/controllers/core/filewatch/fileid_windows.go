@@ -0,0 +1,12 @@
+//go:build windows
+// +build windows
+
+package filewatch
+
+import "os"
+
+// fileIDOf always fails on Windows, where a file's ID can only be read from an open
+// handle, so moves aren't tracked.
+func fileIDOf(info os.FileInfo) (fileID, bool) {
+	return fileID{}, false
+}
@@ -0,0 +1,47 @@
+package fsevent
+
+import (
+	"context"
+
+	"github.com/tilt-dev/tilt/internal/watch"
+)
+
+// DefaultEventBufferSize is the number of file changes Buffer queues when no size is given.
+const DefaultEventBufferSize = 1024
+
+// Buffer relays events from `eventChan` through a queue of `size` events, so that whoever
+// is sending them isn't blocked while the consumer is busy. If `size` is zero,
+// DefaultEventBufferSize is used.
+//
+// Events are never dropped. When the queue is full, `onFull` is called and the relay
+// waits for room.
+//
+// The returned channel is closed once `eventChan` is closed, or when `ctx` is done.
+func Buffer(ctx context.Context, size int, eventChan <-chan watch.FileEvent, onFull func()) <-chan watch.FileEvent {
+	if size <= 0 {
+		size = DefaultEventBufferSize
+	}
+
+	ret := make(chan watch.FileEvent, size)
+	go func() {
+		defer close(ret)
+
+		for event := range eventChan {
+			select {
+			case ret <- event:
+				continue
+			default:
+			}
+
+			if onFull != nil {
+				onFull()
+			}
+			select {
+			case ret <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ret
+}
@@ -0,0 +1,70 @@
+package fsevent
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/watch"
+)
+
+func TestBuffer_WaitsForRoomWhenFull(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	in := make(chan watch.FileEvent)
+	var fullCount atomic.Int32
+	out := Buffer(ctx, 2, in, func() { fullCount.Add(1) })
+
+	dir := t.TempDir()
+	var sent []string
+	for i := 0; i < 5; i++ {
+		sent = append(sent, filepath.Join(dir, fmt.Sprintf("%d.txt", i)))
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, p := range sent {
+			in <- watch.NewFileEvent(p)
+		}
+		close(in)
+	}()
+
+	// nobody is reading, so the relay fills the buffer and then waits
+	require.Eventually(t, func() bool {
+		return fullCount.Load() == 1 && len(out) == 2
+	}, time.Second, time.Millisecond)
+
+	var received []string
+	for e := range out {
+		received = append(received, e.Path())
+	}
+	<-done
+	assert.Equal(t, sent, received, "no events should be lost")
+}
+
+func TestBuffer_StopsWhenContextIsDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	in := make(chan watch.FileEvent, 2)
+	out := Buffer(ctx, 1, in, nil)
+
+	path := filepath.Join(t.TempDir(), "a.txt")
+	in <- watch.NewFileEvent(path)
+	in <- watch.NewFileEvent(path)
+	require.Eventually(t, func() bool { return len(in) == 0 }, time.Second, time.Millisecond)
+
+	cancel()
+	e, ok := <-out
+	require.True(t, ok)
+	assert.Equal(t, path, e.Path())
+	_, ok = <-out
+	assert.False(t, ok, "the relay should close its channel once the context is done")
+}
@@ -1,6 +1,8 @@
 package fsevent
 
 import (
+	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/tilt-dev/tilt/internal/watch"
@@ -20,10 +22,35 @@ const BufferMinRestDuration = 200 * time.Millisecond
 // channel if the threshold is reached even if new file changes are still coming in.
 const BufferMaxDuration = 10 * time.Second
 
+// FlushReason is why Coalesce emitted a Batch.
+type FlushReason int
+
+const (
+	// FlushRest means the `timers.Rest` timer fired without seeing a change.
+	FlushRest FlushReason = iota
+	// FlushMax means the `timers.Max` timer fired while changes were still coming in.
+	FlushMax
+	// FlushClosed means `eventChan` was closed.
+	FlushClosed
+	// FlushBatchSize means the batch reached `maxBatchSize` distinct paths.
+	FlushBatchSize
+)
+
+// Batch is a group of file changes emitted by Coalesce.
+type Batch struct {
+	Events []watch.FileEvent
+	Reason FlushReason
+}
+
 // Coalesce makes an attempt to read some events from `eventChan` so that multiple file changes
 // that happen at the same time from the user's perspective are grouped together.
-func Coalesce(timerMaker TimerMaker, eventChan <-chan watch.FileEvent) <-chan []watch.FileEvent {
-	ret := make(chan []watch.FileEvent)
+//
+// A batch is emitted once the `timers.Rest` timer fires without seeing a change, or once
+// the `timers.Max` timer fires. If `timers.Max` is nil, a batch stays open for as long as
+// changes keep coming in. If `maxBatchSize` is positive, a batch is also emitted as soon as
+// it has changes to that many distinct paths, without waiting for either timer.
+func Coalesce(timers DebounceTimers, maxBatchSize int, eventChan <-chan watch.FileEvent) <-chan Batch {
+	ret := make(chan Batch)
 	go func() {
 		defer close(ret)
 
@@ -33,33 +60,49 @@ func Coalesce(timerMaker TimerMaker, eventChan <-chan watch.FileEvent) <-chan []
 				return
 			}
 			events := []watch.FileEvent{event}
+			paths := map[string]bool{event.Path(): true}
+			if maxBatchSize > 0 && len(paths) >= maxBatchSize {
+				ret <- Batch{Events: events, Reason: FlushBatchSize}
+				continue
+			}
 
-			// keep grabbing changes until we've gone `BufferMinRestDuration` without seeing a change
-			minRestTimer := timerMaker(BufferMinRestDuration)
+			// keep grabbing changes until we've gone the debounce window without seeing a change
+			minRestTimer := timers.Rest()
 
 			// but if we go too long before seeing a break (e.g., a process is constantly writing logs to that dir)
 			// then just send what we've got
-			timeout := timerMaker(BufferMaxDuration)
+			var timeout <-chan time.Time
+			if timers.Max != nil {
+				timeout = timers.Max()
+			}
 
 			done := false
 			channelClosed := false
+			reason := FlushClosed
 			for !done && !channelClosed {
 				select {
 				case event, ok := <-eventChan:
 					if !ok {
 						channelClosed = true
 					} else {
-						minRestTimer = timerMaker(BufferMinRestDuration)
+						minRestTimer = timers.Rest()
 						events = append(events, event)
+						paths[event.Path()] = true
+						if maxBatchSize > 0 && len(paths) >= maxBatchSize {
+							done = true
+							reason = FlushBatchSize
+						}
 					}
 				case <-minRestTimer:
 					done = true
+					reason = FlushRest
 				case <-timeout:
 					done = true
+					reason = FlushMax
 				}
 			}
 			if len(events) > 0 {
-				ret <- events
+				ret <- Batch{Events: events, Reason: reason}
 			}
 
 			if channelClosed {
@@ -70,3 +113,66 @@ func Coalesce(timerMaker TimerMaker, eventChan <-chan watch.FileEvent) <-chan []
 	}()
 	return ret
 }
+
+// CoalescePerDir is like Coalesce, but batches the file changes in each directory on their
+// own timers, so that a busy directory doesn't delay the changes in a quiet one.
+//
+// Changes are grouped by the directory that contains the changed path. A directory's
+// goroutine exits once it has flushed all of the changes it was sent, and a new one is
+// started the next time that directory changes.
+func CoalescePerDir(timers DebounceTimers, maxBatchSize int, eventChan <-chan watch.FileEvent) <-chan Batch {
+	ret := make(chan Batch)
+	go func() {
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		dirs := make(map[string]*dirBatcher)
+		defer func() {
+			mu.Lock()
+			for dir, d := range dirs {
+				delete(dirs, dir)
+				close(d.events)
+			}
+			mu.Unlock()
+			wg.Wait()
+			close(ret)
+		}()
+
+		for event := range eventChan {
+			dir := filepath.Dir(event.Path())
+			mu.Lock()
+			d, ok := dirs[dir]
+			if !ok {
+				d = &dirBatcher{events: make(chan watch.FileEvent)}
+				dirs[dir] = d
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for batch := range Coalesce(timers, maxBatchSize, d.events) {
+						ret <- batch
+
+						mu.Lock()
+						d.pending -= len(batch.Events)
+						if d.pending == 0 && dirs[dir] == d {
+							delete(dirs, dir)
+							close(d.events)
+						}
+						mu.Unlock()
+					}
+				}()
+			}
+			d.pending++
+			mu.Unlock()
+			d.events <- event
+		}
+	}()
+	return ret
+}
+
+// dirBatcher feeds the changes in one directory to its own Coalesce.
+type dirBatcher struct {
+	events chan watch.FileEvent
+
+	// The number of changes sent to events that haven't been flushed yet.
+	// Guarded by the mutex in CoalescePerDir.
+	pending int
+}
@@ -0,0 +1,160 @@
+package fsevent
+
+import (
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/watch"
+)
+
+func TestCoalesce_MaxBatchSizeFlushesBeforeTimers(t *testing.T) {
+	timers := MakeFakeTimerMaker(t)
+	timers.RestTimerLock.Lock()
+	defer timers.RestTimerLock.Unlock()
+	timers.MaxTimerLock.Lock()
+	defer timers.MaxTimerLock.Unlock()
+
+	in := make(chan watch.FileEvent)
+	out := Coalesce(timers.Maker()(time.Second), 2, in)
+
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.txt")
+	b := filepath.Join(dir, "b.txt")
+	c := filepath.Join(dir, "c.txt")
+	go func() {
+		// a repeated path doesn't count towards the size
+		for _, p := range []string{a, a, b, c} {
+			in <- watch.NewFileEvent(p)
+		}
+	}()
+
+	// Neither timer can fire, so the batch is flushed by its size.
+	select {
+	case batch := <-out:
+		assert.Equal(t, FlushBatchSize, batch.Reason)
+		var paths []string
+		for _, e := range batch.Events {
+			paths = append(paths, e.Path())
+		}
+		assert.Equal(t, []string{a, a, b}, paths)
+	case <-time.After(time.Second):
+		t.Fatal("batch was never flushed")
+	}
+}
+
+func TestCoalescePerDir_BusyDirDoesNotDelayQuietDir(t *testing.T) {
+	clock := clockwork.NewFakeClock()
+	timers := NewDebounceTimersMaker(clock.After)(100 * time.Millisecond)
+	timers.Max = nil
+
+	in := make(chan watch.FileEvent)
+	out := CoalescePerDir(timers, 0, in)
+
+	dir := t.TempDir()
+	a1 := filepath.Join(dir, "a", "1.txt")
+	b1 := filepath.Join(dir, "b", "1.txt")
+	b2 := filepath.Join(dir, "b", "2.txt")
+
+	in <- watch.NewFileEvent(a1)
+	in <- watch.NewFileEvent(b1)
+	clock.BlockUntil(2)
+	clock.Advance(60 * time.Millisecond)
+
+	// b stays busy, which restarts its timer but not a's.
+	in <- watch.NewFileEvent(b2)
+	clock.BlockUntil(3)
+	clock.Advance(60 * time.Millisecond)
+	assert.Equal(t, []string{a1}, batchPaths(t, out))
+
+	select {
+	case batch := <-out:
+		t.Fatalf("b flushed before its debounce window: %v", batch.Events)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	clock.Advance(40 * time.Millisecond)
+	assert.Equal(t, []string{b1, b2}, batchPaths(t, out))
+
+	close(in)
+	_, ok := <-out
+	assert.False(t, ok)
+}
+
+func TestCoalescePerDir_FlushesPendingOnClose(t *testing.T) {
+	clock := clockwork.NewFakeClock()
+	in := make(chan watch.FileEvent)
+	out := CoalescePerDir(NewDebounceTimersMaker(clock.After)(time.Second), 0, in)
+
+	path := filepath.Join(t.TempDir(), "a.txt")
+	in <- watch.NewFileEvent(path)
+	close(in)
+
+	select {
+	case batch := <-out:
+		assert.Equal(t, FlushClosed, batch.Reason)
+		require.Len(t, batch.Events, 1)
+		assert.Equal(t, path, batch.Events[0].Path())
+	case <-time.After(time.Second):
+		t.Fatal("pending changes were never flushed")
+	}
+}
+
+func TestCoalescePerDir_QuietDirGoroutinesExit(t *testing.T) {
+	clock := clockwork.NewFakeClock()
+	timers := NewDebounceTimersMaker(clock.After)(100 * time.Millisecond)
+	timers.Max = nil
+
+	before := runtime.NumGoroutine()
+	in := make(chan watch.FileEvent)
+	out := CoalescePerDir(timers, 0, in)
+	defer close(in)
+
+	dir := t.TempDir()
+	const dirCount = 50
+	for i := 0; i < dirCount; i++ {
+		in <- watch.NewFileEvent(filepath.Join(dir, strconv.Itoa(i), "a.txt"))
+	}
+	clock.BlockUntil(dirCount)
+	clock.Advance(100 * time.Millisecond)
+	for i := 0; i < dirCount; i++ {
+		require.Len(t, batchPaths(t, out), 1)
+	}
+
+	// Only the goroutine reading eventChan is left once every directory has flushed.
+	// (Not assert.Eventually, which runs its condition on another goroutine.)
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before+1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.LessOrEqual(t, runtime.NumGoroutine(), before+1, "directory goroutines never exited")
+
+	// A directory that changes again gets a new goroutine.
+	path := filepath.Join(dir, "0", "b.txt")
+	in <- watch.NewFileEvent(path)
+	clock.BlockUntil(1)
+	clock.Advance(100 * time.Millisecond)
+	assert.Equal(t, []string{path}, batchPaths(t, out))
+}
+
+func batchPaths(t *testing.T, out <-chan Batch) []string {
+	t.Helper()
+	select {
+	case batch := <-out:
+		assert.Equal(t, FlushRest, batch.Reason)
+		var paths []string
+		for _, e := range batch.Events {
+			paths = append(paths, e.Path())
+		}
+		return paths
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for a batch")
+		return nil
+	}
+}
@@ -11,10 +11,40 @@ type WatcherMaker func(paths []string, ignore watch.PathMatcher, l logger.Logger
 
 type TimerMaker func(d time.Duration) <-chan time.Time
 
+// DebounceTimers are the timers used to coalesce the file changes of a single watch.
+type DebounceTimers struct {
+	// Rest starts a timer that fires once the watch's debounce window has passed.
+	Rest func() <-chan time.Time
+
+	// Max starts a timer that fires once a batch has been open for too long,
+	// even if file changes are still coming in. Nil if there's no limit.
+	Max func() <-chan time.Time
+}
+
+// DebounceTimersMaker creates the DebounceTimers for a watch with the given
+// debounce window. If minRest is zero, BufferMinRestDuration is used.
+type DebounceTimersMaker func(minRest time.Duration) DebounceTimers
+
+// NewDebounceTimersMaker creates DebounceTimers whose timers all come from timerMaker.
+func NewDebounceTimersMaker(timerMaker TimerMaker) DebounceTimersMaker {
+	return func(minRest time.Duration) DebounceTimers {
+		if minRest <= 0 {
+			minRest = BufferMinRestDuration
+		}
+		return DebounceTimers{
+			Rest: func() <-chan time.Time { return timerMaker(minRest) },
+			Max:  func() <-chan time.Time { return timerMaker(BufferMaxDuration) },
+		}
+	}
+}
+
 func ProvideWatcherMaker() WatcherMaker {
+	if shareMonitorsFromEnv() {
+		return NewSharedWatcherMaker(watch.NewWatcher)
+	}
 	return watch.NewWatcher
 }
 
-func ProvideTimerMaker() TimerMaker {
-	return time.After
+func ProvideDebounceTimersMaker() DebounceTimersMaker {
+	return NewDebounceTimersMaker(time.After)
 }
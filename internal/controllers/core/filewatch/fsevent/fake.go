@@ -12,31 +12,32 @@ type FakeTimerMaker struct {
 	t             *testing.T
 }
 
-func (f FakeTimerMaker) Maker() TimerMaker {
-	return func(d time.Duration) <-chan time.Time {
-		var lock *sync.Mutex
+// Maker returns a DebounceTimersMaker whose timers fire as soon as their lock is free,
+// regardless of the watch's debounce window.
+func (f FakeTimerMaker) Maker() DebounceTimersMaker {
+	return func(minRest time.Duration) DebounceTimers {
+		if minRest < 0 {
+			f.t.Errorf("debounce timers requested with negative duration %s", minRest)
+		}
 		// we have separate locks for the separate uses of timer so that tests can control the timers independently
-		switch d {
-		case BufferMinRestDuration:
-			lock = f.RestTimerLock
-		case BufferMaxDuration:
-			lock = f.MaxTimerLock
-		default:
-			// if you hit this, someone (you!?) might have added a new timer with a new duration, and you probably
-			// want to add a case above
-			f.t.Error("makeTimer called on unsupported duration")
+		return DebounceTimers{
+			Rest: func() <-chan time.Time { return f.fire(f.RestTimerLock) },
+			Max:  func() <-chan time.Time { return f.fire(f.MaxTimerLock) },
 		}
-		ret := make(chan time.Time, 1)
-		go func() {
-			lock.Lock()
-			ret <- time.Unix(0, 0)
-			lock.Unlock()
-			close(ret)
-		}()
-		return ret
 	}
 }
 
+func (f FakeTimerMaker) fire(lock *sync.Mutex) <-chan time.Time {
+	ret := make(chan time.Time, 1)
+	go func() {
+		lock.Lock()
+		ret <- time.Unix(0, 0)
+		lock.Unlock()
+		close(ret)
+	}()
+	return ret
+}
+
 func MakeFakeTimerMaker(t *testing.T) FakeTimerMaker {
 	restTimerLock := new(sync.Mutex)
 	maxTimerLock := new(sync.Mutex)
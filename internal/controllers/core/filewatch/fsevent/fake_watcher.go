@@ -4,12 +4,17 @@ import (
 	"path/filepath"
 	"sync"
 	"sync/atomic"
+	"testing"
 
 	"github.com/tilt-dev/tilt/internal/ospath"
 	"github.com/tilt-dev/tilt/internal/watch"
 	"github.com/tilt-dev/tilt/pkg/logger"
 )
 
+// DefaultFakeBufferSize is the size of the Events and Errors buffers of a
+// FakeMultiWatcher created with NewFakeMultiWatcher.
+const DefaultFakeBufferSize = 20
+
 type FakeMultiWatcher struct {
 	Events chan watch.FileEvent
 	Errors chan error
@@ -18,17 +23,78 @@ type FakeMultiWatcher struct {
 	watchers   []*FakeWatcher
 	subs       []chan watch.FileEvent
 	subsErrors []chan error
+
+	// held while dispatching is paused, so that the Events buffer fills up
+	// the way it would if the real watcher's consumer fell behind.
+	pauseMu sync.Mutex
+
+	dropped uint64
 }
 
 func NewFakeMultiWatcher() *FakeMultiWatcher {
+	return NewFakeMultiWatcherWithBufferSize(DefaultFakeBufferSize)
+}
+
+// NewFakeMultiWatcherWithBufferSize creates a FakeMultiWatcher that can hold
+// bufferSize undispatched events before emitting more would block.
+func NewFakeMultiWatcherWithBufferSize(bufferSize int) *FakeMultiWatcher {
 	r := &FakeMultiWatcher{
-		Events: make(chan watch.FileEvent, 20),
-		Errors: make(chan error, 20),
+		Events: make(chan watch.FileEvent, bufferSize),
+		Errors: make(chan error, bufferSize),
 	}
 	go r.loop()
 	return r
 }
 
+// TryEmit queues e for dispatch without blocking.
+//
+// Returns false (and counts the event as dropped) if the buffer is full.
+func (w *FakeMultiWatcher) TryEmit(e watch.FileEvent) bool {
+	select {
+	case w.Events <- e:
+		return true
+	default:
+		atomic.AddUint64(&w.dropped, 1)
+		return false
+	}
+}
+
+// RequireEmit queues e for dispatch, failing the test if doing so would block.
+func (w *FakeMultiWatcher) RequireEmit(t testing.TB, e watch.FileEvent) {
+	t.Helper()
+	if !w.TryEmit(e) {
+		t.Fatalf("emitting a FileEvent would block (buffer size %d). "+
+			"Perhaps there are too many events or the buffer size is too small.", cap(w.Events))
+	}
+}
+
+// RequireEmitDropped asserts that emitting e would block, i.e., that the
+// event is dropped because the buffer is full.
+func (w *FakeMultiWatcher) RequireEmitDropped(t testing.TB, e watch.FileEvent) {
+	t.Helper()
+	if w.TryEmit(e) {
+		t.Fatalf("expected emitting a FileEvent to be dropped, but it was queued (%d/%d buffered)",
+			len(w.Events), cap(w.Events))
+	}
+}
+
+// DroppedCount returns the number of events TryEmit could not queue.
+func (w *FakeMultiWatcher) DroppedCount() uint64 {
+	return atomic.LoadUint64(&w.dropped)
+}
+
+// Pause stops dispatching events to subscribers until Resume is called.
+//
+// At most one event already taken off the buffer may be held while paused.
+func (w *FakeMultiWatcher) Pause() {
+	w.pauseMu.Lock()
+}
+
+// Resume restarts dispatching after Pause.
+func (w *FakeMultiWatcher) Resume() {
+	w.pauseMu.Unlock()
+}
+
 func (w *FakeMultiWatcher) NewSub(paths []string, ignore watch.PathMatcher, _ logger.Logger) (watch.Notify, error) {
 	subCh := make(chan watch.FileEvent)
 	errorCh := make(chan error)
@@ -71,6 +137,7 @@ func (w *FakeMultiWatcher) loop() {
 			if !ok {
 				return
 			}
+			w.waitIfPaused()
 			w.mu.Lock()
 			for _, watcher := range w.watchers {
 				if watcher.Running && watcher.matches(e.Path()) {
@@ -82,13 +149,23 @@ func (w *FakeMultiWatcher) loop() {
 			if !ok {
 				return
 			}
-			for _, sub := range w.getSubErrors() {
-				sub <- e
+			w.waitIfPaused()
+			w.mu.Lock()
+			for _, watcher := range w.watchers {
+				if watcher.Running {
+					watcher.errorCh <- e
+				}
 			}
+			w.mu.Unlock()
 		}
 	}
 }
 
+func (w *FakeMultiWatcher) waitIfPaused() {
+	w.pauseMu.Lock()
+	defer w.pauseMu.Unlock()
+}
+
 type FakeWatcher struct {
 	inboundCh  chan watch.FileEvent
 	outboundCh chan watch.FileEvent
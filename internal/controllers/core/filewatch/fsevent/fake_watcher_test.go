@@ -0,0 +1,56 @@
+package fsevent
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/watch"
+)
+
+func TestFakeMultiWatcher_DropsWhenBufferFull(t *testing.T) {
+	dir := t.TempDir()
+	w := NewFakeMultiWatcherWithBufferSize(2)
+	sub, err := w.NewSub([]string{dir}, watch.EmptyMatcher{}, nil)
+	require.NoError(t, err)
+	require.NoError(t, sub.Start())
+
+	w.Pause()
+
+	// The dispatch loop may have already taken one event off the buffer before
+	// it noticed the pause, so the buffer fills after 2 or 3 events.
+	var queued []string
+	for i := 0; i < 4; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("%d.txt", i))
+		if !w.TryEmit(watch.NewFileEvent(path)) {
+			break
+		}
+		queued = append(queued, path)
+	}
+	require.GreaterOrEqual(t, len(queued), 2)
+	require.LessOrEqual(t, len(queued), 3)
+	assert.Equal(t, uint64(1), w.DroppedCount())
+
+	w.RequireEmitDropped(t, watch.NewFileEvent(filepath.Join(dir, "dropped.txt")))
+	assert.Equal(t, uint64(2), w.DroppedCount())
+
+	w.Resume()
+
+	var seen []string
+	for len(seen) < len(queued) {
+		select {
+		case e := <-sub.Events():
+			seen = append(seen, e.Path())
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for events, got %v, want %v", seen, queued)
+		}
+	}
+	assert.Equal(t, queued, seen)
+
+	// once dispatching has resumed, there's room again
+	w.RequireEmit(t, watch.NewFileEvent(filepath.Join(dir, "after.txt")))
+}
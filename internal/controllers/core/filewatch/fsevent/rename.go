@@ -0,0 +1,73 @@
+package fsevent
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// CollapseRenames removes the temporary files from a batch of changes where an editor
+// saved a file by writing a temp file and renaming it over the target (e.g., `main.go~`
+// or `main.go___jb_tmp___` renamed to `main.go`), so that only the target is reported.
+//
+// A path is treated as one of these temp files if it no longer exists, its name follows
+// one of the temp file patterns editors are known to use, and the batch also has a change
+// to the existing file that the name was derived from, in the same directory.
+func CollapseRenames(paths []string, exists func(path string) bool) []string {
+	existing := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		existing[p] = exists(p)
+	}
+
+	result := make([]string, 0, len(paths))
+	for _, p := range paths {
+		if existing[p] || !isRenamedTempFile(p, existing) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+func isRenamedTempFile(p string, existing map[string]bool) bool {
+	dir, base := filepath.Split(p)
+	for _, target := range renameTargets(base) {
+		if existing[filepath.Join(dir, target)] {
+			return true
+		}
+	}
+	return false
+}
+
+// renameTargets returns the names of the files that a temp file called name may be
+// renamed over, or nothing if name isn't a known temp file name.
+func renameTargets(name string) []string {
+	for _, suffix := range []string{"~", "___jb_tmp___", "___jb_old___"} {
+		if target, ok := trimAffixes(name, "", suffix); ok {
+			return []string{target}
+		}
+	}
+	if target, ok := trimAffixes(name, ".", ".swp"); ok {
+		return []string{target}
+	}
+
+	// e.g., `main.go.tmp`, `main.go.tmp1234`, or `.main.go.tmp.1234`, as written by
+	// atomic file writers. A leading dot may be part of the target's name, or not.
+	i := strings.LastIndex(name, ".tmp")
+	if i <= 0 || !isDigits(strings.TrimPrefix(name[i+len(".tmp"):], ".")) {
+		return nil
+	}
+	targets := []string{name[:i]}
+	if target, ok := trimAffixes(name[:i], ".", ""); ok {
+		targets = append(targets, target)
+	}
+	return targets
+}
+
+// isDigits reports whether s is made up only of digits. An empty s is.
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
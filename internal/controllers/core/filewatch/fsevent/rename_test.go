@@ -0,0 +1,47 @@
+package fsevent
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollapseRenames(t *testing.T) {
+	dir := filepath.Join(string(filepath.Separator), "src")
+	main := filepath.Join(dir, "main.go")
+	other := filepath.Join(dir, "other.go")
+	deleted := filepath.Join(dir, "deleted.go")
+	sub := filepath.Join(dir, "sub", "main.go~")
+
+	for _, tc := range []struct {
+		name     string
+		paths    []string
+		existing []string
+		expected []string
+	}{
+		{"vim backup", []string{filepath.Join(dir, "main.go~"), main}, []string{main}, []string{main}},
+		{"intellij", []string{filepath.Join(dir, "main.go___jb_tmp___"), filepath.Join(dir, "main.go___jb_old___"), main}, []string{main}, []string{main}},
+		{"temp file still exists", []string{filepath.Join(dir, "main.go.tmp"), main}, []string{filepath.Join(dir, "main.go.tmp"), main}, []string{filepath.Join(dir, "main.go.tmp"), main}},
+		{"unrelated deletion", []string{deleted, main}, []string{main}, []string{deleted, main}},
+		{"deleted target", []string{filepath.Join(dir, "main.go~"), main}, nil, []string{filepath.Join(dir, "main.go~"), main}},
+		{"different directory", []string{sub, main}, []string{main}, []string{sub, main}},
+		{"no renames", []string{main, other}, []string{main, other}, []string{main, other}},
+		{"swap file", []string{filepath.Join(dir, ".main.go.swp"), main}, []string{main}, []string{main}},
+		{"numbered temp file", []string{filepath.Join(dir, ".main.go.tmp.1234"), main}, []string{main}, []string{main}},
+		{"sibling containing the name", []string{filepath.Join(dir, "string_main.go"), main}, []string{main}, []string{filepath.Join(dir, "string_main.go"), main}},
+		{"backup file", []string{filepath.Join(dir, "main.go.bak"), main}, []string{main}, []string{filepath.Join(dir, "main.go.bak"), main}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			exists := func(p string) bool {
+				for _, e := range tc.existing {
+					if e == p {
+						return true
+					}
+				}
+				return false
+			}
+			assert.Equal(t, tc.expected, CollapseRenames(tc.paths, exists))
+		})
+	}
+}
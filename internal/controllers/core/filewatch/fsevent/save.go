@@ -0,0 +1,111 @@
+package fsevent
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/tilt-dev/tilt/internal/watch"
+)
+
+// SaveProfile describes the temp files an editor writes while saving a file.
+type SaveProfile struct {
+	// Target returns the name of the file being saved for the name of one of the editor's
+	// temp files, or false if name isn't one. The target is empty for temp files that
+	// aren't tied to a single file.
+	Target func(name string) (string, bool)
+}
+
+// VimSaveProfile recognizes swap files, backup files, and the 4913 permissions check.
+var VimSaveProfile = SaveProfile{Target: func(name string) (string, bool) {
+	if name == "4913" {
+		return "", true
+	}
+	for _, ext := range []string{".swp", ".swx", ".swo", ".swn"} {
+		if target, ok := trimAffixes(name, ".", ext); ok {
+			return target, true
+		}
+	}
+	return trimAffixes(name, "", "~")
+}}
+
+// EmacsSaveProfile recognizes auto-save, lock, and backup files.
+var EmacsSaveProfile = SaveProfile{Target: func(name string) (string, bool) {
+	if target, ok := trimAffixes(name, "#", "#"); ok {
+		return target, true
+	}
+	if target, ok := trimAffixes(name, ".#", ""); ok {
+		return target, true
+	}
+	return trimAffixes(name, "", "~")
+}}
+
+// JetBrainsSaveProfile recognizes the temp files JetBrains IDEs rename over the target.
+var JetBrainsSaveProfile = SaveProfile{Target: func(name string) (string, bool) {
+	if target, ok := trimAffixes(name, "", "___jb_tmp___"); ok {
+		return target, true
+	}
+	return trimAffixes(name, "", "___jb_old___")
+}}
+
+// trimAffixes removes prefix and suffix from name, or returns false if name doesn't
+// have both of them around a non-empty remainder.
+func trimAffixes(name, prefix, suffix string) (string, bool) {
+	if len(name) <= len(prefix)+len(suffix) || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, suffix) {
+		return "", false
+	}
+	return name[len(prefix) : len(name)-len(suffix)], true
+}
+
+// CollapseSaves replaces changes to the temp files that the profiles' editors write while
+// saving with a change to the file being saved, so that a save is reported as a single
+// change to its target.
+//
+// A target that ignore matches isn't reported, just as it wouldn't be if the monitor had
+// seen the change to it directly.
+func CollapseSaves(paths []string, profiles []SaveProfile, ignore watch.PathMatcher) []string {
+	if len(profiles) == 0 {
+		return paths
+	}
+
+	seen := make(map[string]bool, len(paths))
+	result := make([]string, 0, len(paths))
+	add := func(p string) {
+		if !seen[p] {
+			seen[p] = true
+			result = append(result, p)
+		}
+	}
+	for _, p := range paths {
+		target, ok := saveTarget(p, profiles)
+		if !ok {
+			add(p)
+			continue
+		}
+		if target == "" {
+			continue
+		}
+		if ignore != nil {
+			if ignored, _ := ignore.Matches(target); ignored {
+				continue
+			}
+		}
+		add(target)
+	}
+	return result
+}
+
+// saveTarget returns the path of the file being saved if p is one of the profiles' temp files.
+func saveTarget(p string, profiles []SaveProfile) (string, bool) {
+	dir, name := filepath.Split(p)
+	for _, profile := range profiles {
+		target, ok := profile.Target(name)
+		if !ok {
+			continue
+		}
+		if target == "" {
+			return "", true
+		}
+		return filepath.Join(dir, target), true
+	}
+	return "", false
+}
@@ -0,0 +1,71 @@
+package fsevent
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tilt-dev/tilt/internal/watch"
+)
+
+func TestCollapseSaves(t *testing.T) {
+	dir := filepath.Join(string(filepath.Separator), "src")
+	main := filepath.Join(dir, "main.go")
+	other := filepath.Join(dir, "other.go")
+	ignored := filepath.Join(dir, "main.log")
+
+	ignore := fakeMatcher{ignored: ignored}
+	for _, tc := range []struct {
+		name     string
+		profiles []SaveProfile
+		paths    []string
+		expected []string
+	}{
+		{"vim save", []SaveProfile{VimSaveProfile},
+			[]string{filepath.Join(dir, "4913"), filepath.Join(dir, ".main.go.swp"), filepath.Join(dir, "main.go~"), main},
+			[]string{main}},
+		{"vim swap file only", []SaveProfile{VimSaveProfile},
+			[]string{filepath.Join(dir, ".main.go.swx")},
+			[]string{main}},
+		{"emacs save", []SaveProfile{EmacsSaveProfile},
+			[]string{filepath.Join(dir, ".#main.go"), filepath.Join(dir, "#main.go#"), main, filepath.Join(dir, "main.go~")},
+			[]string{main}},
+		{"jetbrains save", []SaveProfile{JetBrainsSaveProfile},
+			[]string{filepath.Join(dir, "main.go___jb_tmp___"), filepath.Join(dir, "main.go___jb_old___"), main},
+			[]string{main}},
+		{"other files are kept", []SaveProfile{VimSaveProfile},
+			[]string{other, filepath.Join(dir, ".main.go.swp"), main},
+			[]string{other, main}},
+		{"profile not enabled", []SaveProfile{VimSaveProfile},
+			[]string{filepath.Join(dir, "#main.go#"), main},
+			[]string{filepath.Join(dir, "#main.go#"), main}},
+		{"no profiles", nil,
+			[]string{filepath.Join(dir, "4913"), main},
+			[]string{filepath.Join(dir, "4913"), main}},
+		{"ignored target", []SaveProfile{EmacsSaveProfile},
+			[]string{filepath.Join(dir, "#main.log#")},
+			[]string{}},
+		{"affixes alone", []SaveProfile{VimSaveProfile, EmacsSaveProfile},
+			[]string{filepath.Join(dir, "~"), filepath.Join(dir, "##")},
+			[]string{filepath.Join(dir, "~"), filepath.Join(dir, "##")}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, CollapseSaves(tc.paths, tc.profiles, ignore))
+		})
+	}
+}
+
+type fakeMatcher struct {
+	ignored string
+}
+
+var _ watch.PathMatcher = fakeMatcher{}
+
+func (m fakeMatcher) Matches(f string) (bool, error) {
+	return f == m.ignored, nil
+}
+
+func (m fakeMatcher) MatchesEntireDir(f string) (bool, error) {
+	return false, nil
+}
@@ -0,0 +1,367 @@
+package fsevent
+
+import (
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/tilt-dev/tilt/internal/ospath"
+	"github.com/tilt-dev/tilt/internal/watch"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// ShareMonitorsEnvVar turns on sharing of filesystem monitors between watches on the
+// same roots. See NewSharedWatcherMaker.
+const ShareMonitorsEnvVar = "TILT_WATCH_SHARE_MONITORS"
+
+func shareMonitorsFromEnv() bool {
+	share, _ := strconv.ParseBool(os.Getenv(ShareMonitorsEnvVar))
+	return share
+}
+
+// NewSharedWatcherMaker creates watchers that share a filesystem monitor made by maker,
+// whenever all of a watcher's paths are under the paths of a monitor that's already
+// running. Each watcher only gets the events under its own paths that its own ignore
+// doesn't match.
+//
+// A monitor is closed once all the watchers sharing it are closed, and isn't shared any
+// more once it fails. It only ignores the paths that every watcher sharing it ignores,
+// and only skips the directories that every watcher sharing it ignores entirely. A
+// watcher only joins a monitor that hasn't skipped any directories it needs.
+func NewSharedWatcherMaker(maker WatcherMaker) WatcherMaker {
+	s := &sharedMonitors{maker: maker}
+	return s.newSub
+}
+
+type sharedMonitors struct {
+	maker WatcherMaker
+
+	mu       sync.Mutex
+	monitors []*sharedMonitor
+}
+
+func (s *sharedMonitors) newSub(paths []string, ignore watch.PathMatcher, l logger.Logger) (watch.Notify, error) {
+	if ignore == nil {
+		ignore = watch.EmptyMatcher{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var m *sharedMonitor
+	for _, candidate := range s.monitors {
+		if candidate.covers(paths) && !candidate.prunedAnyOf(paths, ignore) {
+			m = candidate
+			break
+		}
+	}
+	if m == nil {
+		m = &sharedMonitor{parent: s, paths: paths}
+		notify, err := s.maker(paths, sharedMatcher{m: m}, l)
+		if err != nil {
+			return nil, err
+		}
+		m.notify = notify
+		s.monitors = append(s.monitors, m)
+	}
+
+	sub := &sharedSub{
+		monitor: m,
+		paths:   paths,
+		ignore:  ignore,
+		events:  make(chan watch.FileEvent),
+		errors:  make(chan error),
+		done:    make(chan struct{}),
+	}
+	m.mu.Lock()
+	m.subs = append(m.subs, sub)
+	m.mu.Unlock()
+	return sub, nil
+}
+
+// remove stops sharing m with new watchers.
+func (s *sharedMonitors) remove(m *sharedMonitor) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removeLocked(m)
+}
+
+func (s *sharedMonitors) removeLocked(m *sharedMonitor) {
+	for i, candidate := range s.monitors {
+		if candidate == m {
+			s.monitors = append(s.monitors[:i], s.monitors[i+1:]...)
+			return
+		}
+	}
+}
+
+type sharedMonitor struct {
+	parent *sharedMonitors
+	paths  []string
+	notify watch.Notify
+
+	startOnce sync.Once
+	startErr  error
+	closeOnce sync.Once
+	closeErr  error
+
+	mu   sync.Mutex
+	subs []*sharedSub
+	// The directories the monitor was told to skip entirely.
+	pruned []string
+}
+
+// covers reports whether every one of paths is under the monitor's paths.
+func (m *sharedMonitor) covers(paths []string) bool {
+	for _, p := range paths {
+		if !ospath.IsChildOfOne(m.paths, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// prunedAnyOf reports whether the monitor has skipped a directory that a watcher on paths
+// with ignore needs.
+func (m *sharedMonitor) prunedAnyOf(paths []string, ignore watch.PathMatcher) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, dir := range m.pruned {
+		if !overlaps(paths, dir) {
+			continue
+		}
+		if skip, err := ignore.MatchesEntireDir(dir); err != nil || !skip {
+			return true
+		}
+	}
+	return false
+}
+
+// overlaps reports whether dir is under one of paths, or has one of them under it.
+func overlaps(paths []string, dir string) bool {
+	for _, p := range paths {
+		if ospath.IsChild(p, dir) || ospath.IsChild(dir, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *sharedMonitor) start() error {
+	m.startOnce.Do(func() {
+		m.startErr = m.notify.Start()
+		if m.startErr != nil {
+			m.parent.remove(m)
+			return
+		}
+		go m.loop()
+	})
+	return m.startErr
+}
+
+func (m *sharedMonitor) currentSubs() []*sharedSub {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*sharedSub(nil), m.subs...)
+}
+
+// loop fans out the monitor's events and errors to the watchers sharing it.
+func (m *sharedMonitor) loop() {
+	defer func() {
+		m.parent.remove(m)
+		for _, sub := range m.currentSubs() {
+			_ = sub.Close()
+		}
+	}()
+
+	errorsCh := m.notify.Errors()
+	for {
+		select {
+		case err, ok := <-errorsCh:
+			if !ok {
+				errorsCh = nil
+				continue
+			}
+			// A failed monitor can't be trusted by watchers that join later.
+			m.parent.remove(m)
+			for _, sub := range m.currentSubs() {
+				sub.sendError(err)
+			}
+		case e, ok := <-m.notify.Events():
+			if !ok {
+				return
+			}
+			for _, sub := range m.currentSubs() {
+				if sub.wants(e.Path()) {
+					sub.sendEvent(e)
+				}
+			}
+		}
+	}
+}
+
+// release removes sub from the monitor, and closes the monitor if nothing else shares it.
+func (m *sharedMonitor) release(sub *sharedSub) error {
+	// Hold the parent's lock, so that no new watcher joins a monitor that's closing.
+	m.parent.mu.Lock()
+	m.mu.Lock()
+	for i, candidate := range m.subs {
+		if candidate == sub {
+			m.subs = append(m.subs[:i], m.subs[i+1:]...)
+			break
+		}
+	}
+	empty := len(m.subs) == 0
+	m.mu.Unlock()
+	if empty {
+		m.parent.removeLocked(m)
+	}
+	m.parent.mu.Unlock()
+
+	if !empty {
+		return nil
+	}
+	m.closeOnce.Do(func() {
+		m.closeErr = m.notify.Close()
+	})
+	return m.closeErr
+}
+
+// sharedMatcher ignores the paths that every watcher sharing the monitor ignores.
+type sharedMatcher struct {
+	m *sharedMonitor
+}
+
+var _ watch.PathMatcher = sharedMatcher{}
+
+func (sm sharedMatcher) Matches(f string) (bool, error) {
+	for _, sub := range sm.m.currentSubs() {
+		if sub.wants(f) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// MatchesEntireDir skips a directory that every watcher sharing the monitor ignores
+// entirely (or doesn't watch at all), and remembers it, so that watchers that need it
+// don't join later.
+func (sm sharedMatcher) MatchesEntireDir(f string) (bool, error) {
+	subs := sm.m.currentSubs()
+	if len(subs) == 0 {
+		return false, nil
+	}
+	for _, sub := range subs {
+		if sub.needsDir(f) {
+			return false, nil
+		}
+	}
+	sm.m.mu.Lock()
+	sm.m.pruned = append(sm.m.pruned, f)
+	sm.m.mu.Unlock()
+	return true, nil
+}
+
+// sharedSub is a single watcher's view of a shared monitor.
+type sharedSub struct {
+	monitor *sharedMonitor
+	paths   []string
+
+	// Ignore matchers aren't safe for concurrent use, but both the monitor and the
+	// fan-out consult this one.
+	ignoreMu sync.Mutex
+	ignore   watch.PathMatcher
+
+	// mu is held while sending, so that the channels aren't closed mid-send.
+	mu        sync.Mutex
+	closed    bool
+	events    chan watch.FileEvent
+	errors    chan error
+	done      chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+}
+
+var _ watch.Notify = &sharedSub{}
+var _ watch.WatchCounter = &sharedSub{}
+
+func (s *sharedSub) Start() error {
+	return s.monitor.start()
+}
+
+func (s *sharedSub) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.mu.Lock()
+		s.closed = true
+		close(s.events)
+		close(s.errors)
+		s.mu.Unlock()
+		s.closeErr = s.monitor.release(s)
+	})
+	return s.closeErr
+}
+
+func (s *sharedSub) Events() chan watch.FileEvent {
+	return s.events
+}
+
+func (s *sharedSub) Errors() chan error {
+	return s.errors
+}
+
+// WatchCount is the number of OS-level watches held by the shared monitor, attributed to
+// the first watcher still sharing it, so that they're only counted once. The others hold
+// none of their own.
+func (s *sharedSub) WatchCount() int {
+	subs := s.monitor.currentSubs()
+	if len(subs) == 0 || subs[0] != s {
+		return 0
+	}
+	return watch.WatchCount(s.monitor.notify)
+}
+
+// needsDir reports whether the watcher may want events from under dir.
+func (s *sharedSub) needsDir(dir string) bool {
+	if !overlaps(s.paths, dir) {
+		return false
+	}
+	s.ignoreMu.Lock()
+	defer s.ignoreMu.Unlock()
+	skip, err := s.ignore.MatchesEntireDir(dir)
+	return err != nil || !skip
+}
+
+// wants reports whether path is under the watcher's paths and not ignored by it.
+func (s *sharedSub) wants(path string) bool {
+	if !ospath.IsChildOfOne(s.paths, path) {
+		return false
+	}
+	s.ignoreMu.Lock()
+	defer s.ignoreMu.Unlock()
+	ignored, err := s.ignore.Matches(path)
+	return err != nil || !ignored
+}
+
+func (s *sharedSub) sendEvent(e watch.FileEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.events <- e:
+	case <-s.done:
+	}
+}
+
+func (s *sharedSub) sendError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.errors <- err:
+	case <-s.done:
+	}
+}
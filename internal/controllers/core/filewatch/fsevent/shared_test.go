@@ -0,0 +1,182 @@
+package fsevent
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/watch"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+func TestSharedWatcherMaker(t *testing.T) {
+	dir := t.TempDir()
+	one := filepath.Join(dir, "sub", "one")
+	two := filepath.Join(dir, "sub", "two")
+	top := filepath.Join(dir, "top")
+
+	multi := NewFakeMultiWatcher()
+	made := 0
+	maker := NewSharedWatcherMaker(func(paths []string, ignore watch.PathMatcher, l logger.Logger) (watch.Notify, error) {
+		made++
+		return multi.NewSub(paths, ignore, l)
+	})
+
+	a, err := maker([]string{dir}, fakeMatcher{ignored: one}, nil)
+	require.NoError(t, err)
+	require.NoError(t, a.Start())
+	b, err := maker([]string{filepath.Join(dir, "sub")}, fakeMatcher{ignored: two}, nil)
+	require.NoError(t, err)
+	require.NoError(t, b.Start())
+	assert.Equal(t, 1, made, "nested paths should share a monitor")
+
+	for _, p := range []string{one, two, top} {
+		multi.RequireEmit(t, watch.NewFileEvent(p))
+	}
+	aSeen, bSeen := collectShared(t, a, b, 3)
+	assert.Equal(t, []string{two, top}, aSeen)
+	assert.Equal(t, []string{one}, bSeen)
+
+	// The monitor stays up until nothing shares it.
+	require.NoError(t, a.Close())
+	multi.RequireEmit(t, watch.NewFileEvent(one))
+	_, bSeen = collectShared(t, nil, b, 1)
+	assert.Equal(t, []string{one}, bSeen)
+
+	require.NoError(t, b.Close())
+	multi.mu.Lock()
+	monitor := multi.watchers[0]
+	multi.mu.Unlock()
+	require.Eventually(t, func() bool {
+		return !monitor.Running
+	}, time.Second, 10*time.Millisecond, "monitor was never closed")
+
+	c, err := maker([]string{dir}, watch.EmptyMatcher{}, nil)
+	require.NoError(t, err)
+	require.NoError(t, c.Start())
+	assert.Equal(t, 2, made, "a closed monitor shouldn't be shared")
+	require.NoError(t, c.Close())
+}
+
+// collectShared reads count events from a and b, which may be nil.
+func collectShared(t *testing.T, a, b watch.Notify, count int) ([]string, []string) {
+	t.Helper()
+	var aCh, bCh chan watch.FileEvent
+	if a != nil {
+		aCh = a.Events()
+	}
+	if b != nil {
+		bCh = b.Events()
+	}
+
+	var aSeen, bSeen []string
+	for len(aSeen)+len(bSeen) < count {
+		select {
+		case e := <-aCh:
+			aSeen = append(aSeen, e.Path())
+		case e := <-bCh:
+			bSeen = append(bSeen, e.Path())
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for %d events, got %v and %v", count, aSeen, bSeen)
+		}
+	}
+	return aSeen, bSeen
+}
+
+func TestSharedWatcherMaker_PrunesDirsEveryWatcherIgnores(t *testing.T) {
+	dir := t.TempDir()
+	deps := filepath.Join(dir, "node_modules")
+	src := filepath.Join(dir, "src")
+
+	multi := NewFakeMultiWatcher()
+	var matchers []watch.PathMatcher
+	maker := NewSharedWatcherMaker(func(paths []string, ignore watch.PathMatcher, l logger.Logger) (watch.Notify, error) {
+		matchers = append(matchers, ignore)
+		return multi.NewSub(paths, ignore, l)
+	})
+
+	a, err := maker([]string{dir}, dirMatcher{ignored: deps}, nil)
+	require.NoError(t, err)
+	require.NoError(t, a.Start())
+	b, err := maker([]string{dir}, dirMatcher{ignored: deps}, nil)
+	require.NoError(t, err)
+	require.NoError(t, b.Start())
+	require.Len(t, matchers, 1)
+
+	// Every watcher ignores node_modules, so the monitor skips it.
+	skip, err := matchers[0].MatchesEntireDir(deps)
+	require.NoError(t, err)
+	assert.True(t, skip)
+	skip, err = matchers[0].MatchesEntireDir(src)
+	require.NoError(t, err)
+	assert.False(t, skip)
+
+	// A watcher that needs node_modules gets its own monitor, but one that doesn't watch
+	// it can still share.
+	c, err := maker([]string{dir}, watch.EmptyMatcher{}, nil)
+	require.NoError(t, err)
+	require.NoError(t, c.Start())
+	d, err := maker([]string{src}, watch.EmptyMatcher{}, nil)
+	require.NoError(t, err)
+	require.NoError(t, d.Start())
+	assert.Len(t, matchers, 2)
+
+	// node_modules is under the new monitor's watchers, one of which needs it.
+	skip, err = matchers[1].MatchesEntireDir(deps)
+	require.NoError(t, err)
+	assert.False(t, skip)
+
+	for _, n := range []watch.Notify{a, b, c, d} {
+		require.NoError(t, n.Close())
+	}
+}
+
+func TestSharedWatcherMaker_WatchCountOnlyOnce(t *testing.T) {
+	dir := t.TempDir()
+	multi := NewFakeMultiWatcher()
+	maker := NewSharedWatcherMaker(func(paths []string, ignore watch.PathMatcher, l logger.Logger) (watch.Notify, error) {
+		n, err := multi.NewSub(paths, ignore, l)
+		return countingNotify{Notify: n, count: 7}, err
+	})
+
+	a, err := maker([]string{dir}, watch.EmptyMatcher{}, nil)
+	require.NoError(t, err)
+	require.NoError(t, a.Start())
+	b, err := maker([]string{dir}, watch.EmptyMatcher{}, nil)
+	require.NoError(t, err)
+	require.NoError(t, b.Start())
+
+	assert.Equal(t, 7, watch.WatchCount(a))
+	assert.Equal(t, 0, watch.WatchCount(b))
+
+	// The watches move over to the watchers still sharing the monitor.
+	require.NoError(t, a.Close())
+	assert.Equal(t, 7, watch.WatchCount(b))
+	require.NoError(t, b.Close())
+}
+
+// dirMatcher ignores everything under a directory.
+type dirMatcher struct {
+	ignored string
+}
+
+func (m dirMatcher) Matches(f string) (bool, error) {
+	return f == m.ignored || strings.HasPrefix(f, m.ignored+string(filepath.Separator)), nil
+}
+
+func (m dirMatcher) MatchesEntireDir(f string) (bool, error) {
+	return m.Matches(f)
+}
+
+type countingNotify struct {
+	watch.Notify
+	count int
+}
+
+func (n countingNotify) WatchCount() int {
+	return n.count
+}
@@ -0,0 +1,149 @@
+package filewatch
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tilt-dev/tilt/internal/ospath"
+	"github.com/tilt-dev/tilt/internal/watch"
+)
+
+// ignoreSummaryInterval is how often a watch logs a summary of the file events
+// its ignore rules filtered out.
+const ignoreSummaryInterval = 30 * time.Second
+
+// ignoreSummaryMinEvents is how many events need to be ignored under a directory
+// within an interval before it's worth mentioning. A few editor swap files aren't.
+const ignoreSummaryMinEvents = 100
+
+// ignoreCounter counts the file events ignored by a filesystem monitor, so that a noisy
+// process whose changes are all ignored doesn't leave the user wondering why nothing rebuilt.
+//
+// Ignored events are grouped by the directory directly under the watched path that
+// contains them (e.g., node_modules). The most recently ignored paths are also kept,
+// for FileWatchStatus.RecentlyIgnored.
+type ignoreCounter struct {
+	matcher watch.PathMatcher
+	paths   []string
+
+	mu            sync.Mutex
+	counts        map[string]int
+	recent        []string
+	recentChanged bool
+}
+
+var _ watch.PathMatcher = &ignoreCounter{}
+
+// newIgnoreCounter wraps matcher. recent seeds the recently ignored paths, so that
+// they survive a restart of the monitor.
+func newIgnoreCounter(matcher watch.PathMatcher, paths []string, recent []string) *ignoreCounter {
+	return &ignoreCounter{
+		matcher: matcher,
+		paths:   paths,
+		counts:  make(map[string]int),
+		recent:  append([]string(nil), recent...),
+	}
+}
+
+func (c *ignoreCounter) Matches(f string) (bool, error) {
+	matches, err := c.matcher.Matches(f)
+	if err == nil && matches {
+		dir := c.groupDir(f)
+		c.mu.Lock()
+		c.counts[dir]++
+		c.addRecent(f)
+		c.mu.Unlock()
+	}
+	return matches, err
+}
+
+// addRecent moves f to the end of the recently ignored paths, dropping the oldest
+// if there are too many.
+//
+// mu must be held before calling.
+func (c *ignoreCounter) addRecent(f string) {
+	if n := len(c.recent); n > 0 && c.recent[n-1] == f {
+		return
+	}
+	if i := slices.Index(c.recent, f); i != -1 {
+		c.recent = slices.Delete(c.recent, i, i+1)
+	}
+	c.recent = append(c.recent, f)
+	if len(c.recent) > MaxRecentlyIgnored {
+		c.recent = c.recent[len(c.recent)-MaxRecentlyIgnored:]
+	}
+	c.recentChanged = true
+}
+
+// recentlyIgnored returns the most recently ignored paths, oldest first.
+func (c *ignoreCounter) recentlyIgnored() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.recent...)
+}
+
+// takeRecentChanged reports whether any paths have been ignored since it was last called.
+func (c *ignoreCounter) takeRecentChanged() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	changed := c.recentChanged
+	c.recentChanged = false
+	return changed
+}
+
+func (c *ignoreCounter) MatchesEntireDir(f string) (bool, error) {
+	return c.matcher.MatchesEntireDir(f)
+}
+
+// groupDir returns the directory that an ignored file is reported under.
+func (c *ignoreCounter) groupDir(f string) string {
+	best := ""
+	bestRel := ""
+	for _, p := range c.paths {
+		rel, ok := ospath.Child(p, f)
+		if ok && len(p) > len(best) {
+			best, bestRel = p, rel
+		}
+	}
+	if best == "" {
+		return filepath.Dir(f)
+	}
+	first, _, nested := strings.Cut(bestRel, string(filepath.Separator))
+	if !nested {
+		return best
+	}
+	return filepath.Join(best, first)
+}
+
+// summarize describes the directories where enough events were ignored since the
+// last summary, busiest first, and starts counting again.
+func (c *ignoreCounter) summarize() []string {
+	c.mu.Lock()
+	counts := c.counts
+	c.counts = make(map[string]int)
+	c.mu.Unlock()
+
+	dirs := make([]string, 0, len(counts))
+	for dir, n := range counts {
+		if n >= ignoreSummaryMinEvents {
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		if counts[dirs[i]] != counts[dirs[j]] {
+			return counts[dirs[i]] > counts[dirs[j]]
+		}
+		return dirs[i] < dirs[j]
+	})
+
+	result := make([]string, len(dirs))
+	for i, dir := range dirs {
+		result[i] = fmt.Sprintf("ignored %d events under %s", counts[dir], dir)
+	}
+	return result
+}
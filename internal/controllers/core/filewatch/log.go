@@ -0,0 +1,104 @@
+package filewatch
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/internal/watch"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// FieldNameFileWatch is the log field with the name of the FileWatch a log line is about.
+const FieldNameFileWatch = "fileWatch"
+
+// jsonLogEntry is a log line in store.LogFormatJSON.
+type jsonLogEntry struct {
+	Level      string   `json:"level"`
+	FileWatch  string   `json:"fileWatch"`
+	Message    string   `json:"msg"`
+	EventCount int      `json:"eventCount,omitempty"`
+	Paths      []string `json:"paths,omitempty"`
+}
+
+// logPrefix is what the lines logged about the named FileWatch start with, given its
+// Spec.LogPrefix.
+func logPrefix(name, prefix string) string {
+	if prefix != "" {
+		return prefix
+	}
+	return "filewatch " + name
+}
+
+// watchLogger returns a logger for log lines about the named FileWatch.
+//
+// When the store is in JSON mode, each line is written as a JSON object.
+func (c *Controller) watchLogger(ctx context.Context, name string) logger.Logger {
+	l := logger.Get(ctx).WithFields(logger.Fields{FieldNameFileWatch: name})
+	if c.logFormat() != store.LogFormatJSON {
+		return l
+	}
+	return logger.NewFuncLogger(false, l.Level(), func(level logger.Level, _ logger.Fields, b []byte) error {
+		writeJSONLog(l, level, jsonLogEntry{
+			FileWatch: name,
+			Message:   strings.TrimSuffix(string(b), "\n"),
+		})
+		return nil
+	})
+}
+
+// logFileChanges logs a batch of file changes from the monitor.
+//
+// Only JSON logs include them. In text logs, the builds they trigger are what the user cares about.
+func (c *Controller) logFileChanges(ctx context.Context, name string, fsEvents []watch.FileEvent) {
+	if c.logFormat() != store.LogFormatJSON {
+		return
+	}
+
+	seen := make(map[string]bool, len(fsEvents))
+	var paths []string
+	for _, e := range fsEvents {
+		if !seen[e.Path()] {
+			seen[e.Path()] = true
+			paths = append(paths, e.Path())
+		}
+	}
+	l := logger.Get(ctx).WithFields(logger.Fields{FieldNameFileWatch: name})
+	writeJSONLog(l, logger.InfoLvl, jsonLogEntry{
+		FileWatch:  name,
+		Message:    "files changed",
+		EventCount: len(fsEvents),
+		Paths:      paths,
+	})
+}
+
+func (c *Controller) logFormat() store.LogFormat {
+	state := c.Store.RLockState()
+	defer c.Store.RUnlockState()
+	return state.LogFormat
+}
+
+func writeJSONLog(l logger.Logger, level logger.Level, entry jsonLogEntry) {
+	entry.Level = levelName(level)
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	l.Write(level, append(line, '\n'))
+}
+
+func levelName(level logger.Level) string {
+	switch level {
+	case logger.DebugLvl:
+		return "debug"
+	case logger.VerboseLvl:
+		return "verbose"
+	case logger.WarnLvl:
+		return "warn"
+	case logger.ErrorLvl:
+		return "error"
+	default:
+		return "info"
+	}
+}
@@ -0,0 +1,106 @@
+package filewatch
+
+import (
+	"time"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// Metrics about how much churn each FileWatch sees.
+//
+// They're registered with the apiserver's registry, so they're exported on Tilt's /metrics endpoint.
+var (
+	fileEventsReceived = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Namespace:      "tilt",
+			Subsystem:      "filewatch",
+			Name:           "events_received_total",
+			Help:           "Number of file change events delivered by the filesystem monitor.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"filewatch"},
+	)
+
+	fileEventsRecorded = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Namespace:      "tilt",
+			Subsystem:      "filewatch",
+			Name:           "events_recorded_total",
+			Help:           "Number of distinct file changes recorded in FileWatch status, after filtering.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"filewatch"},
+	)
+
+	activeWatches = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Namespace:      "tilt",
+			Subsystem:      "filewatch",
+			Name:           "active_watches",
+			Help:           "Number of FileWatches with a filesystem monitor held by the controller.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"namespace"},
+	)
+
+	fileEventBatchSize = metrics.NewHistogramVec(
+		&metrics.HistogramOpts{
+			Namespace:      "tilt",
+			Subsystem:      "filewatch",
+			Name:           "batch_size",
+			Help:           "Number of files in each FileEvent recorded in FileWatch status.",
+			Buckets:        metrics.ExponentialBuckets(1, 4, 8),
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"filewatch"},
+	)
+
+	fileEventWriteLatency = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Namespace: "tilt",
+			Subsystem: "filewatch",
+			Name:      "last_event_latency_seconds",
+			Help: "Time between a file change being received and the FileEvent for it being written to " +
+				"FileWatch status, for the oldest new event in the last status write.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"filewatch"},
+	)
+)
+
+func init() {
+	legacyregistry.MustRegister(fileEventsReceived, fileEventsRecorded, fileEventBatchSize, activeWatches, fileEventWriteLatency)
+}
+
+// recordEventMetrics updates the metrics for a batch of events before it's appended to the status.
+func recordEventMetrics(name string, received int, events []v1alpha1.FileEvent) {
+	fileEventsReceived.WithLabelValues(name).Add(float64(received))
+	for _, event := range events {
+		fileEventsRecorded.WithLabelValues(name).Add(float64(len(event.SeenFiles)))
+		fileEventBatchSize.WithLabelValues(name).Observe(float64(len(event.SeenFiles)))
+	}
+}
+
+// recordWriteLatencyMetrics updates the latency metric after a status write, if it added any events.
+//
+// The events are in chronological order, so the first one that wasn't in the old status has
+// waited the longest.
+func recordWriteLatencyMetrics(name string, oldStatus, newStatus *v1alpha1.FileWatchStatus) {
+	for _, event := range newStatus.FileEvents {
+		if event.Seq > oldStatus.TotalEventCount {
+			fileEventWriteLatency.WithLabelValues(name).Set(time.Since(event.Time.Time).Seconds())
+			return
+		}
+	}
+}
+
+// deleteEventMetrics removes the metrics for a FileWatch that no longer exists.
+func deleteEventMetrics(name string) {
+	fileEventsReceived.DeleteLabelValues(name)
+	fileEventsRecorded.DeleteLabelValues(name)
+	fileEventBatchSize.DeleteLabelValues(name)
+	fileEventWriteLatency.DeleteLabelValues(name)
+}
@@ -0,0 +1,126 @@
+package filewatch
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/tilt-dev/tilt/internal/watch"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// fileID identifies a file regardless of its path, so it can be followed through renames.
+//
+// A rename keeps the device, inode, size and modification time, but a deleted file's
+// inode may be handed straight to a new one, so all four must match for a move.
+type fileID struct {
+	dev   uint64
+	ino   uint64
+	size  int64
+	mtime int64
+}
+
+// listFileIDs returns the IDs of the files (but not directories) under the watched paths,
+// keyed by path, skipping those that the matcher ignores. For TrackMoves.
+func listFileIDs(paths []string, m watch.PathMatcher) map[string]fileID {
+	result := make(map[string]fileID)
+	for _, root := range paths {
+		_ = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if entry.IsDir() {
+				if path != root {
+					if skip, err := m.MatchesEntireDir(path); err == nil && skip {
+						return filepath.SkipDir
+					}
+				}
+				return nil
+			}
+			if ignored, err := m.Matches(path); err != nil || ignored {
+				return nil
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return nil
+			}
+			if id, ok := fileIDOf(info); ok {
+				result[path] = id
+			}
+			return nil
+		})
+	}
+	return result
+}
+
+// checkMoves matches the paths that no longer exist against the ones that do and have the
+// same file ID as when they were last seen, and returns the moves found, keyed by new path. The known file IDs are
+// updated to match.
+//
+// mu must be held before calling.
+func (w *watcher) checkMoves(paths []string, exists map[string]bool) map[string]v1alpha1.FileMove {
+	if w.fileIDs == nil {
+		return nil
+	}
+	gone := make(map[fileID]string)
+	for _, path := range paths {
+		if exists[path] {
+			continue
+		}
+		if id, ok := w.fileIDs[path]; ok {
+			gone[id] = path
+			delete(w.fileIDs, path)
+		}
+	}
+
+	var moves map[string]v1alpha1.FileMove
+	for _, path := range paths {
+		if !exists[path] {
+			continue
+		}
+		info, err := os.Lstat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		id, ok := fileIDOf(info)
+		if !ok {
+			continue
+		}
+		w.fileIDs[path] = id
+		oldPath, ok := gone[id]
+		if !ok {
+			continue
+		}
+		delete(gone, id)
+		if moves == nil {
+			moves = make(map[string]v1alpha1.FileMove)
+		}
+		moves[path] = v1alpha1.FileMove{OldPath: oldPath, NewPath: path}
+	}
+	return moves
+}
+
+// mergeMoves adds the moves in src to dst, joining a move in dst that ends where one in
+// src starts into a single move, and dropping files that were moved back where they started.
+func mergeMoves(dst, src []v1alpha1.FileMove) []v1alpha1.FileMove {
+	for _, move := range src {
+		joined := false
+		for i := range dst {
+			if dst[i].NewPath == move.OldPath {
+				dst[i].NewPath = move.NewPath
+				joined = true
+				break
+			}
+		}
+		if !joined {
+			dst = append(dst, move)
+		}
+	}
+	var result []v1alpha1.FileMove
+	for _, move := range dst {
+		if move.OldPath != move.NewPath {
+			result = append(result, move)
+		}
+	}
+	return result
+}
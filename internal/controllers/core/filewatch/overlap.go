@@ -0,0 +1,72 @@
+package filewatch
+
+import (
+	"cmp"
+	"context"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/tilt-dev/tilt/internal/ospath"
+)
+
+// DetectOverlapsEnvVar turns on warnings for FileWatches whose watched paths overlap.
+//
+// Overlapping watches each hold their own OS-level watches for the shared directories,
+// which can use up the OS limit on large trees.
+const DetectOverlapsEnvVar = "TILT_WATCH_DETECT_OVERLAPS"
+
+func detectOverlapsFromEnv() bool {
+	detect, _ := strconv.ParseBool(os.Getenv(DetectOverlapsEnvVar))
+	return detect
+}
+
+// warnOverlaps logs a warning for each active watch with a watched path that overlaps
+// one of paths, the resolved watched paths of w.
+//
+// Caller must hold c.mu.
+func (c *Controller) warnOverlaps(ctx context.Context, w *watcher, paths []string) {
+	for _, other := range c.sortedWatches() {
+		if other.name == w.name {
+			continue
+		}
+		other.mu.Lock()
+		otherPaths := other.status.WatchedPaths
+		other.mu.Unlock()
+
+		if p, q, ok := overlap(paths, otherPaths); ok {
+			c.watchLogger(ctx, w.name.Name).Warnf(
+				"%s: watched path %s overlaps %s, watched by filewatch %s. "+
+					"Both hold OS-level watches for the files they share", logPrefix(w.name.Name, w.spec.LogPrefix), p, q, other.name.Name)
+		}
+	}
+}
+
+// overlap returns the first pair of paths where one contains the other.
+func overlap(paths, otherPaths []string) (string, string, bool) {
+	for _, p := range paths {
+		for _, q := range otherPaths {
+			if ospath.IsChild(p, q) || ospath.IsChild(q, p) {
+				return p, q, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// sortedWatches returns the active watches, ordered by name.
+//
+// Caller must hold c.mu.
+func (c *Controller) sortedWatches() []*watcher {
+	result := make([]*watcher, 0, len(c.targetWatches))
+	for _, w := range c.targetWatches {
+		result = append(result, w)
+	}
+	slices.SortFunc(result, func(a, b *watcher) int {
+		return cmp.Or(
+			strings.Compare(a.name.Namespace, b.name.Namespace),
+			strings.Compare(a.name.Name, b.name.Name))
+	})
+	return result
+}
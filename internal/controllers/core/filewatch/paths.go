@@ -0,0 +1,825 @@
+package filewatch
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/tilt-dev/tilt/internal/dockerignore"
+	"github.com/tilt-dev/tilt/internal/ignore"
+	"github.com/tilt-dev/tilt/internal/ospath"
+	"github.com/tilt-dev/tilt/internal/watch"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// isGlob returns true if a WatchedPaths entry contains glob metacharacters.
+func isGlob(p string) bool {
+	return strings.ContainsAny(p, "*?[")
+}
+
+// globRoot truncates a glob to the longest leading directory without any glob metacharacters.
+//
+// This is the directory that needs to be (recursively) watched to see every file the glob might match.
+func globRoot(p string) string {
+	parts := strings.Split(filepath.ToSlash(p), "/")
+	for i, part := range parts {
+		if isGlob(part) {
+			root := strings.Join(parts[:i], "/")
+			if root == "" {
+				return string(filepath.Separator)
+			}
+			return filepath.FromSlash(root)
+		}
+	}
+	return p
+}
+
+// resolveWatchedPaths converts the WatchedPaths from a FileWatchSpec into the paths that the filesystem monitor
+// should watch.
+//
+// Glob entries (e.g., `src/**/*.go`) are replaced by their root directory, so that files created later that match
+// the glob are seen without needing to update the spec. The returned matcher ignores any paths under those roots
+// that don't match a glob; it's nil if there are no globs.
+func resolveWatchedPaths(watchedPaths []string) ([]string, watch.PathMatcher, error) {
+	var paths, literals, globs []string
+	for _, p := range watchedPaths {
+		if !isGlob(p) {
+			paths = append(paths, p)
+			literals = append(literals, p)
+			continue
+		}
+		paths = append(paths, globRoot(p))
+		globs = append(globs, p)
+	}
+
+	if len(globs) == 0 {
+		return paths, nil, nil
+	}
+
+	m, err := dockerignore.NewDockerPatternMatcher(string(filepath.Separator), globs)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "invalid glob")
+	}
+	return paths, globMatcher{literals: literals, globs: m}, nil
+}
+
+// globMatcher ignores files under the root of a glob that do not match it (unless they're
+// also under a non-glob watched path).
+type globMatcher struct {
+	literals []string
+	globs    watch.PathMatcher
+}
+
+var _ watch.PathMatcher = globMatcher{}
+
+func (m globMatcher) Matches(f string) (bool, error) {
+	for _, p := range m.literals {
+		if ospath.IsChild(p, f) {
+			return false, nil
+		}
+	}
+	matches, err := m.globs.Matches(f)
+	if err != nil {
+		return false, err
+	}
+	return !matches, nil
+}
+
+func (m globMatcher) MatchesEntireDir(f string) (bool, error) {
+	// A directory might contain files that match a glob even if the directory itself doesn't,
+	// so it always needs to be watched.
+	return false, nil
+}
+
+// depthMatcher ignores paths more than maxDepth levels below all of the watched roots.
+//
+// Directories at maxDepth are skipped entirely, so that the filesystem monitor doesn't
+// recurse into (and add watches for) trees whose contents would be ignored anyway.
+type depthMatcher struct {
+	roots    []string
+	maxDepth int
+}
+
+var _ watch.PathMatcher = depthMatcher{}
+
+func newDepthMatcher(roots []string, maxDepth int) depthMatcher {
+	absRoots := make([]string, 0, len(roots))
+	for _, r := range roots {
+		p, err := filepath.Abs(r)
+		if err != nil {
+			continue
+		}
+		absRoots = append(absRoots, p)
+	}
+	return depthMatcher{roots: absRoots, maxDepth: maxDepth}
+}
+
+// depth returns how many levels f is below the closest root that contains it,
+// or false if f isn't under any root.
+func (m depthMatcher) depth(f string) (int, bool) {
+	depth, found := 0, false
+	for _, r := range m.roots {
+		rel, ok := ospath.Child(r, f)
+		if !ok {
+			continue
+		}
+		d := 0
+		if rel != "." {
+			d = len(strings.Split(rel, string(filepath.Separator)))
+		}
+		if !found || d < depth {
+			depth, found = d, true
+		}
+	}
+	return depth, found
+}
+
+func (m depthMatcher) Matches(f string) (bool, error) {
+	d, ok := m.depth(f)
+	return ok && d > m.maxDepth, nil
+}
+
+func (m depthMatcher) MatchesEntireDir(f string) (bool, error) {
+	d, ok := m.depth(f)
+	return ok && d >= m.maxDepth, nil
+}
+
+// exceptHiddenMatcher matches what matcher does, except for files and directories whose
+// names start with a dot.
+type exceptHiddenMatcher struct {
+	matcher watch.PathMatcher
+}
+
+var _ watch.PathMatcher = exceptHiddenMatcher{}
+
+func (m exceptHiddenMatcher) Matches(f string) (bool, error) {
+	if strings.HasPrefix(filepath.Base(f), ".") {
+		return false, nil
+	}
+	return m.matcher.Matches(f)
+}
+
+func (m exceptHiddenMatcher) MatchesEntireDir(f string) (bool, error) {
+	if strings.HasPrefix(filepath.Base(f), ".") {
+		return false, nil
+	}
+	return m.matcher.MatchesEntireDir(f)
+}
+
+// collapseSubtrees replaces each path under one of dirs with the closest such dir, keeping
+// the order they were first seen in and dropping duplicates.
+func collapseSubtrees(paths []string, dirs []string) []string {
+	if len(dirs) == 0 {
+		return paths
+	}
+	absDirs := make([]string, 0, len(dirs))
+	for _, d := range dirs {
+		if abs, err := filepath.Abs(d); err == nil {
+			absDirs = append(absDirs, abs)
+		}
+	}
+
+	seen := make(map[string]bool, len(paths))
+	result := make([]string, 0, len(paths))
+	for _, p := range paths {
+		collapsed := p
+		closest := -1
+		for _, d := range absDirs {
+			if _, ok := ospath.Child(d, p); ok && len(d) > closest {
+				collapsed, closest = d, len(d)
+			}
+		}
+		if !seen[collapsed] {
+			seen[collapsed] = true
+			result = append(result, collapsed)
+		}
+	}
+	return result
+}
+
+// relativePath returns path relative to base, or path itself if it can't be made relative
+// (e.g., it's on a different volume).
+func relativePath(base, path string) string {
+	absBase, err := filepath.Abs(base)
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(absBase, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
+
+// rewritePath replaces the rw.From prefix of path with rw.To. Paths that aren't under
+// rw.From are returned as-is.
+func rewritePath(rw *v1alpha1.FileWatchPathRewrite, path string) string {
+	from, err := filepath.Abs(rw.From)
+	if err != nil {
+		return path
+	}
+	rel, ok := ospath.Child(from, path)
+	if !ok {
+		return path
+	}
+	return filepath.Join(rw.To, rel)
+}
+
+// rewriteEventPaths applies rw to the paths in e, and recomputes its RelSeenFiles from the
+// rewritten paths, with relativeTo rewritten the same way.
+func rewriteEventPaths(e *v1alpha1.FileEvent, rw *v1alpha1.FileWatchPathRewrite, relativeTo string) {
+	for _, paths := range [][]string{e.SeenFiles, e.DeletedFiles, e.CreatedDirectories, e.DeletedDirectories} {
+		for i, p := range paths {
+			paths[i] = rewritePath(rw, p)
+		}
+	}
+	for i := range e.SymlinkTargetChanges {
+		change := &e.SymlinkTargetChanges[i]
+		change.Path = rewritePath(rw, change.Path)
+		change.OldTarget = rewritePath(rw, change.OldTarget)
+		change.NewTarget = rewritePath(rw, change.NewTarget)
+	}
+	for i := range e.ArchiveChanges {
+		e.ArchiveChanges[i].Path = rewritePath(rw, e.ArchiveChanges[i].Path)
+	}
+	for i := range e.Moves {
+		e.Moves[i].OldPath = rewritePath(rw, e.Moves[i].OldPath)
+		e.Moves[i].NewPath = rewritePath(rw, e.Moves[i].NewPath)
+	}
+	if relativeTo != "" {
+		base, err := filepath.Abs(relativeTo)
+		if err == nil {
+			base = rewritePath(rw, base)
+		}
+		for i, p := range e.SeenFiles {
+			e.RelSeenFiles[i] = relativePath(base, p)
+		}
+	}
+}
+
+// parsePatterns splits newline-delimited ignore patterns, skipping blank lines and comments.
+func parsePatterns(s string) []string {
+	var patterns []string
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns
+}
+
+// rootIgnores anchors the patterns and regexes of ignores without a BasePath to each of the watched paths
+// (or, for globs, their roots, and for files, their directories).
+//
+// A GitignoreFile is anchored to its own directory, so it stays on the original def. A def
+// without a BasePath that's left with nothing to match (e.g., because its PatternsConfigMap
+// is missing or empty) is dropped, since an empty BasePath would ignore the current directory.
+func rootIgnores(ignores []v1alpha1.IgnoreDef, watchedPaths []string) []v1alpha1.IgnoreDef {
+	var roots []string
+	for _, p := range watchedPaths {
+		if isGlob(p) {
+			p = globRoot(p)
+		} else if info, err := os.Stat(p); err == nil && !info.IsDir() {
+			p = filepath.Dir(p)
+		}
+		if !slices.Contains(roots, p) {
+			roots = append(roots, p)
+		}
+	}
+
+	var result []v1alpha1.IgnoreDef
+	for _, def := range ignores {
+		if def.BasePath != "" {
+			result = append(result, def)
+			continue
+		}
+		if def.GitignoreFile != "" {
+			gitignoreDef := def
+			gitignoreDef.Patterns = nil
+			gitignoreDef.Regex = nil
+			result = append(result, gitignoreDef)
+		}
+		if len(def.Patterns) == 0 && len(def.Regex) == 0 {
+			continue
+		}
+		for _, root := range roots {
+			rooted := def
+			rooted.BasePath = root
+			rooted.GitignoreFile = ""
+			rooted.Patterns = append([]string(nil), def.Patterns...)
+			rooted.Regex = append([]string(nil), def.Regex...)
+			result = append(result, rooted)
+		}
+	}
+	return result
+}
+
+// checkIgnoreRegexes reports the first regex in ignores that doesn't compile.
+func checkIgnoreRegexes(ignores []v1alpha1.IgnoreDef) error {
+	for i, def := range ignores {
+		if _, err := ignore.CompileRegexes(def.Regex, def.CaseInsensitive); err != nil {
+			return errors.Wrapf(err, "ignores[%d]", i)
+		}
+	}
+	return nil
+}
+
+// gitignoreFiles returns the absolute paths of all the gitignore files referenced by the ignores.
+func gitignoreFiles(ignores []v1alpha1.IgnoreDef) []string {
+	var result []string
+	for _, ignore := range ignores {
+		if ignore.GitignoreFile == "" {
+			continue
+		}
+		p, err := filepath.Abs(ignore.GitignoreFile)
+		if err != nil {
+			continue
+		}
+		result = append(result, p)
+	}
+	return result
+}
+
+// countIgnoreRules counts the ignore rules of spec by source.
+//
+// configMapPatterns is the number of patterns resolveIgnores read from ConfigMaps, and
+// ignoreFiles are the gitignore files from gitignoreFiles. A file that can't be read
+// contributes no rules, just like it doesn't ignore anything.
+func countIgnoreRules(spec v1alpha1.FileWatchSpec, configMapPatterns int32, ignoreFiles []string) *v1alpha1.IgnoreRuleCounts {
+	counts := &v1alpha1.IgnoreRuleCounts{File: configMapPatterns}
+	for _, def := range spec.Ignores {
+		n := len(def.Patterns) + len(def.Regex)
+		if n == 0 && def.GitignoreFile == "" && def.PatternsConfigMap == nil {
+			// Ignores everything under its BasePath.
+			n = 1
+		}
+		counts.Inline += int32(n)
+	}
+	for _, p := range ignoreFiles {
+		f, err := os.Open(p)
+		if err != nil {
+			continue
+		}
+		patterns, err := ignore.ReadGitignorePatterns(f)
+		_ = f.Close()
+		if err == nil {
+			counts.File += int32(len(patterns))
+		}
+	}
+	if !spec.DisableEphemeralIgnores {
+		counts.Ephemeral = int32(len(ignore.EphemeralPatterns))
+	}
+	return counts
+}
+
+// watchIgnoreFiles makes sure that the filesystem monitor sees changes to gitignore files, so that
+// the ignore rules can be re-read when they change.
+//
+// It returns the paths and matcher to give to the monitor, and the set of ignore files that were only
+// added for this purpose; changes to them should not be reported as file events.
+func watchIgnoreFiles(paths []string, m watch.PathMatcher, ignoreFiles []string) ([]string, watch.PathMatcher, map[string]bool) {
+	if len(ignoreFiles) == 0 {
+		return paths, m, nil
+	}
+
+	hidden := make(map[string]bool)
+	watchedPaths := paths
+	for _, f := range ignoreFiles {
+		if !isWatched(watchedPaths, m, f) {
+			hidden[f] = true
+			paths = append(paths, f)
+		}
+	}
+	return paths, ignoreFileMatcher{files: ignoreFiles, matcher: m}, hidden
+}
+
+// isWatched returns true if the monitor would report changes to f.
+func isWatched(paths []string, m watch.PathMatcher, f string) bool {
+	for _, p := range paths {
+		if ospath.IsChild(p, f) {
+			ignored, err := m.Matches(f)
+			return err == nil && !ignored
+		}
+	}
+	return false
+}
+
+// ignoreFileMatcher never ignores gitignore files (or the directories that contain them).
+type ignoreFileMatcher struct {
+	files   []string
+	matcher watch.PathMatcher
+}
+
+var _ watch.PathMatcher = ignoreFileMatcher{}
+
+func (m ignoreFileMatcher) Matches(f string) (bool, error) {
+	for _, p := range m.files {
+		if p == f {
+			return false, nil
+		}
+	}
+	return m.matcher.Matches(f)
+}
+
+func (m ignoreFileMatcher) MatchesEntireDir(f string) (bool, error) {
+	for _, p := range m.files {
+		if ospath.IsChild(f, p) {
+			return false, nil
+		}
+	}
+	return m.matcher.MatchesEntireDir(f)
+}
+
+// resolveSymlinks follows symlinks in the paths the monitor is subscribed to, for reporting on the status.
+//
+// Paths that don't exist yet are reported as-is.
+func resolveSymlinks(paths []string) []string {
+	result := make([]string, 0, len(paths))
+	seen := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		if resolved, err := filepath.EvalSymlinks(p); err == nil {
+			p = resolved
+		}
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		result = append(result, p)
+	}
+	return result
+}
+
+// mountPointsUnder returns the mount points below the watched paths that aren't entirely ignored,
+// so that monitors that stop at mount boundaries can watch them explicitly.
+//
+// Detection is best-effort: if it fails, the watch continues without them.
+func mountPointsUnder(ctx context.Context, paths []string, m watch.PathMatcher) []string {
+	mounts, err := watch.MountPointsUnder(paths)
+	if err != nil {
+		logger.Get(ctx).Debugf("filewatch: detecting mount points: %v", err)
+		return nil
+	}
+
+	var result []string
+	for _, mount := range mounts {
+		if skip, err := m.MatchesEntireDir(mount); err == nil && skip {
+			continue
+		}
+		result = append(result, mount)
+	}
+	return result
+}
+
+// listDirectories returns the set of directories under the watched paths (including the paths
+// themselves) that the matcher doesn't ignore entirely.
+func listDirectories(paths []string, m watch.PathMatcher) map[string]bool {
+	result := make(map[string]bool)
+	for _, root := range paths {
+		_ = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil || !entry.IsDir() {
+				return nil
+			}
+			if path != root {
+				if skip, err := m.MatchesEntireDir(path); err == nil && skip {
+					return filepath.SkipDir
+				}
+			}
+			result[path] = true
+			return nil
+		})
+	}
+	return result
+}
+
+// unreadableDirs returns the directories under the watched paths (but not the paths
+// themselves) that can't be read because of their permissions, skipping those that the
+// matcher ignores entirely.
+func unreadableDirs(paths []string, m watch.PathMatcher) []string {
+	var result []string
+	for _, root := range paths {
+		_ = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if path != root && entry != nil && entry.IsDir() && os.IsPermission(err) {
+					result = append(result, path)
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !entry.IsDir() {
+				return nil
+			}
+			if path != root {
+				if skip, err := m.MatchesEntireDir(path); err == nil && skip {
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		})
+	}
+	sort.Strings(result)
+	return slices.Compact(result)
+}
+
+// skipDirsMatcher ignores everything under dirs, along with whatever matcher ignores.
+type skipDirsMatcher struct {
+	dirs    []string
+	matcher watch.PathMatcher
+}
+
+var _ watch.PathMatcher = skipDirsMatcher{}
+
+func (m skipDirsMatcher) Matches(f string) (bool, error) {
+	if ospath.IsChildOfOne(m.dirs, f) {
+		return true, nil
+	}
+	return m.matcher.Matches(f)
+}
+
+func (m skipDirsMatcher) MatchesEntireDir(f string) (bool, error) {
+	if ospath.IsChildOfOne(m.dirs, f) {
+		return true, nil
+	}
+	return m.matcher.MatchesEntireDir(f)
+}
+
+// readSymlinkTargets returns the targets of the paths that are symlinks, keyed by symlink path.
+func readSymlinkTargets(paths []string) map[string]string {
+	targets := make(map[string]string)
+	for _, p := range paths {
+		if target := symlinkTarget(p); target != "" {
+			targets[p] = target
+		}
+	}
+	return targets
+}
+
+// symlinkTarget returns the absolute path that link points to, or "" if it isn't a symlink.
+func symlinkTarget(link string) string {
+	target, err := os.Readlink(link)
+	if err != nil {
+		return ""
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(link), target)
+	}
+	return target
+}
+
+// watchSymlinkTargets makes sure that the filesystem monitor sees watched symlinks being re-pointed.
+//
+// The monitor follows a watched symlink to its target, so it won't see changes to the link itself.
+// Instead, the directory containing the link is watched, ignoring everything in it except for the
+// link and the other watched paths.
+func watchSymlinkTargets(paths []string, m watch.PathMatcher, links map[string]string) ([]string, watch.PathMatcher) {
+	if len(links) == 0 {
+		return paths, m
+	}
+
+	watched := paths
+	var parents []string
+	seen := make(map[string]bool)
+	for link := range links {
+		parent := filepath.Dir(link)
+		covered := false
+		for _, p := range watched {
+			if p != link && ospath.IsChild(p, link) {
+				covered = true
+			}
+		}
+		if !covered && !seen[parent] {
+			seen[parent] = true
+			parents = append(parents, parent)
+		}
+	}
+	if len(parents) == 0 {
+		return paths, m
+	}
+	sort.Strings(parents)
+
+	result := append(append([]string{}, watched...), parents...)
+	return result, symlinkParentMatcher{links: links, parents: parents, watched: watched, matcher: m}
+}
+
+// symlinkParentMatcher ignores everything in the directories added by watchSymlinkTargets,
+// except for the symlinks themselves and the watched paths.
+type symlinkParentMatcher struct {
+	links   map[string]string
+	parents []string
+	watched []string
+	matcher watch.PathMatcher
+}
+
+var _ watch.PathMatcher = symlinkParentMatcher{}
+
+func (m symlinkParentMatcher) Matches(f string) (bool, error) {
+	if _, ok := m.links[f]; ok {
+		return false, nil
+	}
+	if ospath.IsChildOfOne(m.parents, f) && !ospath.IsChildOfOne(m.watched, f) {
+		return true, nil
+	}
+	return m.matcher.Matches(f)
+}
+
+func (m symlinkParentMatcher) MatchesEntireDir(f string) (bool, error) {
+	if ospath.IsChildOfOne(m.parents, f) && !ospath.IsChildOfOne(m.watched, f) {
+		for _, p := range m.watched {
+			if ospath.IsChild(f, p) {
+				return m.matcher.MatchesEntireDir(f)
+			}
+		}
+		return true, nil
+	}
+	return m.matcher.MatchesEntireDir(f)
+}
+
+// followSymlinks finds symlinked directories under the watched paths, and returns the real
+// directories they point to, keyed by the path of the symlink. Directories the matcher ignores
+// entirely are not searched.
+//
+// Targets are searched for symlinks too. To break cycles, a real directory is only followed if
+// it isn't already being watched.
+func followSymlinks(paths []string, m watch.PathMatcher) map[string]string {
+	links := make(map[string]string)
+	var watched []string
+	for _, p := range paths {
+		if real, err := filepath.EvalSymlinks(p); err == nil {
+			watched = append(watched, real)
+		}
+	}
+
+	var follow func(link string)
+	follow = func(link string) {
+		real, err := filepath.EvalSymlinks(link)
+		if err != nil || ospath.IsChildOfOne(watched, real) {
+			return
+		}
+		if info, err := os.Stat(real); err != nil || !info.IsDir() {
+			return
+		}
+		links[link] = real
+		watched = append(watched, real)
+		walkSymlinks(real, m, follow)
+	}
+
+	for _, p := range paths {
+		info, err := os.Lstat(p)
+		if err != nil {
+			continue
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			walkSymlinks(p, m, follow)
+			continue
+		}
+
+		// The monitor won't descend into a watched path that's itself a symlink.
+		real, err := filepath.EvalSymlinks(p)
+		if err != nil {
+			continue
+		}
+		if info, err := os.Stat(real); err == nil && info.IsDir() {
+			links[p] = real
+			walkSymlinks(real, m, follow)
+		}
+	}
+	return links
+}
+
+// symlinkMatcher applies a matcher to paths under followed symlinks as if they were
+// under the symlink, so that ignores written against the watched paths still apply.
+type symlinkMatcher struct {
+	links   map[string]string
+	matcher watch.PathMatcher
+}
+
+var _ watch.PathMatcher = symlinkMatcher{}
+
+func (m symlinkMatcher) Matches(f string) (bool, error) {
+	return m.matcher.Matches(symlinkPath(m.links, f))
+}
+
+func (m symlinkMatcher) MatchesEntireDir(f string) (bool, error) {
+	return m.matcher.MatchesEntireDir(symlinkPath(m.links, f))
+}
+
+// walkSymlinks calls fn for every symlink under root.
+func walkSymlinks(root string, m watch.PathMatcher, fn func(link string)) {
+	_ = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// Keep going; anything we can't read just isn't followed.
+			if entry != nil && entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() {
+			if skip, _ := m.MatchesEntireDir(path); skip && path != root {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.Type()&fs.ModeSymlink != 0 {
+			fn(path)
+		}
+		return nil
+	})
+}
+
+// rescanFiles lists every file under paths that isn't ignored by m, as if it had just changed.
+func rescanFiles(ctx context.Context, paths []string, m watch.PathMatcher) []watch.FileEvent {
+	var events []watch.FileEvent
+	for _, root := range paths {
+		_ = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if err != nil {
+				// Keep going; anything we can't read can't have changed.
+				if entry != nil && entry.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if entry.IsDir() {
+				if skip, _ := m.MatchesEntireDir(path); skip && path != root {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if ignored, _ := m.Matches(path); !ignored {
+				events = append(events, watch.NewFileEvent(path))
+			}
+			return nil
+		})
+	}
+	return events
+}
+
+// symlinkPath translates a path under the real target of a followed symlink back to a path under the symlink.
+func symlinkPath(links map[string]string, path string) string {
+	bestLink, bestRel := "", ""
+	bestLen := -1
+	for link, real := range links {
+		rel, ok := ospath.Child(real, path)
+		if ok && len(real) > bestLen {
+			bestLink, bestRel, bestLen = link, rel, len(real)
+		}
+	}
+	if bestLen < 0 {
+		return path
+	}
+	if bestRel == "." {
+		return bestLink
+	}
+	// The symlink itself may be under the target of another followed symlink.
+	return symlinkPath(links, filepath.Join(bestLink, bestRel))
+}
+
+// debugIgnoreMatcher logs an explanation of every ignore decision, for FileWatchSpec.DebugIgnores.
+type debugIgnoreMatcher struct {
+	prefix  string
+	matcher watch.PathMatcher
+	rules   ignoreRules
+	logger  logger.Logger
+}
+
+var _ watch.PathMatcher = debugIgnoreMatcher{}
+
+func newDebugIgnoreMatcher(prefix string, m watch.PathMatcher, rules ignoreRules, l logger.Logger) debugIgnoreMatcher {
+	return debugIgnoreMatcher{prefix: prefix, matcher: m, rules: rules, logger: l}
+}
+
+func (m debugIgnoreMatcher) Matches(f string) (bool, error) {
+	matches, err := m.matcher.Matches(f)
+	if err != nil {
+		m.logger.Infof("%s: error matching %s: %v", m.prefix, f, err)
+		return matches, err
+	}
+	if !matches {
+		m.logger.Infof("%s: not ignoring %s", m.prefix, f)
+		return matches, err
+	}
+	m.logger.Infof("%s: ignoring %s (%s)", m.prefix, f, m.explain(f))
+	return matches, err
+}
+
+func (m debugIgnoreMatcher) MatchesEntireDir(f string) (bool, error) {
+	return m.matcher.MatchesEntireDir(f)
+}
+
+// explain describes which rule caused a path to be ignored.
+func (m debugIgnoreMatcher) explain(f string) string {
+	if ok, reason := m.rules.explain(f); ok {
+		return reason
+	}
+	return "unknown rule"
+}
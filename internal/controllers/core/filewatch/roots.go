@@ -0,0 +1,82 @@
+package filewatch
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// missingRoots returns the roots that don't exist.
+func missingRoots(roots []string) []string {
+	var result []string
+	for _, root := range roots {
+		if _, err := os.Stat(root); os.IsNotExist(err) {
+			result = append(result, root)
+		}
+	}
+	return result
+}
+
+// setRootMissingCondition sets the RootMissing condition based on which roots exist. If they
+// all do, the condition is only updated if it's already there.
+func setRootMissingCondition(status *v1alpha1.FileWatchStatus, roots []string, now time.Time) {
+	condition := metav1.Condition{
+		Type:               v1alpha1.FileWatchConditionRootMissing,
+		Status:             metav1.ConditionFalse,
+		Reason:             "Found",
+		Message:            "all watched paths exist",
+		LastTransitionTime: metav1.NewTime(now),
+	}
+	if missing := missingRoots(roots); len(missing) != 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "NotFound"
+		condition.Message = fmt.Sprintf("watched paths don't exist: %s. "+
+			"They'll be watched again once they're created", strings.Join(missing, ", "))
+	} else if meta.FindStatusCondition(status.Conditions, v1alpha1.FileWatchConditionRootMissing) == nil {
+		return
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+}
+
+// rootDirectories returns the roots that are directories.
+func rootDirectories(roots []string) map[string]bool {
+	result := make(map[string]bool)
+	for _, root := range roots {
+		if info, err := os.Stat(root); err == nil && info.IsDir() {
+			result[root] = true
+		}
+	}
+	return result
+}
+
+// checkRoots updates the RootMissing condition if any of paths is a root, or if a root is
+// already missing, since monitors don't always report a root directory being created.
+//
+// A root directory that was there when the monitor started takes the monitor's OS-level
+// watches with it when it's deleted, so the monitor has to be restarted to notice it
+// being created again. Roots that were missing from the start are watched through
+// their parent directories, and don't need that.
+//
+// mu must be held before calling.
+func (w *watcher) checkRoots(paths []string, exists map[string]bool) {
+	found := false
+	for _, p := range paths {
+		if !slices.Contains(w.roots, p) {
+			continue
+		}
+		found = true
+		if !exists[p] && w.rootDirs[p] {
+			w.rootDirDeleted = true
+		}
+	}
+	if found || meta.IsStatusConditionTrue(w.status.Conditions, v1alpha1.FileWatchConditionRootMissing) {
+		setRootMissingCondition(w.status, w.roots, w.clock.Now())
+	}
+}
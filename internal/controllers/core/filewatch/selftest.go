@@ -0,0 +1,120 @@
+package filewatch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tilt-dev/tilt/internal/watch"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// selfTestTimeout is how long the self-test waits for the event for its probe file.
+const selfTestTimeout = 5 * time.Second
+
+const selfTestFailedMsg = `The filesystem monitor didn't deliver a file event within %s, so file changes may not be detected in this environment. ` +
+	`This can happen on some network filesystems and VM shared folders. Consider setting spec.watchMode to Poll.
+error: %v`
+
+// selfTest checks that the environment can deliver file events, for FileWatchSpec.SelfTest,
+// and records the result in the SelfTestPassed condition.
+//
+// It watches a temporary directory of its own rather than the watched paths, so that the
+// probe file doesn't trigger builds or depend on the watch's ignore rules.
+func (c *Controller) selfTest(ctx context.Context, w *watcher) {
+	err := c.runSelfTest(ctx, w)
+	if ctx.Err() != nil {
+		// The watch was stopped before the self-test finished.
+		return
+	}
+	if err != nil {
+		msg := fmt.Sprintf(selfTestFailedMsg, selfTestTimeout, err)
+		w.setSelfTestCondition(metav1.ConditionFalse, "NoEvents", msg, c.clock.Now())
+		c.watchLogger(ctx, w.name.Name).Errorf("%s: self-test failed: %s", logPrefix(w.name.Name, w.spec.LogPrefix), msg)
+	} else {
+		w.setSelfTestCondition(metav1.ConditionTrue, "EventReceived", "a file event was delivered by the self-test", c.clock.Now())
+	}
+	c.requeuer.Add(w.name)
+}
+
+func (c *Controller) runSelfTest(ctx context.Context, w *watcher) error {
+	dir, err := os.MkdirTemp("", "tilt-filewatch-selftest-")
+	if err != nil {
+		return fmt.Errorf("creating temp dir: %v", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	// Monitors report paths with symlinks resolved (e.g., /private/var on macOS).
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+
+	notify, err := c.fsWatcherMaker([]string{dir}, watch.EmptyMatcher{}, logger.Get(ctx))
+	if err != nil {
+		return fmt.Errorf("creating monitor: %v", err)
+	}
+	if err := notify.Start(); err != nil {
+		return fmt.Errorf("starting monitor: %v", err)
+	}
+	defer func() {
+		_ = notify.Close()
+	}()
+
+	probe := filepath.Join(dir, "probe")
+	timeout := c.clock.After(selfTestTimeout)
+	w.setSelfTestProbe(probe)
+	if err := os.WriteFile(probe, []byte("tilt"), 0600); err != nil {
+		return fmt.Errorf("writing probe file: %v", err)
+	}
+
+	for {
+		select {
+		case e, ok := <-notify.Events():
+			if !ok {
+				return fmt.Errorf("monitor closed unexpectedly")
+			}
+			if e.Path() == probe {
+				return nil
+			}
+		case err, ok := <-notify.Errors():
+			if !ok {
+				return fmt.Errorf("monitor closed unexpectedly")
+			}
+			return err
+		case <-timeout:
+			return fmt.Errorf("no event for %s", probe)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (w *watcher) setSelfTestCondition(conditionStatus metav1.ConditionStatus, reason, message string, now time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	setSelfTestCondition(w.status, conditionStatus, reason, message, now)
+}
+
+func setSelfTestCondition(status *v1alpha1.FileWatchStatus, conditionStatus metav1.ConditionStatus, reason, message string, now time.Time) {
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               v1alpha1.FileWatchConditionSelfTestPassed,
+		Status:             conditionStatus,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: metav1.NewTime(now),
+	})
+}
+
+func (w *watcher) setSelfTestProbe(probe string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.selfTestProbe = probe
+}
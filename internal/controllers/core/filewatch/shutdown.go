@@ -0,0 +1,85 @@
+package filewatch
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/tilt-dev/tilt/internal/controllers/core/filewatch/fsevent"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// ShutdownDrainTimeoutEnvVar sets how long a watch waits, when Tilt is shutting down, to
+// record the events its filesystem monitor had already received and write the final
+// status. Set it to 0 to skip the drain.
+const ShutdownDrainTimeoutEnvVar = "TILT_WATCH_SHUTDOWN_DRAIN_TIMEOUT"
+
+// defaultShutdownDrainTimeout is longer than drainTimeout, since it also covers the
+// final status write.
+const defaultShutdownDrainTimeout = 2 * time.Second
+
+// errWatchStopped is the cause when the controller stops a watch itself, as opposed to
+// the whole controller shutting down.
+var errWatchStopped = errors.New("filewatch stopped")
+
+func shutdownDrainTimeoutFromEnv() time.Duration {
+	v := os.Getenv(ShutdownDrainTimeoutEnvVar)
+	if v == "" {
+		return defaultShutdownDrainTimeout
+	}
+	timeout, err := time.ParseDuration(v)
+	if err != nil || timeout < 0 {
+		return defaultShutdownDrainTimeout
+	}
+	return timeout
+}
+
+// isShutdown reports whether ctx, the context of a watch's dispatch loop, was cancelled
+// because the controller is shutting down.
+func isShutdown(ctx context.Context) bool {
+	return ctx.Err() != nil && !errors.Is(context.Cause(ctx), errWatchStopped)
+}
+
+// drainOnShutdown records the events that w's filesystem monitor had already received,
+// and writes the resulting status, so that they aren't lost when Tilt exits.
+//
+// Both steps together are bounded by c.shutdownDrainTimeout. ctx is already cancelled,
+// so the status is written with a context that only carries its values.
+func (c *Controller) drainOnShutdown(ctx context.Context, w *watcher, eventsCh <-chan fsevent.Batch) {
+	if c.shutdownDrainTimeout <= 0 {
+		return
+	}
+	writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.shutdownDrainTimeout)
+	defer cancel()
+
+	if err := w.closeNotify(); err != nil {
+		logger.Get(ctx).Debugf("Failed to close notifier for %q: %v", w.name.String(), err)
+	}
+
+	for drained := false; !drained; {
+		select {
+		case batch, ok := <-eventsCh:
+			if !ok {
+				drained = true
+				continue
+			}
+			c.subscribers.notify(w.name, w.recordEvent(batch.Events, v1alpha1.FileEventFlushReasonShutdownDrain))
+		case <-writeCtx.Done():
+			logger.Get(ctx).Debugf("Timed out draining file events for %q", w.name.String())
+			drained = true
+		}
+	}
+
+	var fw v1alpha1.FileWatch
+	if err := c.Client.Get(writeCtx, w.name, &fw); err != nil {
+		logger.Get(ctx).Debugf("Failed to write final status for %q: %v", w.name.String(), err)
+		return
+	}
+	status := w.copyStatus()
+	status.DisableStatus = fw.Status.DisableStatus
+	if err := c.maybeUpdateObjectStatus(writeCtx, &fw, status); err != nil {
+		logger.Get(ctx).Debugf("Failed to write final status for %q: %v", w.name.String(), err)
+	}
+}
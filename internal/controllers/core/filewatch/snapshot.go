@@ -0,0 +1,45 @@
+package filewatch
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// WatchSnapshot describes one of the controller's active watches.
+type WatchSnapshot struct {
+	Name types.NamespacedName
+
+	// The watched paths, made absolute, with globs replaced by their roots.
+	Roots []string
+
+	// The number of OS-level watches held by the filesystem monitor.
+	WatchCount int32
+
+	// Whether the watch is disabled with DisableMode Pause, so its events are discarded.
+	// Watches that are disabled any other way aren't active.
+	Paused bool
+
+	// When the most recent file event was recorded, if any.
+	LastEventTime metav1.MicroTime
+}
+
+// ActiveWatches returns a snapshot of the active watches, ordered by name.
+func (c *Controller) ActiveWatches() []WatchSnapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	watches := c.sortedWatches()
+	result := make([]WatchSnapshot, 0, len(watches))
+	for _, w := range watches {
+		w.mu.Lock()
+		result = append(result, WatchSnapshot{
+			Name:          w.name,
+			Roots:         append([]string(nil), w.roots...),
+			WatchCount:    w.status.WatchCount,
+			Paused:        w.paused,
+			LastEventTime: w.status.LastEventTime,
+		})
+		w.mu.Unlock()
+	}
+	return result
+}
@@ -0,0 +1,67 @@
+package filewatch
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// subscribers are the in-process callbacks for file events, keyed by FileWatch.
+//
+// They're kept apart from the watchers, so that subscriptions outlive restarts of the
+// monitor, and can be made before the FileWatch exists.
+type subscribers struct {
+	mu     sync.Mutex
+	nextID int
+	subs   map[types.NamespacedName]map[int]func(v1alpha1.FileEvent)
+}
+
+// Subscribe calls fn with each FileEvent recorded for the named FileWatch, as soon as it's
+// recorded, rather than waiting for it to be written to the status.
+//
+// fn is called from the goroutine that receives events for the watch, so it must not block.
+// Call the returned function to unsubscribe. It's safe to call more than once.
+func (c *Controller) Subscribe(name types.NamespacedName, fn func(v1alpha1.FileEvent)) func() {
+	s := &c.subscribers
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.subs == nil {
+		s.subs = make(map[types.NamespacedName]map[int]func(v1alpha1.FileEvent))
+	}
+	if s.subs[name] == nil {
+		s.subs[name] = make(map[int]func(v1alpha1.FileEvent))
+	}
+	id := s.nextID
+	s.nextID++
+	s.subs[name][id] = fn
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.subs[name], id)
+		if len(s.subs[name]) == 0 {
+			delete(s.subs, name)
+		}
+	}
+}
+
+// notify calls the subscribers for name with each of events.
+func (s *subscribers) notify(name types.NamespacedName, events []v1alpha1.FileEvent) {
+	if len(events) == 0 {
+		return
+	}
+	s.mu.Lock()
+	fns := make([]func(v1alpha1.FileEvent), 0, len(s.subs[name]))
+	for _, fn := range s.subs[name] {
+		fns = append(fns, fn)
+	}
+	s.mu.Unlock()
+
+	for _, e := range events {
+		for _, fn := range fns {
+			fn(*e.DeepCopy())
+		}
+	}
+}
@@ -2,45 +2,175 @@ package filewatch
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"slices"
 	"sync"
 	"time"
 
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	"github.com/jonboulle/clockwork"
 
+	"github.com/tilt-dev/tilt/internal/controllers/core/filewatch/fsevent"
+	"github.com/tilt-dev/tilt/internal/ospath"
 	"github.com/tilt-dev/tilt/internal/watch"
 	"github.com/tilt-dev/tilt/pkg/apis"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 	"github.com/tilt-dev/tilt/pkg/logger"
 )
 
-// MaxFileEventHistory is the maximum number of file events that will be retained on the FileWatch status.
+// MaxFileEventHistory is the default maximum number of file events that will be retained on the FileWatch status.
+//
+// Individual FileWatch objects can override it with FileWatchSpec.MaxEventHistory.
 const MaxFileEventHistory = 20
 
+// MaxRecentlyIgnored is the maximum number of ignored paths that will be retained on the FileWatch status.
+const MaxRecentlyIgnored = 20
+
 const maxRestartBackoff = 5 * time.Minute
 
+// Rough per-entry memory costs, for EstimatedWatchBytes.
+const (
+	// What the kernel uses for an inotify watch on 64-bit systems.
+	watchDescriptorBytes = 1024
+	// A path held in a map, at a typical path length.
+	trackedPathBytes = 256
+)
+
+// drainTimeout bounds how long deleting a FileWatch waits for events that the filesystem
+// monitor had already received to be recorded.
+const drainTimeout = 500 * time.Millisecond
+
+// maxRestartAttempts is the number of consecutive times a filesystem monitor will be restarted after failing
+// before the controller gives up until the spec changes.
+const maxRestartAttempts = 5
+
+// stableMonitorDuration is how long a filesystem monitor has to stay up before its earlier
+// failures stop counting towards maxRestartAttempts and the restart backoff.
+const stableMonitorDuration = maxRestartBackoff
+
+// maxSetupAttempts is the number of consecutive times a filesystem monitor that fails to
+// start will be retried with a growing backoff, before the controller only retries it every
+// setupCircuitOpenInterval until the spec changes.
+const maxSetupAttempts = 5
+
+const setupCircuitOpenInterval = 10 * time.Minute
+
 const DetectedOverflowErrMsg = `It looks like the inotify event queue has overflowed. Check these instructions for how to raise the queue limit: https://facebook.github.io/watchman/docs/install#system-specific-preparation`
 
+// WatchLimitErrReason prefixes the status error when the OS has run out of file watches, so that
+// consumers can distinguish it from other watcher errors.
+const WatchLimitErrReason = "WatchLimitExceeded"
+
+const DetectedWatchLimitErrMsg = `It looks like the OS limit on file watches has been reached. Check these instructions for how to raise fs.inotify.max_user_watches: https://facebook.github.io/watchman/docs/install#system-specific-preparation`
+
 type watcher struct {
 	clock          clockwork.Clock
 	name           types.NamespacedName
+	objectMeta     metav1.ObjectMeta
 	spec           v1alpha1.FileWatchSpec
+	ignores        []v1alpha1.IgnoreDef // spec.Ignores, with ConfigMap patterns resolved
 	status         *v1alpha1.FileWatchStatus
 	mu             sync.Mutex
 	restartBackoff time.Duration
+	restartCount   int
+	startedAt      time.Time
 	doneAt         time.Time
 	done           bool
 	notify         watch.Notify
+	closeOnce      sync.Once
 	cancel         func()
+
+	// Closed when the dispatch loop exits, so that deletion can wait for events
+	// that were already received to be recorded.
+	drained chan struct{}
+
+	// Gitignore files referenced by the spec, and the subset of them that are only
+	// watched so that changes can be detected.
+	ignoreFiles        []string
+	hiddenIgnoreFiles  map[string]bool
+	ignoreFilesChanged bool
+
+	// When the watch last saw a file event (or was first started), according to clock.
+	// Used to decide whether the watch is stale.
+	lastActive time.Time
+
+	// Real directories being watched on behalf of followed symlinks, keyed by symlink path.
+	symlinks map[string]string
+
+	// Targets of the watched paths that are symlinks, keyed by symlink path, for
+	// WatchSymlinkTargets. Set when one of them is re-pointed, since the monitor
+	// is still following the old target.
+	symlinkTargets        map[string]string
+	symlinkTargetsChanged bool
+
+	// The entries of the watched paths that are zip archives, keyed by archive path, for
+	// DiffArchives.
+	archives map[string]map[string]archiveEntry
+
+	// The IDs of the files under the watched paths, keyed by path, for TrackMoves.
+	fileIDs map[string]fileID
+
+	// Directories under the watched paths, for ReportDirectoryEvents, so that a deleted
+	// path can be recognized as a directory.
+	directories map[string]bool
+
+	// Counts the events ignored by the monitor, so they can be summarized in the log.
+	// Nil when polling, since every scan consults the ignore rules.
+	ignoreCounter *ignoreCounter
+
+	// The current one-second window for MaxEventsPerSecond, how many events have been
+	// recorded in it, and how many were merged because they went over the limit.
+	rateWindowStart  time.Time
+	rateWindowCount  int
+	rateWindowMerged int
+
+	// When the status was last written to the API server, for StatusUpdateInterval, and
+	// the timer that writes it once the interval has passed, if one is pending.
+	lastStatusWrite  time.Time
+	statusWriteTimer clockwork.Timer
+
+	// The file written by the self-test, once it's been started.
+	selfTestProbe string
+
+	// When the monitor was started, according to clock, and the timer that triggers a
+	// reconcile once it's due to be restarted, for WatchRestartInterval.
+	monitorStartedAt time.Time
+	restartTimer     clockwork.Timer
+
+	// Whether the watch is disabled with DisableMode Pause, so events are discarded.
+	paused bool
+
+	// How many times in a row the monitor has failed to start, and the timer that triggers
+	// a reconcile once it's due to be retried.
+	setupFailures   int
+	setupRetryAt    time.Time
+	setupRetryTimer clockwork.Timer
+
+	// If the monitor was closed because it didn't fit in the controller's watch budget,
+	// the number of OS-level watches it needed. See MaxWatchesEnvVar.
+	overBudget int
+
+	// The resolved WatchedPaths, the ones that were directories when the monitor started,
+	// and whether one of those has been deleted since. See checkRoots.
+	roots          []string
+	rootDirs       map[string]bool
+	rootDirDeleted bool
+
+	// The monitor's ignore rules, for the targets of SaveProfiles, which the monitor
+	// never saw a change to.
+	ignoreMatcher watch.PathMatcher
 }
 
 // Whether we need to restart the watcher.
 func (w *watcher) shouldRestart() (bool, ctrl.Result) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
-	if !w.done {
+	if !w.done || w.restartCount > maxRestartAttempts {
 		return false, ctrl.Result{}
 	}
 
@@ -59,28 +189,106 @@ func (w *watcher) cleanupWatch(ctx context.Context) {
 	}
 
 	if w.notify != nil {
-		if err := w.notify.Close(); err != nil {
+		if err := w.closeNotify(); err != nil {
 			logger.Get(ctx).Debugf("Failed to close notifier for %q: %v", w.name.String(), err)
 		}
 	}
 
+	w.doneAt = w.clock.Now()
+	if !w.startedAt.IsZero() && w.doneAt.Sub(w.startedAt) >= stableMonitorDuration {
+		// The monitor was healthy for a while, so this isn't part of a run of failures.
+		w.restartCount = 0
+		w.restartBackoff = time.Second
+	}
 	w.restartBackoff *= 2
 	if w.restartBackoff > maxRestartBackoff {
 		w.restartBackoff = maxRestartBackoff
 	}
-	w.doneAt = w.clock.Now()
-	if ctx.Err() == nil && w.status.Error == "" {
-		w.status.Error = "unexpected close"
+	if ctx.Err() == nil {
+		if w.status.Error == "" {
+			w.status.Error = "unexpected close"
+			w.status.ErrorTime = apis.NowMicro()
+		}
+		w.restartCount++
+		if w.restartCount > maxRestartAttempts {
+			w.status.Error = fmt.Sprintf("filewatch stopped after %d failed restarts: %s", maxRestartAttempts, w.status.Error)
+			setReadyCondition(w.status, metav1.ConditionFalse, "SetupFailed", w.status.Error, w.clock.Now())
+		} else {
+			setReadyCondition(w.status, metav1.ConditionFalse, "SetupInProgress",
+				fmt.Sprintf("restarting filesystem monitor: %s", w.status.Error), w.clock.Now())
+		}
 	}
 
+	if w.restartTimer != nil {
+		w.restartTimer.Stop()
+	}
+	if w.setupRetryTimer != nil {
+		w.setupRetryTimer.Stop()
+	}
 	w.cancel()
 	w.done = true
 }
 
+// closeNotify closes the filesystem monitor. It's safe to call more than once.
+func (w *watcher) closeNotify() error {
+	var err error
+	w.closeOnce.Do(func() {
+		err = w.notify.Close()
+	})
+	return err
+}
+
+// drain closes the filesystem monitor and waits (up to timeout) for the dispatch loop to
+// record any events that the monitor had already received.
+//
+// Returns the resulting status, and whether any new events were recorded.
+func (w *watcher) drain(ctx context.Context, timeout time.Duration) (*v1alpha1.FileWatchStatus, bool) {
+	w.mu.Lock()
+	if w.done || w.notify == nil || w.drained == nil {
+		w.mu.Unlock()
+		return nil, false
+	}
+	lastEventTime := w.status.LastEventTime
+	w.mu.Unlock()
+
+	if err := w.closeNotify(); err != nil {
+		logger.Get(ctx).Debugf("Failed to close notifier for %q: %v", w.name.String(), err)
+	}
+
+	select {
+	case <-w.drained:
+	case <-time.After(timeout):
+		logger.Get(ctx).Debugf("Timed out draining file events for %q", w.name.String())
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.status.DeepCopy(), !w.status.LastEventTime.Equal(&lastEventTime)
+}
+
 func (w *watcher) copyStatus() *v1alpha1.FileWatchStatus {
 	w.mu.Lock()
 	defer w.mu.Unlock()
-	return w.status.DeepCopy()
+	status := w.status.DeepCopy()
+	if w.ignoreCounter != nil {
+		status.RecentlyIgnored = w.ignoreCounter.recentlyIgnored()
+	}
+	return status
+}
+
+// setBaseline records the files in events as the status's Baseline.
+func (w *watcher) setBaseline(events []watch.FileEvent) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	baseline := make([]string, 0, len(events))
+	for _, e := range events {
+		path := symlinkPath(w.symlinks, e.Path())
+		if !w.hiddenIgnoreFiles[path] {
+			baseline = append(baseline, path)
+		}
+	}
+	slices.Sort(baseline)
+	w.status.Baseline = slices.Compact(baseline)
 }
 
 func (w *watcher) recordError(err error) {
@@ -88,25 +296,636 @@ func (w *watcher) recordError(err error) {
 	defer w.mu.Unlock()
 	if err == nil {
 		w.status.Error = ""
+		w.status.ErrorTime = metav1.MicroTime{}
 	} else {
 		w.status.Error = err.Error()
+		w.status.ErrorTime = apis.NowMicro()
+	}
+}
+
+// newFileEvent creates an empty event attributed to this watch.
+func (w *watcher) newFileEvent(now metav1.MicroTime, reason v1alpha1.FileEventFlushReason) v1alpha1.FileEvent {
+	return v1alpha1.FileEvent{
+		Time:               *now.DeepCopy(),
+		FileWatchName:      w.name.Name,
+		FileWatchNamespace: w.name.Namespace,
+		FlushReason:        reason,
 	}
 }
 
-func (w *watcher) recordEvent(fsEvents []watch.FileEvent) {
+// recordEvent records a batch of file changes from the monitor, flushed for reason.
+//
+// Returns copies of the FileEvents that were added to the status.
+func (w *watcher) recordEvent(fsEvents []watch.FileEvent, reason v1alpha1.FileEventFlushReason) []v1alpha1.FileEvent {
 	now := apis.NowMicro()
 	w.mu.Lock()
 	defer w.mu.Unlock()
-	event := v1alpha1.FileEvent{Time: *now.DeepCopy()}
+	if w.paused {
+		return nil
+	}
+	event := w.newFileEvent(now, reason)
+	// A file may change several times within a batch, so dedupe paths,
+	// preserving the order they were first seen in.
+	seen := make(map[string]bool, len(fsEvents))
+	var paths []string
+	ops := eventOps(w.spec.WatchEventTypes)
 	for _, fsEvent := range fsEvents {
-		event.SeenFiles = append(event.SeenFiles, fsEvent.Path())
+		path := symlinkPath(w.symlinks, fsEvent.Path())
+		if w.isIgnoreFile(path) {
+			w.ignoreFilesChanged = true
+		}
+		if seen[path] || w.hiddenIgnoreFiles[path] {
+			continue
+		}
+		if ops != 0 && fsEvent.Op() != 0 && !fsEvent.Op().Has(ops) {
+			continue
+		}
+		seen[path] = true
+		paths = append(paths, path)
+	}
+
+	paths = fsevent.CollapseSaves(paths, saveProfiles(w.spec.SaveProfiles), w.ignoreMatcher)
+	if !w.spec.PreserveSeenFilesOrder {
+		slices.Sort(paths)
+	}
+	targetChanges := w.checkSymlinkTargets(paths)
+	paths, archiveChanges := w.checkArchives(paths)
+
+	exists := make(map[string]bool, len(paths))
+	isDir := make(map[string]bool, len(paths))
+	modTimes := make(map[string]time.Time, len(paths))
+	stat := func(paths []string) {
+		for _, path := range paths {
+			if _, ok := exists[path]; ok {
+				continue
+			}
+			info, err := os.Lstat(path)
+			exists[path] = !os.IsNotExist(err)
+			isDir[path] = err == nil && info.IsDir()
+			if err == nil {
+				modTimes[path] = info.ModTime()
+			}
+		}
+	}
+	stat(paths)
+	w.checkRoots(paths, exists)
+	if len(w.spec.CollapseTo) != 0 {
+		paths = collapseSubtrees(paths, w.spec.CollapseTo)
+		stat(paths)
+	}
+	moves := w.checkMoves(paths, exists)
+	paths = fsevent.CollapseRenames(paths, func(p string) bool { return exists[p] })
+	reported := make(map[string]bool, len(moves))
+	if len(moves) != 0 {
+		for _, path := range paths {
+			reported[path] = true
+		}
+	}
+	var events []v1alpha1.FileEvent
+	for _, path := range paths {
+		if w.spec.MaxBatchSize > 0 && len(event.SeenFiles) == int(w.spec.MaxBatchSize) {
+			event.FlushReason = v1alpha1.FileEventFlushReasonBatchSize
+			events = append(events, event)
+			event = w.newFileEvent(now, reason)
+		}
+		event.SeenFiles = append(event.SeenFiles, path)
+		if w.spec.RelativeTo != "" {
+			event.RelSeenFiles = append(event.RelSeenFiles, relativePath(w.spec.RelativeTo, path))
+		}
+		if !exists[path] {
+			event.DeletedFiles = append(event.DeletedFiles, path)
+		}
+		if modTime := modTimes[path]; modTime.After(event.FileModTime.Time) {
+			event.FileModTime = metav1.NewMicroTime(modTime)
+		}
+		if change, ok := targetChanges[path]; ok {
+			event.SymlinkTargetChanges = append(event.SymlinkTargetChanges, change)
+		}
+		if change, ok := archiveChanges[path]; ok {
+			event.ArchiveChanges = append(event.ArchiveChanges, change)
+		}
+		// A move from a temp file that was collapsed away isn't worth reporting.
+		if move, ok := moves[path]; ok && reported[move.OldPath] {
+			event.Moves = append(event.Moves, move)
+		}
+		if w.directories != nil {
+			if isDir[path] && !w.directories[path] {
+				w.directories[path] = true
+				event.CreatedDirectories = append(event.CreatedDirectories, path)
+			} else if !exists[path] && w.directories[path] {
+				w.forgetDirectory(path)
+				event.DeletedDirectories = append(event.DeletedDirectories, path)
+			}
+		}
 	}
 	if len(event.SeenFiles) != 0 {
+		events = append(events, event)
+	}
+	if rw := w.spec.PathRewrite; rw != nil {
+		for i := range events {
+			rewriteEventPaths(&events[i], rw, w.spec.RelativeTo)
+			if !w.spec.PreserveSeenFilesOrder {
+				sortFileEvent(&events[i])
+			}
+		}
+	}
+	filesSeen := 0
+	for _, e := range events {
+		filesSeen += len(e.SeenFiles)
+	}
+	events = w.throttle(events)
+	recordEventMetrics(w.name.Name, len(fsEvents), events)
+	// New directories may have been watched since the last event.
+	w.status.WatchCount = int32(watch.WatchCount(w.notify))
+	w.status.EstimatedWatchBytes = w.estimatedWatchBytes(int(w.status.WatchCount))
+	// Throttled files are merged into an earlier event, but still count as seen.
+	w.status.TotalFilesSeen += int64(filesSeen)
+	if len(events) != 0 {
 		w.status.LastEventTime = *now.DeepCopy()
-		w.status.FileEvents = append(w.status.FileEvents, event)
-		if len(w.status.FileEvents) > MaxFileEventHistory {
-			w.status.FileEvents = w.status.FileEvents[len(w.status.FileEvents)-MaxFileEventHistory:]
+		w.lastActive = w.clock.Now()
+		for i := range events {
+			events[i].Seq = w.status.TotalEventCount + int64(i) + 1
+		}
+		w.status.FileEvents = append(w.status.FileEvents, events...)
+		w.status.TotalEventCount += int64(len(events))
+		maxHistory := w.maxEventHistory()
+		if len(w.status.FileEvents) > maxHistory {
+			w.status.FileEvents = w.status.FileEvents[len(w.status.FileEvents)-maxHistory:]
 		}
 		w.status.Error = ""
+		w.status.ErrorTime = metav1.MicroTime{}
+		w.restartCount = 0
+	}
+
+	result := make([]v1alpha1.FileEvent, len(events))
+	for i, e := range events {
+		e.DeepCopyInto(&result[i])
+	}
+	return result
+}
+
+// replay re-appends the most recent file event as a new one, if token is a new ReplayToken.
+//
+// Returns the new event, for subscribers.
+func (w *watcher) replay(token string) []v1alpha1.FileEvent {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if token == "" || token == w.status.LastReplayToken {
+		return nil
+	}
+	w.status.LastReplayToken = token
+	if w.paused || len(w.status.FileEvents) == 0 {
+		return nil
+	}
+
+	event := *w.status.FileEvents[len(w.status.FileEvents)-1].DeepCopy()
+	event.Time = apis.NowMicro()
+	event.Seq = w.status.TotalEventCount + 1
+	w.status.FileEvents = append(w.status.FileEvents, event)
+	w.status.TotalEventCount++
+	w.status.LastEventTime = *event.Time.DeepCopy()
+	if maxHistory := w.maxEventHistory(); len(w.status.FileEvents) > maxHistory {
+		w.status.FileEvents = w.status.FileEvents[len(w.status.FileEvents)-maxHistory:]
+	}
+	return []v1alpha1.FileEvent{*event.DeepCopy()}
+}
+
+// throttle enforces MaxEventsPerSecond by merging events over the limit into the most
+// recent event, and updates the Throttled condition.
+//
+// Returns the events that should still be appended to the status.
+func (w *watcher) throttle(events []v1alpha1.FileEvent) []v1alpha1.FileEvent {
+	maxEvents := int(w.spec.MaxEventsPerSecond)
+	if maxEvents <= 0 || len(events) == 0 {
+		return events
+	}
+
+	now := w.clock.Now()
+	if now.Sub(w.rateWindowStart) >= time.Second {
+		if w.rateWindowMerged > 0 {
+			meta.SetStatusCondition(&w.status.Conditions, metav1.Condition{
+				Type:               v1alpha1.FileWatchConditionThrottled,
+				Status:             metav1.ConditionFalse,
+				Reason:             "WithinRate",
+				Message:            fmt.Sprintf("file events are within %d per second", maxEvents),
+				LastTransitionTime: metav1.NewTime(now),
+			})
+		}
+		w.rateWindowStart = now
+		w.rateWindowCount = 0
+		w.rateWindowMerged = 0
+	}
+
+	var result []v1alpha1.FileEvent
+	for _, e := range events {
+		var last *v1alpha1.FileEvent
+		if len(result) != 0 {
+			last = &result[len(result)-1]
+		} else if len(w.status.FileEvents) != 0 {
+			last = &w.status.FileEvents[len(w.status.FileEvents)-1]
+		}
+		if w.rateWindowCount < maxEvents || last == nil {
+			w.rateWindowCount++
+			result = append(result, e)
+			continue
+		}
+		mergeFileEvent(last, e)
+		if !w.spec.PreserveSeenFilesOrder {
+			sortFileEvent(last)
+		}
+		w.rateWindowMerged++
+	}
+
+	if w.rateWindowMerged > 0 {
+		meta.SetStatusCondition(&w.status.Conditions, metav1.Condition{
+			Type:               v1alpha1.FileWatchConditionThrottled,
+			Status:             metav1.ConditionTrue,
+			Reason:             "RateExceeded",
+			Message:            fmt.Sprintf("merged %d file events over the limit of %d per second", w.rateWindowMerged, maxEvents),
+			LastTransitionTime: metav1.NewTime(now),
+		})
+	}
+	return result
+}
+
+// sortFileEvent sorts the files in e, keeping RelSeenFiles in the same order as SeenFiles.
+func sortFileEvent(e *v1alpha1.FileEvent) {
+	rel := make(map[string]string, len(e.RelSeenFiles))
+	for i, f := range e.RelSeenFiles {
+		if i < len(e.SeenFiles) {
+			rel[e.SeenFiles[i]] = f
+		}
+	}
+	slices.Sort(e.SeenFiles)
+	if len(e.RelSeenFiles) == len(e.SeenFiles) {
+		for i, f := range e.SeenFiles {
+			e.RelSeenFiles[i] = rel[f]
+		}
+	}
+	slices.Sort(e.DeletedFiles)
+	slices.Sort(e.CreatedDirectories)
+	slices.Sort(e.DeletedDirectories)
+}
+
+// mergeFileEvent folds the files from src into dst, keeping the latest time.
+func mergeFileEvent(dst *v1alpha1.FileEvent, src v1alpha1.FileEvent) {
+	inSrc := make(map[string]bool, len(src.SeenFiles))
+	for _, f := range src.SeenFiles {
+		inSrc[f] = true
+	}
+
+	// Whether a file is deleted depends on the most recent event that saw it.
+	var deletedFiles []string
+	for _, f := range dst.DeletedFiles {
+		if !inSrc[f] {
+			deletedFiles = append(deletedFiles, f)
+		}
+	}
+	dst.DeletedFiles = append(deletedFiles, src.DeletedFiles...)
+
+	inDst := make(map[string]bool, len(dst.SeenFiles))
+	for _, f := range dst.SeenFiles {
+		inDst[f] = true
+	}
+	for i, f := range src.SeenFiles {
+		if !inDst[f] {
+			dst.SeenFiles = append(dst.SeenFiles, f)
+			if i < len(src.RelSeenFiles) {
+				dst.RelSeenFiles = append(dst.RelSeenFiles, src.RelSeenFiles[i])
+			}
+		}
+	}
+
+	// If a symlink was re-pointed more than once, report where it started and ended up.
+	for _, change := range src.SymlinkTargetChanges {
+		merged := false
+		for i := range dst.SymlinkTargetChanges {
+			if dst.SymlinkTargetChanges[i].Path == change.Path {
+				dst.SymlinkTargetChanges[i].NewTarget = change.NewTarget
+				merged = true
+			}
+		}
+		if !merged {
+			dst.SymlinkTargetChanges = append(dst.SymlinkTargetChanges, change)
+		}
+	}
+
+	// If an archive changed more than once, report every entry that changed along the way.
+	for _, change := range src.ArchiveChanges {
+		merged := false
+		for i := range dst.ArchiveChanges {
+			if dst.ArchiveChanges[i].Path == change.Path {
+				entries := append(dst.ArchiveChanges[i].Entries, change.Entries...)
+				slices.Sort(entries)
+				dst.ArchiveChanges[i].Entries = slices.Compact(entries)
+				merged = true
+			}
+		}
+		if !merged {
+			dst.ArchiveChanges = append(dst.ArchiveChanges, change)
+		}
+	}
+
+	// If a file was moved more than once, report where it started and ended up.
+	dst.Moves = mergeMoves(dst.Moves, src.Moves)
+
+	// Whether a directory was created or deleted depends on the most recent event that saw it.
+	dst.CreatedDirectories = append(removePaths(dst.CreatedDirectories, src.DeletedDirectories), src.CreatedDirectories...)
+	dst.DeletedDirectories = append(removePaths(dst.DeletedDirectories, src.CreatedDirectories), src.DeletedDirectories...)
+	dst.Time = *src.Time.DeepCopy()
+	dst.FlushReason = src.FlushReason
+}
+
+// removePaths returns the paths that aren't in remove.
+func removePaths(paths []string, remove []string) []string {
+	var result []string
+	for _, p := range paths {
+		if !slices.Contains(remove, p) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// estimatedWatchBytes estimates the memory used by watchCount OS-level watches, which the
+// monitor keeps track of by path, plus the paths the watcher tracks itself.
+func (w *watcher) estimatedWatchBytes(watchCount int) int64 {
+	if watchCount == 0 {
+		return 0
+	}
+	tracked := len(w.directories) + len(w.symlinks) + len(w.symlinkTargets) + len(w.fileIDs)
+	return int64(watchCount)*(watchDescriptorBytes+trackedPathBytes) + int64(tracked)*trackedPathBytes
+}
+
+// forgetDirectory removes a deleted directory, and any directories under it, from the known directories.
+//
+// mu must be held before calling.
+func (w *watcher) forgetDirectory(dir string) {
+	for d := range w.directories {
+		if ospath.IsChild(dir, d) {
+			delete(w.directories, d)
+		}
+	}
+}
+
+// setReadyCondition records whether the filesystem monitor is live.
+func setReadyCondition(status *v1alpha1.FileWatchStatus, conditionStatus metav1.ConditionStatus, reason, message string, now time.Time) {
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               v1alpha1.FileWatchConditionReady,
+		Status:             conditionStatus,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: metav1.NewTime(now),
+	})
+}
+
+// updateStaleCondition sets the Stale condition based on how long it's been since the last file event.
+//
+// Returns how long until the watch will become stale, or 0 if there's nothing to re-check.
+func (w *watcher) updateStaleCondition() time.Duration {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	staleAfter := w.spec.StaleAfter.Duration
+	if staleAfter <= 0 {
+		meta.RemoveStatusCondition(&w.status.Conditions, v1alpha1.FileWatchConditionStale)
+		return 0
+	}
+
+	condition := metav1.Condition{
+		Type:               v1alpha1.FileWatchConditionStale,
+		Status:             metav1.ConditionFalse,
+		Reason:             "RecentEvents",
+		Message:            fmt.Sprintf("file events seen in the last %s", staleAfter),
+		LastTransitionTime: metav1.NewTime(w.clock.Now()),
+	}
+	remaining := staleAfter - w.clock.Since(w.lastActive)
+	if remaining <= 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "NoRecentEvents"
+		condition.Message = fmt.Sprintf("no file events in the last %s", staleAfter)
+		remaining = 0
+	}
+	meta.SetStatusCondition(&w.status.Conditions, condition)
+	return remaining
+}
+
+// statusWriteDelay returns how long until the status can be written again under
+// StatusUpdateInterval, or 0 if it can be written now.
+func (w *watcher) statusWriteDelay() time.Duration {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	interval := w.spec.StatusUpdateInterval.Duration
+	if interval <= 0 || w.lastStatusWrite.IsZero() {
+		return 0
+	}
+	remaining := interval - w.clock.Since(w.lastStatusWrite)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+func (w *watcher) markStatusWritten() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lastStatusWrite = w.clock.Now()
+}
+
+func (w *watcher) isIgnoreFile(path string) bool {
+	for _, f := range w.ignoreFiles {
+		if f == path {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSymlinkTargets returns the watched symlinks in paths that have been re-pointed,
+// keyed by symlink path.
+//
+// mu must be held before calling.
+func (w *watcher) checkSymlinkTargets(paths []string) map[string]v1alpha1.SymlinkTargetChange {
+	var changes map[string]v1alpha1.SymlinkTargetChange
+	for _, path := range paths {
+		oldTarget, ok := w.symlinkTargets[path]
+		if !ok {
+			continue
+		}
+		newTarget := symlinkTarget(path)
+		if newTarget == oldTarget {
+			continue
+		}
+		if changes == nil {
+			changes = make(map[string]v1alpha1.SymlinkTargetChange)
+		}
+		changes[path] = v1alpha1.SymlinkTargetChange{Path: path, OldTarget: oldTarget, NewTarget: newTarget}
+		w.symlinkTargets[path] = newTarget
+		w.symlinkTargetsChanged = true
+	}
+	return changes
+}
+
+func (w *watcher) setPaused(paused bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.paused = paused
+}
+
+// Whether the monitor has been running for WatchRestartInterval, and should be replaced.
+func (w *watcher) restartDue() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	interval := w.spec.WatchRestartInterval.Duration
+	return interval > 0 && !w.done && w.notify != nil && w.clock.Since(w.monitorStartedAt) >= interval
+}
+
+// setupRetryDue reports whether the monitor failed to start, and is due to be retried.
+func (w *watcher) setupRetryDue() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.setupFailures > 0 && !w.done && w.notify == nil && !w.clock.Now().Before(w.setupRetryAt)
+}
+
+// setupRetryBackoff is how long to wait before retrying a monitor that has failed to start
+// failures times in a row.
+func setupRetryBackoff(failures int) time.Duration {
+	if failures >= maxSetupAttempts {
+		return setupCircuitOpenInterval
+	}
+	return min(time.Second<<(failures-1), maxRestartBackoff)
+}
+
+// flushReason converts the reason Coalesce emitted a batch to a FileEvent's FlushReason.
+func flushReason(reason fsevent.FlushReason) v1alpha1.FileEventFlushReason {
+	switch reason {
+	case fsevent.FlushMax:
+		return v1alpha1.FileEventFlushReasonTimer
+	case fsevent.FlushBatchSize:
+		return v1alpha1.FileEventFlushReasonBatchSize
+	case fsevent.FlushClosed:
+		// The monitor is only closed when the watch is being stopped.
+		return v1alpha1.FileEventFlushReasonShutdownDrain
+	default:
+		return v1alpha1.FileEventFlushReasonQuietPeriod
+	}
+}
+
+// saveProfiles converts the spec's SaveProfiles to the profiles CollapseSaves uses.
+func saveProfiles(names []v1alpha1.FileWatchSaveProfile) []fsevent.SaveProfile {
+	var result []fsevent.SaveProfile
+	for _, name := range names {
+		switch name {
+		case v1alpha1.FileWatchSaveProfileVim:
+			result = append(result, fsevent.VimSaveProfile)
+		case v1alpha1.FileWatchSaveProfileEmacs:
+			result = append(result, fsevent.EmacsSaveProfile)
+		case v1alpha1.FileWatchSaveProfileJetBrains:
+			result = append(result, fsevent.JetBrainsSaveProfile)
+		}
+	}
+	return result
+}
+
+// eventOps returns the ops for the given event types, or 0 if changes of every type are wanted.
+func eventOps(types []v1alpha1.FileWatchEventType) watch.Op {
+	var ops watch.Op
+	for _, t := range types {
+		switch t {
+		case v1alpha1.FileWatchEventTypeCreate:
+			ops |= watch.OpCreate
+		case v1alpha1.FileWatchEventTypeWrite:
+			ops |= watch.OpWrite
+		case v1alpha1.FileWatchEventTypeRemove:
+			ops |= watch.OpRemove
+		case v1alpha1.FileWatchEventTypeRename:
+			ops |= watch.OpRename
+		}
+	}
+	return ops
+}
+
+// Whether the monitor needs to be restarted, because a gitignore file has changed or a
+// watched symlink has been re-pointed since the watcher was started.
+func (w *watcher) needsRestart() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.ignoreFilesChanged || w.symlinkTargetsChanged || w.rootDirDeleted
+}
+
+// setRecordingFields copies the spec fields that only affect how events are recorded (or whether
+// the watch is enabled) from src to dst. These can change without restarting the monitor.
+func setRecordingFields(dst *v1alpha1.FileWatchSpec, src v1alpha1.FileWatchSpec) {
+	dst.DisableSource = src.DisableSource
+	dst.DisableSources = src.DisableSources
+	dst.DisableSourcePolicy = src.DisableSourcePolicy
+	dst.MaxEventHistory = src.MaxEventHistory
+	dst.StaleAfter = src.StaleAfter
+	dst.MaxEventsPerSecond = src.MaxEventsPerSecond
+	dst.RelativeTo = src.RelativeTo
+	dst.PathRewrite = src.PathRewrite
+	dst.StatusUpdateInterval = src.StatusUpdateInterval
+	dst.PreserveSeenFilesOrder = src.PreserveSeenFilesOrder
+	dst.DisableMode = src.DisableMode
+	dst.SaveProfiles = src.SaveProfiles
+	dst.CollapseTo = src.CollapseTo
+	dst.ReplayToken = src.ReplayToken
+}
+
+// monitorSpec returns the parts of the spec that the filesystem monitor and its event
+// loop are built from. The monitor only needs to be restarted when these change.
+func monitorSpec(spec v1alpha1.FileWatchSpec) v1alpha1.FileWatchSpec {
+	result := *spec.DeepCopy()
+	setRecordingFields(&result, v1alpha1.FileWatchSpec{})
+	return result
+}
+
+// applySpec updates a running watch with spec changes that don't need a new monitor.
+func (w *watcher) applySpec(spec v1alpha1.FileWatchSpec) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	setRecordingFields(&w.spec, *spec.DeepCopy())
+	if maxHistory := w.maxEventHistory(); len(w.status.FileEvents) > maxHistory {
+		w.status.FileEvents = w.status.FileEvents[len(w.status.FileEvents)-maxHistory:]
+	}
+	if w.spec.MaxEventsPerSecond <= 0 {
+		meta.RemoveStatusCondition(&w.status.Conditions, v1alpha1.FileWatchConditionThrottled)
+	}
+}
+
+// maxEventHistory is the number of file events to retain on the status.
+func (w *watcher) maxEventHistory() int {
+	if w.spec.MaxEventHistory != nil && *w.spec.MaxEventHistory > 0 {
+		return int(*w.spec.MaxEventHistory)
+	}
+	return MaxFileEventHistory
+}
+
+// eventBufferSize is the number of file changes queued between the monitor and the dispatch loop.
+func (w *watcher) eventBufferSize() int {
+	if w.spec.EventBufferSize > 0 {
+		return int(w.spec.EventBufferSize)
+	}
+	return fsevent.DefaultEventBufferSize
+}
+
+// debounceDuration is the window used to coalesce file changes into a single event.
+func (w *watcher) debounceDuration() time.Duration {
+	if w.spec.QuietPeriod.Duration > 0 {
+		return w.spec.QuietPeriod.Duration
+	}
+	if w.spec.DebounceDuration.Duration > 0 {
+		return w.spec.DebounceDuration.Duration
+	}
+	return fsevent.BufferMinRestDuration
+}
+
+// debounceTimers creates the timers used to coalesce file changes into a single event.
+//
+// With a QuietPeriod, a batch stays open for as long as file changes keep coming in.
+func (w *watcher) debounceTimers(maker fsevent.DebounceTimersMaker) fsevent.DebounceTimers {
+	timers := maker(w.debounceDuration())
+	if w.spec.QuietPeriod.Duration > 0 {
+		timers.Max = nil
 	}
+	return timers
 }
@@ -2,8 +2,15 @@ package tiltfile
 
 import (
 	"context"
+	"time"
 
+	"k8s.io/apimachinery/pkg/api/meta"
+
+	"github.com/tilt-dev/tilt/internal/controllers/apis/trigger"
+	"github.com/tilt-dev/tilt/internal/ospath"
+	"github.com/tilt-dev/tilt/internal/sliceutils"
 	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 	"github.com/tilt-dev/tilt/pkg/model"
 	"github.com/tilt-dev/tilt/pkg/model/logstore"
 )
@@ -11,15 +18,110 @@ import (
 // BuildEntry is vestigial, but currently used to help manage state about a tiltfile build.
 type BuildEntry struct {
 	Name                  model.ManifestName
-	FilesChanged          []string
+	Changes               []FileChange
 	BuildReason           model.BuildReason
 	Args                  []string
 	TiltfilePath          string
 	CheckpointAtExecStart logstore.Checkpoint
 	LoadCount             int
 	ArgsChanged           bool
+
+	// The log span for this build.
+	SpanID logstore.SpanID
+}
+
+// FileChange is a changed file that triggered a tiltfile build, and why.
+type FileChange struct {
+	Path string
+
+	// The FileWatch that saw the change.
+	FileWatch string
+
+	// The dependency that made the file relevant: the most specific of the FileWatch's
+	// watched paths that contains it (e.g., the Tiltfile, or a file or directory it loaded).
+	Dependency string
+}
+
+// FilesChanged flattens Changes into a deduped, sorted list of paths.
+func (be *BuildEntry) FilesChanged() []string {
+	files := make([]string, 0, len(be.Changes))
+	for _, c := range be.Changes {
+		files = append(files, c.Path)
+	}
+	return sliceutils.DedupedAndSorted(files)
+}
+
+// NeedsRebuild reports whether the entry warrants running the Tiltfile.
+//
+// The BuildReason flags are checked in order of precedence:
+//  1. Init always builds, since the Tiltfile has never been loaded.
+//  2. Triggers (web, CLI, HUD, or unknown) always build, since the user asked for one,
+//     even if nothing changed.
+//  3. ChangedFiles only builds if there are Changes, and TiltfileArgs only builds if
+//     ArgsChanged, so that a flag left over from an earlier build doesn't re-run it.
+//
+// The other flags (e.g., Config or ChangedDeps) are for resource builds, and never
+// build a Tiltfile on their own.
+func (be *BuildEntry) NeedsRebuild() bool {
+	if be.BuildReason.Has(model.BuildReasonFlagInit) || be.BuildReason.HasTrigger() {
+		return true
+	}
+	return len(be.Changes) != 0 || be.ArgsChanged
+}
+
+// fileChanges determines the files that have changed since the last build,
+// and which FileWatch and dependency each of them came from.
+func fileChanges(restartOn *v1alpha1.RestartOnSpec, fileWatches []*v1alpha1.FileWatch, lastBuild time.Time) []FileChange {
+	var changes []FileChange
+	for _, fw := range fileWatches {
+		for _, f := range trigger.FilesChanged(restartOn, []*v1alpha1.FileWatch{fw}, lastBuild) {
+			changes = append(changes, FileChange{
+				Path:       f,
+				FileWatch:  fw.Name,
+				Dependency: dependencyFor(fw.Spec.WatchedPaths, f),
+			})
+		}
+	}
+	return changes
+}
+
+// dependencyFor returns the most specific of watchedPaths that contains path, or "" if
+// none of them do.
+func dependencyFor(watchedPaths []string, path string) string {
+	dep := ""
+	for _, p := range watchedPaths {
+		if ospath.IsChild(p, path) && len(p) > len(dep) {
+			dep = p
+		}
+	}
+	return dep
 }
 
 func (be *BuildEntry) WithLogger(ctx context.Context, st store.RStore) context.Context {
-	return store.WithManifestLogHandler(ctx, st, be.Name, SpanIDForLoadCount(be.Name, be.LoadCount))
+	return store.WithManifestLogHandler(ctx, st, be.Name, be.SpanID)
+}
+
+// pendingFileWatches returns the names of FileWatches whose filesystem monitor
+// hasn't started yet.
+//
+// File changes before the monitor starts are never seen, so the watch isn't
+// authoritative until then. Disabled and failed watches (including those whose
+// monitor couldn't be set up) may never start, so they're not considered pending.
+func pendingFileWatches(fileWatches []*v1alpha1.FileWatch) []string {
+	var result []string
+	for _, fw := range fileWatches {
+		if fw.Status.DisableStatus != nil && fw.Status.DisableStatus.Disabled {
+			continue
+		}
+		if fw.Status.Error != "" || fw.Status.SetupError != "" || !fw.Status.MonitorStartTime.IsZero() {
+			continue
+		}
+		// Ready is only reported once the watch has been reconciled, so it's either
+		// running or has failed.
+		if meta.FindStatusCondition(fw.Status.Conditions, v1alpha1.FileWatchConditionReady) != nil {
+			continue
+		}
+		result = append(result, fw.Name)
+	}
+	return result
 }
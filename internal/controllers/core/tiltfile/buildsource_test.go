@@ -0,0 +1,110 @@
+package tiltfile
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tilt-dev/tilt/pkg/apis"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestBuildEntryFilesChanged(t *testing.T) {
+	entry := BuildEntry{
+		Name: model.MainTiltfileManifestName,
+		Changes: []FileChange{
+			{Path: "/src/b.go", FileWatch: "configs:(Tiltfile)"},
+			{Path: "/src/a.go", FileWatch: "configs:(Tiltfile)"},
+			{Path: "/src/a.go", FileWatch: "other"},
+		},
+	}
+	assert.Equal(t, []string{"/src/a.go", "/src/b.go"}, entry.FilesChanged())
+
+	assert.Empty(t, (&BuildEntry{}).FilesChanged())
+}
+
+func TestBuildEntryNeedsRebuild(t *testing.T) {
+	changes := []FileChange{{Path: "/src/a.go", FileWatch: "configs:(Tiltfile)"}}
+
+	for _, tc := range []struct {
+		name        string
+		reason      model.BuildReason
+		changes     []FileChange
+		argsChanged bool
+		expected    bool
+	}{
+		{name: "nothing", expected: false},
+		{name: "init", reason: model.BuildReasonFlagInit, expected: true},
+		{name: "init with args", reason: model.BuildReasonFlagInit, argsChanged: true, expected: true},
+		{name: "changed files", reason: model.BuildReasonFlagChangedFiles, changes: changes, expected: true},
+		{name: "changed files flag without changes", reason: model.BuildReasonFlagChangedFiles, expected: false},
+		{name: "changes without flag", changes: changes, expected: true},
+		{name: "args changed", reason: model.BuildReasonFlagTiltfileArgs, argsChanged: true, expected: true},
+		{name: "args flag without new args", reason: model.BuildReasonFlagTiltfileArgs, expected: false},
+		{name: "changes and args", reason: model.BuildReasonFlagChangedFiles.With(model.BuildReasonFlagTiltfileArgs),
+			changes: changes, argsChanged: true, expected: true},
+		{name: "web trigger", reason: model.BuildReasonFlagTriggerWeb, expected: true},
+		{name: "cli trigger", reason: model.BuildReasonFlagTriggerCLI, expected: true},
+		{name: "hud trigger", reason: model.BuildReasonFlagTriggerHUD, expected: true},
+		{name: "unknown trigger", reason: model.BuildReasonFlagTriggerUnknown, expected: true},
+		{name: "trigger with stale args flag", reason: model.BuildReasonFlagTriggerCLI.With(model.BuildReasonFlagTiltfileArgs), expected: true},
+		{name: "config", reason: model.BuildReasonFlagConfig, expected: false},
+		{name: "changed deps", reason: model.BuildReasonFlagChangedDeps, expected: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			entry := BuildEntry{
+				Name:        model.MainTiltfileManifestName,
+				BuildReason: tc.reason,
+				Changes:     tc.changes,
+				ArgsChanged: tc.argsChanged,
+			}
+			assert.Equal(t, tc.expected, entry.NeedsRebuild())
+		})
+	}
+}
+
+func TestFileChanges(t *testing.T) {
+	lastBuild := time.Now()
+	before := apis.NewMicroTime(lastBuild.Add(-time.Second))
+	after := apis.NewMicroTime(lastBuild.Add(time.Second))
+
+	fws := []*v1alpha1.FileWatch{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "fw-1"},
+			Spec:       v1alpha1.FileWatchSpec{WatchedPaths: []string{"/src", "/src/a.go", "/lib"}},
+			Status: v1alpha1.FileWatchStatus{FileEvents: []v1alpha1.FileEvent{
+				{Time: before, SeenFiles: []string{"/src/old.go"}},
+				{Time: after, SeenFiles: []string{"/src/a.go", "/src/c.go"}},
+			}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "fw-2"},
+			Spec:       v1alpha1.FileWatchSpec{WatchedPaths: []string{"/src/b.go"}},
+			Status: v1alpha1.FileWatchStatus{FileEvents: []v1alpha1.FileEvent{
+				{Time: after, SeenFiles: []string{"/src/b.go"}},
+			}},
+		},
+	}
+	restartOn := &v1alpha1.RestartOnSpec{FileWatches: []string{"fw-1", "fw-2"}}
+
+	assert.Equal(t, []FileChange{
+		{Path: "/src/a.go", FileWatch: "fw-1", Dependency: "/src/a.go"},
+		{Path: "/src/c.go", FileWatch: "fw-1", Dependency: "/src"},
+		{Path: "/src/b.go", FileWatch: "fw-2", Dependency: "/src/b.go"},
+	}, fileChanges(restartOn, fws, lastBuild))
+
+	assert.Empty(t, fileChanges(nil, fws, lastBuild))
+}
+
+func TestOverlappingBuildsHaveDistinctSpans(t *testing.T) {
+	first := BuildEntry{Name: model.MainTiltfileManifestName, LoadCount: 1, SpanID: newSpanID(model.MainTiltfileManifestName, 1)}
+	second := BuildEntry{Name: model.MainTiltfileManifestName, LoadCount: 1, SpanID: newSpanID(model.MainTiltfileManifestName, 1)}
+
+	assert.NotEqual(t, first.SpanID, second.SpanID)
+	assert.True(t, strings.HasPrefix(string(first.SpanID), string(SpanIDForLoadCount(model.MainTiltfileManifestName, 1))+":"))
+	assert.True(t, strings.HasPrefix(string(second.SpanID), string(SpanIDForLoadCount(model.MainTiltfileManifestName, 1))+":"))
+}
@@ -2,6 +2,7 @@ package tiltfile
 
 import (
 	"fmt"
+	"sync/atomic"
 
 	"github.com/tilt-dev/tilt/pkg/model"
 	"github.com/tilt-dev/tilt/pkg/model/logstore"
@@ -10,3 +11,12 @@ import (
 func SpanIDForLoadCount(mn model.ManifestName, loadCount int) logstore.SpanID {
 	return logstore.SpanID(fmt.Sprintf("tiltfile:%s:%d", mn, loadCount))
 }
+
+// spanNonce is incremented for every Tiltfile build, so that two builds with the same
+// name and load count (e.g., overlapping builds from different reconcilers) never share a span.
+var spanNonce atomic.Int64
+
+// newSpanID creates a unique log span for a Tiltfile build.
+func newSpanID(mn model.ManifestName, loadCount int) logstore.SpanID {
+	return logstore.SpanID(fmt.Sprintf("%s:%d", SpanIDForLoadCount(mn, loadCount), spanNonce.Add(1)))
+}
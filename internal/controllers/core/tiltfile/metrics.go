@@ -2,12 +2,41 @@ package tiltfile
 
 import (
 	"context"
+	"time"
 
 	dockertypes "github.com/docker/docker/api/types"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
 
 	"github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/pkg/logger"
 )
 
+// tiltfileLoadDuration tracks how long each Tiltfile takes to evaluate, so that slow reloads
+// show up on Tilt's /metrics endpoint.
+var tiltfileLoadDuration = metrics.NewHistogramVec(
+	&metrics.HistogramOpts{
+		Namespace:      "tilt",
+		Subsystem:      "tiltfile",
+		Name:           "load_duration_seconds",
+		Help:           "Wall-clock time to evaluate a Tiltfile.",
+		Buckets:        metrics.ExponentialBuckets(0.05, 2, 12),
+		StabilityLevel: metrics.ALPHA,
+	},
+	[]string{"tiltfile"},
+)
+
+func init() {
+	legacyregistry.MustRegister(tiltfileLoadDuration)
+}
+
+// recordLoadDuration records how long a Tiltfile build took to evaluate, in both the
+// build's log span and the load duration metric.
+func recordLoadDuration(ctx context.Context, entry *BuildEntry, duration time.Duration) {
+	logger.Get(ctx).Debugf("Tiltfile %s (load #%d) evaluated in %s", entry.Name, entry.LoadCount, duration)
+	tiltfileLoadDuration.WithLabelValues(entry.Name.String()).Observe(duration.Seconds())
+}
+
 // reportDockerConnectionEvent records a metric about Docker connectivity.
 func (r *Reconciler) reportDockerConnectionEvent(ctx context.Context, success bool, serverVersion dockertypes.Version) {
 	r.dockerConnectMetricReporter.Do(func() {
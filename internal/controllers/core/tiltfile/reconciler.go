@@ -30,6 +30,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/store/buildcontrols"
 	"github.com/tilt-dev/tilt/internal/store/tiltfiles"
 	"github.com/tilt-dev/tilt/internal/tiltfile"
+	"github.com/tilt-dev/tilt/internal/tiltfile/config"
 	"github.com/tilt-dev/tilt/internal/timecmp"
 	"github.com/tilt-dev/tilt/pkg/apis"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
@@ -208,8 +209,11 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 //     (so that we don't keep re-running a failed build)
 //  4. OR the command-line args have changed since the last Tiltfile build
 //  5. OR user has manually triggered a Tiltfile build
+//
+// The first build waits until the FileWatches it restarts on have started
+// their filesystem monitors, so that edits made while it runs aren't missed.
 func (r *Reconciler) needsBuild(
-	_ context.Context,
+	ctx context.Context,
 	nn types.NamespacedName,
 	tf *v1alpha1.Tiltfile,
 	run *runStatus,
@@ -218,7 +222,7 @@ func (r *Reconciler) needsBuild(
 	lastRestartEvent metav1.MicroTime,
 ) *BuildEntry {
 	var reason model.BuildReason
-	filesChanged := []string{}
+	var changes []FileChange
 
 	step := runStepNone
 	lastStartTime := time.Time{}
@@ -230,10 +234,15 @@ func (r *Reconciler) needsBuild(
 	}
 
 	if step == runStepNone {
+		if pending := pendingFileWatches(fileWatches); len(pending) > 0 {
+			// The FileWatch status update will trigger another reconcile.
+			logger.Get(ctx).Debugf("Waiting for FileWatches to start before running Tiltfile: %v", pending)
+			return nil
+		}
 		reason = reason.With(model.BuildReasonFlagInit)
 	} else {
-		filesChanged = trigger.FilesChanged(tf.Spec.RestartOn, fileWatches, lastStartTime)
-		if len(filesChanged) > 0 {
+		changes = fileChanges(tf.Spec.RestartOn, fileWatches, lastStartTime)
+		if len(changes) > 0 {
 			reason = reason.With(model.BuildReasonFlagChangedFiles)
 		} else if timecmp.After(lastRestartEvent, lastStartTime) {
 			reason = reason.With(model.BuildReasonFlagTriggerUnknown)
@@ -248,7 +257,15 @@ func (r *Reconciler) needsBuild(
 		reason = reason.With(configmap.TriggerQueueReason(triggerQueue, nn))
 	}
 
-	if reason == model.BuildReasonNone {
+	entry := &BuildEntry{
+		Name:         model.ManifestName(nn.Name),
+		Changes:      changes,
+		BuildReason:  reason,
+		Args:         tf.Spec.Args,
+		TiltfilePath: tf.Spec.Path,
+		ArgsChanged:  !sliceutils.StringSliceEquals(lastStartArgs, tf.Spec.Args),
+	}
+	if !entry.NeedsRebuild() {
 		return nil
 	}
 
@@ -256,17 +273,10 @@ func (r *Reconciler) needsBuild(
 	defer r.st.RUnlockState()
 
 	r.loadCount++
-
-	return &BuildEntry{
-		Name:                  model.ManifestName(nn.Name),
-		FilesChanged:          filesChanged,
-		BuildReason:           reason,
-		Args:                  tf.Spec.Args,
-		TiltfilePath:          tf.Spec.Path,
-		CheckpointAtExecStart: state.LogStore.Checkpoint(),
-		LoadCount:             r.loadCount,
-		ArgsChanged:           !sliceutils.StringSliceEquals(lastStartArgs, tf.Spec.Args),
-	}
+	entry.CheckpointAtExecStart = state.LogStore.Checkpoint()
+	entry.LoadCount = r.loadCount
+	entry.SpanID = newSpanID(entry.Name, r.loadCount)
+	return entry
 }
 
 // Start a tiltfile run asynchronously, returning immediately.
@@ -298,23 +308,32 @@ func (r *Reconciler) run(ctx context.Context, nn types.NamespacedName, tf *v1alp
 	startTime := time.Now()
 	r.st.Dispatch(ConfigsReloadStartedAction{
 		Name:         entry.Name,
-		FilesChanged: entry.FilesChanged,
+		FilesChanged: entry.FilesChanged(),
 		StartTime:    startTime,
-		SpanID:       SpanIDForLoadCount(entry.Name, entry.LoadCount),
+		SpanID:       entry.SpanID,
 		Reason:       entry.BuildReason,
 	})
 
 	buildcontrols.LogBuildEntry(ctx, buildcontrols.BuildEntry{
 		Name:         entry.Name,
 		BuildReason:  entry.BuildReason,
-		FilesChanged: entry.FilesChanged,
+		FilesChanged: entry.FilesChanged(),
 	})
+	for _, c := range entry.Changes {
+		logger.Get(ctx).Debugf("%s changed (FileWatch: %s, dependency: %s)", c.Path, c.FileWatch, c.Dependency)
+	}
 
 	if entry.BuildReason.Has(model.BuildReasonFlagTiltfileArgs) {
 		logger.Get(ctx).Infof("Tiltfile args changed to: %v", entry.Args)
 	}
 
-	tlr := r.tfl.Load(ctx, tf, run.tlr)
+	tlr, ok := argsOnlyLoad(tf, entry, run.tlr)
+	if ok {
+		logger.Get(ctx).Infof("Only Tiltfile args changed; re-using the previous Tiltfile evaluation")
+	} else {
+		tlr = r.tfl.Load(ctx, tf, run.tlr)
+	}
+	recordLoadDuration(ctx, entry, time.Since(startTime))
 
 	// If the user is executing an empty main tiltfile, that probably means
 	// they need a tutorial. For now, we link to that tutorial, but a more interactive
@@ -354,6 +373,25 @@ func (r *Reconciler) run(ctx context.Context, nn types.NamespacedName, tf *v1alp
 	r.requeuer.Add(nn)
 }
 
+// argsOnlyLoad re-uses the previous load result if the build was only triggered by
+// new args, and the Tiltfile only uses its args to select which resources to enable.
+//
+// Re-executing the Tiltfile can be slow (e.g., it may shell out with local()),
+// and toggling resources with args is common.
+func argsOnlyLoad(tf *v1alpha1.Tiltfile, entry *BuildEntry, prev *tiltfile.TiltfileLoadResult) (tiltfile.TiltfileLoadResult, bool) {
+	if !entry.ArgsChanged || len(entry.Changes) != 0 || entry.BuildReason != model.BuildReasonFlagTiltfileArgs {
+		return tiltfile.TiltfileLoadResult{}, false
+	}
+	if prev == nil || prev.Error != nil || prev.ReadsConfig() {
+		return tiltfile.TiltfileLoadResult{}, false
+	}
+
+	tlr := *prev
+	tlr.Manifests = append([]model.Manifest{}, prev.Manifests...)
+	tlr.EnabledManifests, tlr.Error = config.Settings{}.EnabledResources(tf, tlr.Manifests)
+	return tlr, true
+}
+
 // After the tiltfile has been evaluated, create all the objects in the
 // apiserver.
 func (r *Reconciler) handleLoaded(
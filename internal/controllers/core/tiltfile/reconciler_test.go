@@ -17,6 +17,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/workqueue"
+	"k8s.io/component-base/metrics/testutil"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
@@ -31,6 +32,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/testutils/manifestbuilder"
 	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
 	"github.com/tilt-dev/tilt/internal/tiltfile"
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 	"github.com/tilt-dev/tilt/pkg/model"
 	"github.com/tilt-dev/wmclient/pkg/analytics"
@@ -253,6 +255,32 @@ func TestDockerMetrics(t *testing.T) {
 	assert.ElementsMatch(t, []analytics.CountEvent{connectEvt}, f.ma.Counts)
 }
 
+func TestLoadDuration(t *testing.T) {
+	f := newFixture(t)
+	p := f.tempdir.JoinPath("Tiltfile")
+	f.tempdir.WriteFile(p, "print('hello-world')")
+
+	histogram := tiltfileLoadDuration.WithLabelValues("my-tf")
+	loads, err := testutil.GetHistogramMetricCount(histogram)
+	require.NoError(t, err)
+
+	tf := v1alpha1.Tiltfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "my-tf",
+		},
+		Spec: v1alpha1.TiltfileSpec{
+			Path: p,
+		},
+	}
+	f.createAndWaitForLoaded(&tf)
+
+	assert.Contains(t, f.st.out.String(), "Tiltfile my-tf (load #1) evaluated in ")
+
+	newLoads, err := testutil.GetHistogramMetricCount(histogram)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), newLoads-loads)
+}
+
 func TestArgsChangeResetsEnabledResources(t *testing.T) {
 	f := newFixture(t)
 	p := f.tempdir.JoinPath("Tiltfile")
@@ -289,6 +317,75 @@ func TestArgsChangeResetsEnabledResources(t *testing.T) {
 	f.requireEnabled(m2, true)
 }
 
+func TestArgsOnlyChangeReusesLoad(t *testing.T) {
+	f := newFixture(t)
+	p := f.tempdir.JoinPath("Tiltfile")
+
+	m1 := manifestbuilder.New(f.tempdir, "m1").WithLocalServeCmd("hi").Build()
+	m2 := manifestbuilder.New(f.tempdir, "m2").WithLocalServeCmd("hi").Build()
+	f.tfl.Result = tiltfile.TiltfileLoadResult{
+		Manifests:        []model.Manifest{m1, m2},
+		EnabledManifests: []model.ManifestName{"m1", "m2"},
+	}
+
+	tf := v1alpha1.Tiltfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "my-tf",
+		},
+		Spec: v1alpha1.TiltfileSpec{
+			Path: p,
+			Args: []string{"m1", "m2"},
+		},
+	}
+	f.createAndWaitForLoaded(&tf)
+	require.Equal(t, 1, f.tfl.LoadCount)
+
+	ts := time.Now()
+	f.setArgs("my-tf", []string{"m2"})
+	f.MustReconcile(types.NamespacedName{Name: "my-tf"})
+	f.waitForRunning("my-tf")
+	f.popQueue()
+	f.waitForTerminatedAfter("my-tf", ts)
+
+	assert.Equal(t, 1, f.tfl.LoadCount, "Tiltfile should not have been re-executed")
+	f.requireEnabled(m1, false)
+	f.requireEnabled(m2, true)
+}
+
+func TestArgsChangeReloadsIfConfigUsed(t *testing.T) {
+	f := newFixture(t)
+	p := f.tempdir.JoinPath("Tiltfile")
+
+	m1 := manifestbuilder.New(f.tempdir, "m1").WithLocalServeCmd("hi").Build()
+	f.tfl.Result = tiltfile.TiltfileLoadResult{
+		Manifests:        []model.Manifest{m1},
+		EnabledManifests: []model.ManifestName{"m1"},
+		BuiltinCalls:     []starkit.BuiltinCall{{Name: "config.parse"}},
+	}
+
+	tf := v1alpha1.Tiltfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "my-tf",
+		},
+		Spec: v1alpha1.TiltfileSpec{
+			Path: p,
+			Args: []string{"--foo"},
+		},
+	}
+	f.createAndWaitForLoaded(&tf)
+	require.Equal(t, 1, f.tfl.LoadCount)
+
+	ts := time.Now()
+	f.setArgs("my-tf", []string{"--bar"})
+	f.MustReconcile(types.NamespacedName{Name: "my-tf"})
+	f.waitForRunning("my-tf")
+	f.popQueue()
+	f.waitForTerminatedAfter("my-tf", ts)
+
+	assert.Equal(t, 2, f.tfl.LoadCount)
+	assert.Equal(t, []string{"--bar"}, f.tfl.PassedArgs())
+}
+
 func TestRunWithoutArgsChangePreservesEnabledResources(t *testing.T) {
 	f := newFixture(t)
 	p := f.tempdir.JoinPath("Tiltfile")
@@ -368,6 +465,91 @@ func TestTiltfileFailurePreservesEnabledResources(t *testing.T) {
 	f.requireEnabled(m2, false)
 }
 
+func TestFirstRunWaitsForFileWatchMonitor(t *testing.T) {
+	f := newFixture(t)
+	p := f.tempdir.JoinPath("Tiltfile")
+	f.tempdir.WriteFile(p, "print('hello-world')")
+
+	nn := types.NamespacedName{Name: "my-tf"}
+	fwKey := types.NamespacedName{Name: "configs:my-tf"}
+	tf := v1alpha1.Tiltfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: nn.Name,
+		},
+		Spec: v1alpha1.TiltfileSpec{
+			Path:      p,
+			RestartOn: &v1alpha1.RestartOnSpec{FileWatches: []string{fwKey.Name}},
+		},
+	}
+	f.Create(&tf)
+
+	// The FileWatch exists, but its monitor hasn't started, so the Tiltfile shouldn't run yet.
+	var fw v1alpha1.FileWatch
+	f.MustGet(fwKey, &fw)
+	f.MustReconcile(nn)
+	f.MustGet(nn, &tf)
+	assert.Nil(t, tf.Status.Running)
+	assert.Nil(t, tf.Status.Terminated)
+
+	fw.Status.MonitorStartTime = metav1.NowMicro()
+	f.UpdateStatus(&fw)
+	f.MustReconcile(nn)
+	f.waitForRunning(nn.Name)
+
+	// An edit made right after the build starts is seen by the watch, and
+	// triggers another run once the first one finishes.
+	ts := time.Now()
+	f.MustGet(fwKey, &fw)
+	fw.Status.FileEvents = []v1alpha1.FileEvent{{Time: metav1.NowMicro(), SeenFiles: []string{p}}}
+	f.UpdateStatus(&fw)
+
+	f.popQueue()
+	f.waitForTerminatedAfter(nn.Name, ts)
+	f.popQueue()
+	f.waitForRunning(nn.Name)
+
+	f.r.mu.Lock()
+	entry := f.r.runs[nn].entry
+	f.r.mu.Unlock()
+	assert.True(t, entry.BuildReason.Has(model.BuildReasonFlagChangedFiles))
+	assert.Equal(t, []string{p}, entry.FilesChanged())
+}
+
+func TestFirstRunDoesNotWaitForFailedFileWatch(t *testing.T) {
+	f := newFixture(t)
+	p := f.tempdir.JoinPath("Tiltfile")
+	f.tempdir.WriteFile(p, "print('hello-world')")
+
+	nn := types.NamespacedName{Name: "my-tf"}
+	fwKey := types.NamespacedName{Name: "configs:my-tf"}
+	tf := v1alpha1.Tiltfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: nn.Name,
+		},
+		Spec: v1alpha1.TiltfileSpec{
+			Path:      p,
+			RestartOn: &v1alpha1.RestartOnSpec{FileWatches: []string{fwKey.Name}},
+		},
+	}
+	f.Create(&tf)
+
+	// The monitor couldn't be set up, so it may never start.
+	var fw v1alpha1.FileWatch
+	f.MustGet(fwKey, &fw)
+	fw.Status.SetupError = "watch budget exceeded"
+	fw.Status.Conditions = []metav1.Condition{{
+		Type:               v1alpha1.FileWatchConditionReady,
+		Status:             metav1.ConditionFalse,
+		Reason:             "OverBudget",
+		Message:            fw.Status.SetupError,
+		LastTransitionTime: metav1.Now(),
+	}}
+	f.UpdateStatus(&fw)
+
+	f.MustReconcile(nn)
+	f.waitForRunning(nn.Name)
+}
+
 func TestCancel(t *testing.T) {
 	f := newFixture(t)
 	p := f.tempdir.JoinPath("Tiltfile")
@@ -30,6 +30,7 @@ import (
 
 var controllerSet = wire.NewSet(
 	filewatch.NewController,
+	filewatch.ProvideMaxWatches,
 	kubernetesdiscovery.NewReconciler,
 	portforward.NewReconciler,
 	podlogstream.NewController,
@@ -29,6 +29,7 @@ type InitAction struct {
 	CloudAddress string
 	Token        token.Token
 	TerminalMode store.TerminalMode
+	LogFormat    store.LogFormat
 }
 
 func (InitAction) Action() {}
@@ -74,6 +74,7 @@ func (u Upper) Start(
 	b model.TiltBuild,
 	fileName string,
 	initTerminalMode store.TerminalMode,
+	logFormat store.LogFormat,
 	analyticsUserOpt analytics.Opt,
 	token token.Token,
 	cloudAddress string,
@@ -94,6 +95,7 @@ func (u Upper) Start(
 		Token:            token,
 		CloudAddress:     cloudAddress,
 		TerminalMode:     initTerminalMode,
+		LogFormat:        logFormat,
 	})
 }
 
@@ -283,6 +285,7 @@ func handleInitAction(ctx context.Context, engineState *store.EngineState, actio
 	engineState.CloudAddress = action.CloudAddress
 	engineState.Token = action.Token
 	engineState.TerminalMode = action.TerminalMode
+	engineState.LogFormat = action.LogFormat
 }
 
 func handleHudExitAction(state *store.EngineState, action hud.ExitAction) {
@@ -2093,7 +2093,7 @@ func TestEmptyTiltfile(t *testing.T) {
 	closeCh := make(chan error)
 	go func() {
 		err := f.upper.Start(f.ctx, []string{}, model.TiltBuild{},
-			f.JoinPath("Tiltfile"), store.TerminalModeHUD,
+			f.JoinPath("Tiltfile"), store.TerminalModeHUD, store.LogFormatText,
 			analytics.OptIn, token.Token("unit test token"),
 			"nonexistent.example.com")
 		closeCh <- err
@@ -2128,7 +2128,7 @@ func TestUpperStart(t *testing.T) {
 	f.WriteFile("Tiltfile", "")
 	go func() {
 		err := f.upper.Start(f.ctx, []string{"foo", "bar"}, model.TiltBuild{},
-			f.JoinPath("Tiltfile"), store.TerminalModeHUD,
+			f.JoinPath("Tiltfile"), store.TerminalModeHUD, store.LogFormatJSON,
 			analytics.OptIn, tok, cloudAddress)
 		closeCh <- err
 	}()
@@ -2142,6 +2142,7 @@ func TestUpperStart(t *testing.T) {
 		require.Equal(t, tok, state.Token)
 		require.Equal(t, analytics.OptIn, state.AnalyticsEffectiveOpt())
 		require.Equal(t, cloudAddress, state.CloudAddress)
+		require.Equal(t, store.LogFormatJSON, state.LogFormat)
 	})
 
 	f.cancel()
@@ -3182,7 +3183,7 @@ func newTestFixture(t *testing.T, options ...fixtureOptions) *testFixture {
 	tcum := cloud.NewStatusManager(httptest.NewFakeClientEmptyJSON(), clock)
 	fe := cmd.NewFakeExecer()
 	fpm := cmd.NewFakeProberManager()
-	fwc := filewatch.NewController(cdc, st, watcher.NewSub, timerMaker.Maker(), v1alpha1.NewScheme(), clock)
+	fwc := filewatch.NewController(cdc, st, watcher.NewSub, timerMaker.Maker(), v1alpha1.NewScheme(), clock, 0)
 	cmds := cmd.NewController(ctx, fe, fpm, cdc, st, clock, v1alpha1.NewScheme())
 	lsc := local.NewServerController(cdc)
 	sr := ctrlsession.NewReconciler(cdc, st, clock)
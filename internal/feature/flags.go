@@ -29,6 +29,7 @@ const DisableResources = "disable_resources"
 const BulkDisableResources = "bulk_disable_resources"
 const ClusterRefresh = "cluster_refresh"
 const OfflineSnapshotCreation = "offline_snapshot_creation"
+const FileWatchEventsEndpoint = "filewatch_events_endpoint"
 
 // The Value a flag can have. Status should never be changed.
 type Value struct {
@@ -88,6 +89,10 @@ var MainDefaults = Defaults{
 		Enabled: true,
 		Status:  Obsolete,
 	},
+	FileWatchEventsEndpoint: Value{
+		Enabled: false,
+		Status:  Active,
+	},
 }
 
 // FeatureSet is a mutable set of Features.
@@ -143,6 +143,12 @@ func (s *HeadsUpServerController) setUpHelper(ctx context.Context, st store.RSto
 	apiRouter.PathPrefix("/version").Handler(apiserverHandler)
 	apiRouter.PathPrefix("/debug").Handler(http.DefaultServeMux) // for /debug/pprof
 
+	fileWatchEvents, err := newFileWatchEventsHandler(config.GenericConfig.LoopbackClientConfig, st)
+	if err != nil {
+		return fmt.Errorf("failed to create filewatch events handler: %v", err)
+	}
+	apiRouter.Path(fileWatchEventsPath).Handler(fileWatchEvents)
+
 	var apiTLSConfig *tls.Config
 	if serving.Cert != nil {
 		apiTLSConfig, err = start.TLSConfig(ctx, serving)
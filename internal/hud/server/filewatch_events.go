@@ -0,0 +1,144 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/tilt-dev/tilt/internal/feature"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// fileWatchEventsPath streams FileWatch events as newline-delimited JSON.
+//
+// It's served alongside the apiserver, and requires the same bearer token.
+const fileWatchEventsPath = "/filewatch-events"
+
+// FileWatchEventLine is a single line of the FileWatch events stream.
+type FileWatchEventLine struct {
+	Name         string           `json:"name"`
+	Time         metav1.MicroTime `json:"time"`
+	SeenFiles    []string         `json:"seenFiles"`
+	DeletedFiles []string         `json:"deletedFiles,omitempty"`
+}
+
+// fileWatchEventsHandler streams new FileEvents as they're added to the status of any FileWatch.
+//
+// Only enabled with the filewatch_events_endpoint feature flag.
+type fileWatchEventsHandler struct {
+	client ctrlclient.WithWatch
+	token  string
+	st     store.RStore
+}
+
+func newFileWatchEventsHandler(config *rest.Config, st store.RStore) (*fileWatchEventsHandler, error) {
+	client, err := ctrlclient.NewWithWatch(config, ctrlclient.Options{Scheme: v1alpha1.NewScheme()})
+	if err != nil {
+		return nil, err
+	}
+	return &fileWatchEventsHandler{client: client, token: config.BearerToken, st: st}, nil
+}
+
+func (h *fileWatchEventsHandler) enabled() bool {
+	state := h.st.RLockState()
+	defer h.st.RUnlockState()
+	return state.Features[feature.FileWatchEventsEndpoint]
+}
+
+func (h *fileWatchEventsHandler) authorized(req *http.Request) bool {
+	token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) == 1
+}
+
+func (h *fileWatchEventsHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if !h.enabled() {
+		http.NotFound(w, req)
+		return
+	}
+	if !h.authorized(req) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	ctx := req.Context()
+	watcher, err := h.client.Watch(ctx, &v1alpha1.FileWatchList{})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error watching FileWatches: %v", err), http.StatusInternalServerError)
+		return
+	}
+	defer watcher.Stop()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	// The time of the most recent event streamed for each FileWatch, so that
+	// only new events are written.
+	lastSent := make(map[string]metav1.MicroTime)
+	encoder := json.NewEncoder(w)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e, ok := <-watcher.ResultChan():
+			if !ok {
+				return
+			}
+			fw, ok := e.Object.(*v1alpha1.FileWatch)
+			if !ok {
+				continue
+			}
+
+			switch e.Type {
+			case watch.Added:
+				// Existing history was already reported before the client subscribed.
+				lastSent[fw.Name] = latestFileEventTime(fw)
+				continue
+			case watch.Deleted:
+				delete(lastSent, fw.Name)
+				continue
+			case watch.Modified:
+			default:
+				continue
+			}
+
+			last := lastSent[fw.Name]
+			for _, event := range fw.Status.FileEvents {
+				if !last.Before(&event.Time) {
+					continue
+				}
+				err := encoder.Encode(FileWatchEventLine{
+					Name:         fw.Name,
+					Time:         event.Time,
+					SeenFiles:    event.SeenFiles,
+					DeletedFiles: event.DeletedFiles,
+				})
+				if err != nil {
+					return
+				}
+			}
+			lastSent[fw.Name] = latestFileEventTime(fw)
+			flusher.Flush()
+		}
+	}
+}
+
+func latestFileEventTime(fw *v1alpha1.FileWatch) metav1.MicroTime {
+	if len(fw.Status.FileEvents) == 0 {
+		return metav1.MicroTime{}
+	}
+	return fw.Status.FileEvents[len(fw.Status.FileEvents)-1].Time
+}
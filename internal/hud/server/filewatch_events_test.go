@@ -0,0 +1,93 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/tilt-dev/tilt/internal/feature"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestFileWatchEventsDisabledByDefault(t *testing.T) {
+	f := newAPIServerFixture(t)
+	f.start()
+
+	resp := f.getFileWatchEvents(f.serverConfig.GenericConfig.LoopbackClientConfig)
+	defer func() { _ = resp.Body.Close() }()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestFileWatchEventsRequiresToken(t *testing.T) {
+	f := newAPIServerFixture(t)
+	f.enableFileWatchEvents()
+	f.start()
+
+	config := rest.CopyConfig(f.serverConfig.GenericConfig.LoopbackClientConfig)
+	config.BearerToken = "wrong-token"
+	resp := f.getFileWatchEvents(config)
+	defer func() { _ = resp.Body.Close() }()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestFileWatchEventsStream(t *testing.T) {
+	f := newAPIServerFixture(t)
+	f.enableFileWatchEvents()
+	f.start()
+
+	resp := f.getFileWatchEvents(f.serverConfig.GenericConfig.LoopbackClientConfig)
+	defer func() { _ = resp.Body.Close() }()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	cli, err := ctrlclient.New(f.serverConfig.GenericConfig.LoopbackClientConfig,
+		ctrlclient.Options{Scheme: v1alpha1.NewScheme()})
+	require.NoError(t, err)
+
+	fw := &v1alpha1.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-watch"},
+		Spec:       v1alpha1.FileWatchSpec{WatchedPaths: []string{mustCwd(t)}},
+	}
+	require.NoError(t, cli.Create(f.ctx, fw))
+
+	event := v1alpha1.FileEvent{
+		Time:      metav1.NewMicroTime(time.Now().Truncate(time.Microsecond)),
+		SeenFiles: []string{f.JoinPath("main.go")},
+	}
+	fw.Status.FileEvents = []v1alpha1.FileEvent{event}
+	require.NoError(t, cli.Status().Update(f.ctx, fw))
+
+	line, err := bufio.NewReader(resp.Body).ReadBytes('\n')
+	require.NoError(t, err)
+	var actual FileWatchEventLine
+	require.NoError(t, json.Unmarshal(line, &actual))
+	assert.Equal(t, "my-watch", actual.Name)
+	assert.True(t, event.Time.Equal(&actual.Time))
+	assert.Equal(t, event.SeenFiles, actual.SeenFiles)
+}
+
+func (f *apiserverFixture) enableFileWatchEvents() {
+	f.st.WithState(func(state *store.EngineState) {
+		state.Features = map[string]bool{feature.FileWatchEventsEndpoint: true}
+	})
+}
+
+func (f *apiserverFixture) getFileWatchEvents(config *rest.Config) *http.Response {
+	f.t.Helper()
+	httpClient, err := rest.HTTPClientFor(config)
+	require.NoError(f.t, err)
+
+	req, err := http.NewRequestWithContext(f.ctx, http.MethodGet, config.Host+fileWatchEventsPath, nil)
+	require.NoError(f.t, err)
+	resp, err := httpClient.Do(req)
+	require.NoError(f.t, err)
+	return resp
+}
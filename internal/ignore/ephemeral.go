@@ -16,7 +16,10 @@ import (
 // https://app.clubhouse.io/windmill/story/691/filter-out-ephemeral-file-changes
 var EphemeralPathMatcher = initEphemeralPathMatcher()
 
-func initEphemeralPathMatcher() model.PathMatcher {
+// EphemeralPatterns are the dockerignore patterns matched by EphemeralPathMatcher.
+var EphemeralPatterns = initEphemeralPatterns()
+
+func initEphemeralPatterns() []string {
 	golandPatterns := []string{"**/*___jb_old___", "**/*___jb_tmp___", "**/.idea/**"}
 	emacsPatterns := []string{"**/.#*", "**/#*#"}
 	// if .swp is taken (presumably because multiple vims are running in that dir),
@@ -40,8 +43,11 @@ func initEphemeralPathMatcher() model.PathMatcher {
 	allPatterns = append(allPatterns, vimPatterns...)
 	allPatterns = append(allPatterns, katePatterns...)
 	allPatterns = append(allPatterns, goPatterns...)
+	return allPatterns
+}
 
-	matcher, err := dockerignore.NewDockerPatternMatcher("/", allPatterns)
+func initEphemeralPathMatcher() model.PathMatcher {
+	matcher, err := dockerignore.NewDockerPatternMatcher("/", initEphemeralPatterns())
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,46 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// ExtensionMatcher matches files whose extension is NOT in an allowed set,
+// so that everything else is ignored.
+//
+// Directories never match, so that we still recurse into them to find files
+// with the allowed extensions.
+type ExtensionMatcher struct {
+	extensions map[string]bool
+}
+
+var _ model.PathMatcher = ExtensionMatcher{}
+
+// NewExtensionMatcher creates a matcher that only lets through files with
+// one of the given extensions. The leading dot is optional (".go" and "go"
+// are equivalent).
+func NewExtensionMatcher(extensions []string) ExtensionMatcher {
+	m := make(map[string]bool, len(extensions))
+	for _, ext := range extensions {
+		m["."+strings.TrimPrefix(ext, ".")] = true
+	}
+	return ExtensionMatcher{extensions: m}
+}
+
+func (m ExtensionMatcher) Matches(f string) (bool, error) {
+	if m.extensions[filepath.Ext(f)] {
+		return false, nil
+	}
+	info, err := os.Stat(f)
+	if err == nil && info.IsDir() {
+		return false, nil
+	}
+	return true, nil
+}
+
+func (m ExtensionMatcher) MatchesEntireDir(f string) (bool, error) {
+	return false, nil
+}
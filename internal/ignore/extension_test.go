@@ -0,0 +1,44 @@
+package ignore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+)
+
+func TestExtensionMatcher(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.WriteFile("main.go", "package main")
+	f.WriteFile("api.proto", "syntax = \"proto3\";")
+	f.WriteFile("README.md", "hello")
+	f.MkdirAll("pkg")
+	f.MkdirAll("pkg.d")
+
+	m := NewExtensionMatcher([]string{".go", "proto"})
+	cases := []struct {
+		path     string
+		expected bool
+	}{
+		{"main.go", false},
+		{"api.proto", false},
+		{"README.md", true},
+		{"pkg", false},
+		{"pkg.d", false},
+		{"deleted.go", false},
+		{"deleted.md", true},
+	}
+	for _, c := range cases {
+		t.Run(c.path, func(t *testing.T) {
+			actual, err := m.Matches(f.JoinPath(c.path))
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, actual)
+		})
+	}
+
+	entireDir, err := m.MatchesEntireDir(f.JoinPath("pkg"))
+	require.NoError(t, err)
+	assert.False(t, entireDir)
+}
@@ -0,0 +1,36 @@
+package ignore
+
+import (
+	"os"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// FileAgeMatcher matches files that were last modified longer ago than a maximum age.
+//
+// A deleted file has no modification time, so any file that can't be stat'd is not matched.
+type FileAgeMatcher struct {
+	maxAge time.Duration
+	clock  clockwork.Clock
+}
+
+var _ model.PathMatcher = FileAgeMatcher{}
+
+func NewFileAgeMatcher(maxAge time.Duration, clock clockwork.Clock) FileAgeMatcher {
+	return FileAgeMatcher{maxAge: maxAge, clock: clock}
+}
+
+func (m FileAgeMatcher) Matches(f string) (bool, error) {
+	info, err := os.Stat(f)
+	if err != nil || info.IsDir() {
+		return false, nil
+	}
+	return m.clock.Since(info.ModTime()) > m.maxAge, nil
+}
+
+func (m FileAgeMatcher) MatchesEntireDir(f string) (bool, error) {
+	return false, nil
+}
@@ -0,0 +1,46 @@
+package ignore
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+)
+
+func TestFileAgeMatcher(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	clock := clockwork.NewFakeClock()
+	f.WriteFile("old", "hello")
+	f.WriteFile("new", "hello")
+	f.MkdirAll("dir")
+	require.NoError(t, os.Chtimes(f.JoinPath("old"), clock.Now(), clock.Now().Add(-2*time.Hour)))
+	require.NoError(t, os.Chtimes(f.JoinPath("new"), clock.Now(), clock.Now().Add(-time.Minute)))
+	require.NoError(t, os.Chtimes(f.JoinPath("dir"), clock.Now(), clock.Now().Add(-2*time.Hour)))
+
+	m := NewFileAgeMatcher(time.Hour, clock)
+	cases := []struct {
+		path     string
+		expected bool
+	}{
+		{"old", true},
+		{"new", false},
+		{"dir", false},
+		{"missing", false},
+	}
+	for _, c := range cases {
+		t.Run(c.path, func(t *testing.T) {
+			actual, err := m.Matches(f.JoinPath(c.path))
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, actual)
+		})
+	}
+
+	entireDir, err := m.MatchesEntireDir(f.JoinPath("dir"))
+	require.NoError(t, err)
+	assert.False(t, entireDir)
+}
@@ -0,0 +1,33 @@
+package ignore
+
+import (
+	"os"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// FileSizeMatcher matches files larger than a maximum size.
+//
+// A file that's mid-write may not have reached its final size, and a deleted file
+// has no size at all, so any file that can't be stat'd is not matched.
+type FileSizeMatcher struct {
+	maxSize int64
+}
+
+var _ model.PathMatcher = FileSizeMatcher{}
+
+func NewFileSizeMatcher(maxSize int64) FileSizeMatcher {
+	return FileSizeMatcher{maxSize: maxSize}
+}
+
+func (m FileSizeMatcher) Matches(f string) (bool, error) {
+	info, err := os.Stat(f)
+	if err != nil || info.IsDir() {
+		return false, nil
+	}
+	return info.Size() > m.maxSize, nil
+}
+
+func (m FileSizeMatcher) MatchesEntireDir(f string) (bool, error) {
+	return false, nil
+}
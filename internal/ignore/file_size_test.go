@@ -0,0 +1,40 @@
+package ignore
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+)
+
+func TestFileSizeMatcher(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.WriteFile("small", "hello")
+	f.WriteFile("large", strings.Repeat("x", 1024))
+	f.MkdirAll("dir")
+
+	m := NewFileSizeMatcher(100)
+	cases := []struct {
+		path     string
+		expected bool
+	}{
+		{"small", false},
+		{"large", true},
+		{"dir", false},
+		{"missing", false},
+	}
+	for _, c := range cases {
+		t.Run(c.path, func(t *testing.T) {
+			actual, err := m.Matches(f.JoinPath(c.path))
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, actual)
+		})
+	}
+
+	entireDir, err := m.MatchesEntireDir(f.JoinPath("dir"))
+	require.NoError(t, err)
+	assert.False(t, entireDir)
+}
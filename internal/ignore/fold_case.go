@@ -0,0 +1,62 @@
+package ignore
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/tilt-dev/tilt/internal/dockerignore"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// foldCaseMatcher matches paths without regard to case.
+//
+// The underlying matcher must have been created with lower-cased paths and patterns.
+type foldCaseMatcher struct {
+	matcher model.PathMatcher
+}
+
+var _ model.PathMatcher = foldCaseMatcher{}
+
+func newFoldCaseDirectoryMatcher(dir string) (model.PathMatcher, error) {
+	dir, err := foldCaseAbs(dir)
+	if err != nil {
+		return nil, err
+	}
+	return foldCaseMatcher{matcher: DirectoryMatcher{dir: dir}}, nil
+}
+
+func newFoldCaseDockerMatcher(basePath string, patterns []string) (model.PathMatcher, error) {
+	basePath, err := foldCaseAbs(basePath)
+	if err != nil {
+		return nil, err
+	}
+	lower := make([]string, len(patterns))
+	for i, p := range patterns {
+		lower[i] = strings.ToLower(p)
+	}
+	m, err := dockerignore.NewDockerPatternMatcher(basePath, lower)
+	if err != nil {
+		return nil, err
+	}
+	return foldCaseMatcher{matcher: m}, nil
+}
+
+// foldCaseAbs lower-cases a path after making it absolute, so that a relative
+// path is resolved against the working directory as-is.
+func foldCaseAbs(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get abs path of '%s'", path)
+	}
+	return strings.ToLower(abs), nil
+}
+
+func (m foldCaseMatcher) Matches(f string) (bool, error) {
+	return m.matcher.Matches(strings.ToLower(f))
+}
+
+func (m foldCaseMatcher) MatchesEntireDir(f string) (bool, error) {
+	return m.matcher.MatchesEntireDir(strings.ToLower(f))
+}
@@ -0,0 +1,96 @@
+package ignore
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/tilt-dev/tilt/internal/dockerignore"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// NewGitignoreMatcher reads a file of gitignore style rules, rooted at the directory containing it.
+//
+// A missing file matches nothing, so that a watch can be created before the file exists.
+func NewGitignoreMatcher(path string) (model.PathMatcher, error) {
+	return newGitignoreMatcher(path, false)
+}
+
+func newGitignoreMatcher(path string, foldCase bool) (model.PathMatcher, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get abs path of '%s'", path)
+	}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return model.EmptyMatcher, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	patterns, err := ReadGitignorePatterns(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading %s", path)
+	}
+	if foldCase {
+		return newFoldCaseDockerMatcher(filepath.Dir(path), patterns)
+	}
+	return dockerignore.NewDockerPatternMatcher(filepath.Dir(path), patterns)
+}
+
+// ReadGitignorePatterns translates gitignore style rules into the equivalent dockerignore patterns.
+//
+// Dockerignore patterns are always anchored to the base path, so a gitignore pattern without a
+// slash (which matches at any depth) becomes a `**/` pattern. Like git, later rules take
+// precedence over earlier ones, so a `!` pattern can re-include a path ignored by a broader rule.
+//
+// Dockerignore has no way to express directory-only patterns, so a trailing slash is dropped
+// and the pattern also matches files with the same name.
+func ReadGitignorePatterns(r io.Reader) ([]string, error) {
+	var patterns []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if !strings.HasSuffix(line, `\ `) {
+			line = strings.TrimRight(line, " ")
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		negate := false
+		if strings.HasPrefix(line, "!") {
+			negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+			line = line[1:]
+		}
+
+		line = strings.TrimSuffix(line, "/")
+		if line == "" {
+			continue
+		}
+
+		if strings.Contains(line, "/") {
+			// Anchored to the directory containing the gitignore file.
+			line = strings.TrimPrefix(line, "/")
+		} else {
+			line = "**/" + line
+		}
+
+		if negate {
+			line = "!" + line
+		}
+		patterns = append(patterns, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return patterns, nil
+}
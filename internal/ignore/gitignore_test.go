@@ -0,0 +1,95 @@
+package ignore
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestReadGitignorePatterns(t *testing.T) {
+	contents := strings.Join([]string{
+		"# comment",
+		"",
+		"*.log",
+		"!important.log",
+		"/build",
+		"docs/*.md",
+		"node_modules/",
+		`\#hash`,
+		"trailing   ",
+	}, "\n")
+
+	patterns, err := ReadGitignorePatterns(strings.NewReader(contents))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"**/*.log",
+		"!**/important.log",
+		"build",
+		"docs/*.md",
+		"**/node_modules",
+		"**/#hash",
+		"**/trailing",
+	}, patterns)
+}
+
+func TestGitignoreFile(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.WriteFile(".gitignore", strings.Join([]string{
+		"*.log",
+		"!important.log",
+		"/build",
+		"docs/*.md",
+		"!docs/README.md",
+		"node_modules/",
+		"!*.swp",
+	}, "\n"))
+
+	ignores := []v1alpha1.IgnoreDef{{GitignoreFile: f.JoinPath(".gitignore")}}
+	filter := CreateFileChangeFilter(ignores)
+
+	cases := []struct {
+		change   string
+		expected bool
+	}{
+		{"main.go", false},
+		{"a.log", true},
+		{filepath.Join("sub", "a.log"), true},
+		{"important.log", false},
+		{filepath.Join("sub", "important.log"), false},
+		{filepath.Join("build", "out"), true},
+		{filepath.Join("sub", "build", "out"), false},
+		{filepath.Join("docs", "a.md"), true},
+		{filepath.Join("docs", "README.md"), false},
+		{filepath.Join("docs", "sub", "a.md"), false},
+		{filepath.Join("node_modules", "pkg", "index.js"), true},
+		{filepath.Join("sub", "node_modules", "pkg", "index.js"), true},
+
+		// ephemeral ignores still apply, even if the gitignore re-includes them
+		{filepath.Join("sub", ".main.go.swp"), true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.change, func(t *testing.T) {
+			actual, err := filter.Matches(f.JoinPath(c.change))
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, actual)
+		})
+	}
+}
+
+func TestGitignoreFileMissing(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+
+	ignores := []v1alpha1.IgnoreDef{{BasePath: f.Path(), GitignoreFile: f.JoinPath(".gitignore")}}
+	filter := CreateBuildContextFilter(ignores)
+
+	actual, err := filter.Matches(f.JoinPath("main.go"))
+	require.NoError(t, err)
+	assert.False(t, actual)
+}
@@ -26,15 +26,41 @@ func CreateFileChangeFilter(ignores []v1alpha1.IgnoreDef) model.PathMatcher {
 func ToMatchersBestEffort(ignores []v1alpha1.IgnoreDef) []model.PathMatcher {
 	var ignoreMatchers []model.PathMatcher
 	for _, ignoreDef := range ignores {
+		if ignoreDef.GitignoreFile != "" {
+			m, err := newGitignoreMatcher(ignoreDef.GitignoreFile, ignoreDef.CaseInsensitive)
+			if err == nil {
+				ignoreMatchers = append(ignoreMatchers, m)
+			}
+		}
+
 		if len(ignoreDef.Patterns) != 0 {
-			m, err := dockerignore.NewDockerPatternMatcher(
-				ignoreDef.BasePath,
-				append([]string{}, ignoreDef.Patterns...))
+			var m model.PathMatcher
+			var err error
+			if ignoreDef.CaseInsensitive {
+				m, err = newFoldCaseDockerMatcher(ignoreDef.BasePath, ignoreDef.Patterns)
+			} else {
+				m, err = dockerignore.NewDockerPatternMatcher(
+					ignoreDef.BasePath,
+					append([]string{}, ignoreDef.Patterns...))
+			}
 			if err == nil {
 				ignoreMatchers = append(ignoreMatchers, m)
 			}
-		} else {
-			m, err := NewDirectoryMatcher(ignoreDef.BasePath)
+		} else if ignoreDef.GitignoreFile == "" && len(ignoreDef.Regex) == 0 {
+			var m model.PathMatcher
+			var err error
+			if ignoreDef.CaseInsensitive {
+				m, err = newFoldCaseDirectoryMatcher(ignoreDef.BasePath)
+			} else {
+				m, err = NewDirectoryMatcher(ignoreDef.BasePath)
+			}
+			if err == nil {
+				ignoreMatchers = append(ignoreMatchers, m)
+			}
+		}
+
+		if len(ignoreDef.Regex) != 0 {
+			m, err := NewRegexMatcher(ignoreDef.BasePath, ignoreDef.Regex, ignoreDef.CaseInsensitive)
 			if err == nil {
 				ignoreMatchers = append(ignoreMatchers, m)
 			}
@@ -138,3 +138,87 @@ func TestIgnores(t *testing.T) {
 		})
 	}
 }
+
+func TestIgnoreNegation(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+
+	cases := []struct {
+		patterns []string
+		change   string
+		expected bool
+	}{
+		{[]string{"node_modules/**", "!node_modules/mypkg/dist/**"}, "node_modules/other/index.js", true},
+		{[]string{"node_modules/**", "!node_modules/mypkg/dist/**"}, "node_modules/mypkg/index.js", true},
+		{[]string{"node_modules/**", "!node_modules/mypkg/dist/**"}, "node_modules/mypkg/dist/index.js", false},
+		{[]string{"node_modules/**", "!node_modules/mypkg/dist/**"}, "node_modules/mypkg/dist/sub/index.js", false},
+		{[]string{"node_modules", "!node_modules/mypkg"}, "node_modules/mypkg/index.js", false},
+
+		// last match wins
+		{[]string{"!node_modules/mypkg/dist/**", "node_modules/**"}, "node_modules/mypkg/dist/index.js", true},
+		{[]string{"*.txt", "!keep.txt", "*.txt"}, "keep.txt", true},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("TestIgnoreNegation%d", i), func(t *testing.T) {
+			filter := CreateFileChangeFilter([]v1alpha1.IgnoreDef{{BasePath: f.Path(), Patterns: c.patterns}})
+			actual, err := filter.Matches(filepath.Join(f.Path(), c.change))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, c.expected, actual)
+		})
+	}
+
+	// A directory that contains re-included files can't be skipped entirely.
+	filter := CreateFileChangeFilter([]v1alpha1.IgnoreDef{{
+		BasePath: f.Path(),
+		Patterns: []string{"node_modules/**", "!node_modules/mypkg/dist/**"},
+	}})
+	actual, err := filter.MatchesEntireDir(filepath.Join(f.Path(), "node_modules", "mypkg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, actual)
+}
+
+func TestIgnoreCaseInsensitive(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.WriteFile(".gitignore", "*.LOG")
+
+	cases := []struct {
+		ignore          v1alpha1.IgnoreDef
+		change          string
+		caseSensitive   bool
+		caseInsensitive bool
+	}{
+		{v1alpha1.IgnoreDef{BasePath: f.Path(), Patterns: []string{"build"}}, "build/out", true, true},
+		{v1alpha1.IgnoreDef{BasePath: f.Path(), Patterns: []string{"build"}}, "Build/out", false, true},
+		{v1alpha1.IgnoreDef{BasePath: f.Path(), Patterns: []string{"Build"}}, "BUILD/out", false, true},
+		{v1alpha1.IgnoreDef{BasePath: f.Path(), Patterns: []string{"build"}}, "src/out", false, false},
+		{v1alpha1.IgnoreDef{BasePath: f.JoinPath("build")}, "BUILD/out", false, true},
+		{v1alpha1.IgnoreDef{GitignoreFile: f.JoinPath(".gitignore")}, "sub/a.log", false, true},
+		{v1alpha1.IgnoreDef{GitignoreFile: f.JoinPath(".gitignore")}, "sub/a.go", false, false},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("TestIgnoreCaseInsensitive%d", i), func(t *testing.T) {
+			change := filepath.Join(f.Path(), filepath.FromSlash(c.change))
+
+			filter := CreateBuildContextFilter([]v1alpha1.IgnoreDef{c.ignore})
+			actual, err := filter.Matches(change)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, c.caseSensitive, actual, "case sensitive")
+
+			ignore := c.ignore
+			ignore.CaseInsensitive = true
+			filter = CreateBuildContextFilter([]v1alpha1.IgnoreDef{ignore})
+			actual, err = filter.Matches(change)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, c.caseInsensitive, actual, "case insensitive")
+		})
+	}
+}
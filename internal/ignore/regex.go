@@ -0,0 +1,81 @@
+package ignore
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+
+	"github.com/pkg/errors"
+
+	"github.com/tilt-dev/tilt/internal/ospath"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// RegexMatcher matches paths under a base directory whose relative path matches
+// any of a set of regular expressions.
+type RegexMatcher struct {
+	basePath string
+	regexes  []*regexp.Regexp
+}
+
+var _ model.PathMatcher = RegexMatcher{}
+
+// NewRegexMatcher compiles regexes, which are matched against slash-separated paths
+// relative to basePath. If caseInsensitive, both basePath and the regexes are matched
+// without regard to case.
+func NewRegexMatcher(basePath string, regexes []string, caseInsensitive bool) (model.PathMatcher, error) {
+	compiled, err := CompileRegexes(regexes, caseInsensitive)
+	if err != nil {
+		return nil, err
+	}
+	if caseInsensitive {
+		folded, err := foldCaseAbs(basePath)
+		if err != nil {
+			return nil, err
+		}
+		return foldCaseMatcher{matcher: RegexMatcher{basePath: folded, regexes: compiled}}, nil
+	}
+	basePath, err = filepath.Abs(basePath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get abs path of '%s'", basePath)
+	}
+	return RegexMatcher{basePath: basePath, regexes: compiled}, nil
+}
+
+// CompileRegexes compiles the regexes of an IgnoreDef, reporting the first one that
+// doesn't compile.
+func CompileRegexes(regexes []string, caseInsensitive bool) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(regexes))
+	for _, r := range regexes {
+		expr := r
+		if caseInsensitive {
+			expr = "(?i)" + expr
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %q: %v", r, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+func (m RegexMatcher) Matches(f string) (bool, error) {
+	rel, ok := ospath.Child(m.basePath, f)
+	if !ok || rel == "." {
+		return false, nil
+	}
+	rel = filepath.ToSlash(rel)
+	for _, re := range m.regexes {
+		if re.MatchString(rel) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// MatchesEntireDir is always false, since a regex that matches a directory may
+// not match everything under it.
+func (m RegexMatcher) MatchesEntireDir(f string) (bool, error) {
+	return false, nil
+}
@@ -0,0 +1,69 @@
+package ignore
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestRegexMatcher(t *testing.T) {
+	base := filepath.Join(string(filepath.Separator), "src")
+	m, err := NewRegexMatcher(base, []string{`(^|/)[^/]*[0-9]{12}[^/]*$`, `^build/`}, false)
+	require.NoError(t, err)
+
+	cases := []struct {
+		path     string
+		expected bool
+	}{
+		{filepath.Join(base, "backup-202401021504.sql"), true},
+		{filepath.Join(base, "pkg", "snapshot-202401021504"), true},
+		{filepath.Join(base, "backup-2024.sql"), false},
+		{filepath.Join(base, "202401021504", "main.go"), false},
+		{filepath.Join(base, "build", "out.o"), true},
+		{filepath.Join(base, "pkg", "build", "out.o"), false},
+		{filepath.Join(base, "Build", "out.o"), false},
+		{base, false},
+		{filepath.Join(string(filepath.Separator), "other", "backup-202401021504.sql"), false},
+	}
+	for _, c := range cases {
+		t.Run(c.path, func(t *testing.T) {
+			actual, err := m.Matches(c.path)
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, actual)
+		})
+	}
+}
+
+func TestRegexMatcherCaseInsensitive(t *testing.T) {
+	base := filepath.Join(string(filepath.Separator), "src")
+	m, err := NewRegexMatcher(base, []string{`^build/`}, true)
+	require.NoError(t, err)
+
+	actual, err := m.Matches(filepath.Join(string(filepath.Separator), "SRC", "Build", "out.o"))
+	require.NoError(t, err)
+	assert.True(t, actual)
+}
+
+func TestRegexMatcherInvalid(t *testing.T) {
+	_, err := NewRegexMatcher("/src", []string{`\.tmp$`, `build(`}, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid regex "build("`)
+}
+
+func TestToMatchersBestEffortRegexOnly(t *testing.T) {
+	base := filepath.Join(string(filepath.Separator), "src")
+	matchers := ToMatchersBestEffort([]v1alpha1.IgnoreDef{{BasePath: base, Regex: []string{`\.tmp$`}}})
+	require.Len(t, matchers, 1)
+
+	// a regex-only ignore doesn't ignore everything under its base path
+	ignored, err := matchers[0].Matches(filepath.Join(base, "main.go"))
+	require.NoError(t, err)
+	assert.False(t, ignored)
+	ignored, err = matchers[0].Matches(filepath.Join(base, "main.tmp"))
+	require.NoError(t, err)
+	assert.True(t, ignored)
+}
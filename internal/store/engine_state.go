@@ -35,6 +35,9 @@ type EngineState struct {
 
 	TerminalMode TerminalMode
 
+	// How controllers format their log lines.
+	LogFormat LogFormat
+
 	// For synchronizing BuildController -- wait until engine records all builds started
 	// so far before starting another build
 	BuildControllerStartCount int
@@ -0,0 +1,43 @@
+package store
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+// LogFormat controls how controllers format the log lines they write to the store.
+type LogFormat int
+
+const (
+	// Human-readable text. This is the default.
+	LogFormatText LogFormat = iota
+
+	// One JSON object per line, with structured fields, for log aggregation pipelines.
+	LogFormatJSON
+)
+
+func (f *LogFormat) String() string {
+	if *f == LogFormatJSON {
+		return "json"
+	}
+	return "text"
+}
+
+func (f *LogFormat) Set(v string) error {
+	switch v {
+	case "text":
+		*f = LogFormatText
+	case "json":
+		*f = LogFormatJSON
+	default:
+		return fmt.Errorf("Unrecognized log format: %s. Allowed values: [text json]", v)
+	}
+	return nil
+}
+
+func (f *LogFormat) Type() string {
+	return "LogFormat"
+}
+
+var _ pflag.Value = new(LogFormat)
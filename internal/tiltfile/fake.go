@@ -10,6 +10,9 @@ type FakeTiltfileLoader struct {
 	Result   TiltfileLoadResult
 	Args     []string
 	Delegate TiltfileLoader
+
+	// The number of times Load has been called.
+	LoadCount int
 }
 
 var _ TiltfileLoader = &FakeTiltfileLoader{}
@@ -20,6 +23,7 @@ func NewFakeTiltfileLoader() *FakeTiltfileLoader {
 
 func (tfl *FakeTiltfileLoader) Load(ctx context.Context, tf *v1alpha1.Tiltfile, prevResult *TiltfileLoadResult) TiltfileLoadResult {
 	tfl.Args = tf.Spec.Args
+	tfl.LoadCount++
 	if tfl.Delegate != nil {
 		return tfl.Delegate.Load(ctx, tf, prevResult)
 	}
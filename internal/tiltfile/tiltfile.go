@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"go.starlark.net/starlark"
@@ -77,6 +78,17 @@ func (r TiltfileLoadResult) HasOrchestrator(orc model.Orchestrator) bool {
 	return false
 }
 
+// ReadsConfig returns true if the Tiltfile called into the config module, and so may
+// have used its args for more than selecting which resources to enable.
+func (r TiltfileLoadResult) ReadsConfig() bool {
+	for _, call := range r.BuiltinCalls {
+		if strings.HasPrefix(call.Name, "config.") {
+			return true
+		}
+	}
+	return false
+}
+
 func (r TiltfileLoadResult) WithAllManifestsEnabled() TiltfileLoadResult {
 	r.EnabledManifests = nil
 	for _, m := range r.Manifests {
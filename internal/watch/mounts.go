@@ -0,0 +1,54 @@
+package watch
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/tilt-dev/tilt/internal/ospath"
+)
+
+// MountPointsUnder returns the mount points strictly below any of roots.
+//
+// Some monitors (e.g., FSEvents on macOS or ReadDirectoryChangesW on Windows)
+// don't report changes on the far side of a mount point, so callers that want
+// to see changes inside mounted volumes can watch these paths explicitly.
+//
+// Detection is best-effort: it's implemented by reading /proc/self/mountinfo
+// on Linux and getfsstat(2) on macOS. On other platforms, it returns no mount
+// points.
+func MountPointsUnder(roots []string) ([]string, error) {
+	mounts, err := listMountPoints()
+	if err != nil {
+		return nil, err
+	}
+
+	var absRoots []string
+	for _, root := range roots {
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			return nil, err
+		}
+		// mount points are reported with symlinks resolved (e.g., /private/var on macOS)
+		if real, err := filepath.EvalSymlinks(abs); err == nil {
+			abs = real
+		}
+		absRoots = append(absRoots, abs)
+	}
+
+	seen := make(map[string]bool)
+	var result []string
+	for _, m := range mounts {
+		if seen[m] {
+			continue
+		}
+		for _, root := range absRoots {
+			if m != root && ospath.IsChild(root, m) {
+				seen[m] = true
+				result = append(result, m)
+				break
+			}
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
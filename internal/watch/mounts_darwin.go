@@ -0,0 +1,38 @@
+package watch
+
+import (
+	"fmt"
+	"syscall"
+)
+
+func listMountPoints() ([]string, error) {
+	n, err := syscall.Getfsstat(nil, mntNoWait)
+	if err != nil {
+		return nil, fmt.Errorf("listing mount points: %v", err)
+	}
+	buf := make([]syscall.Statfs_t, n)
+	n, err = syscall.Getfsstat(buf, mntNoWait)
+	if err != nil {
+		return nil, fmt.Errorf("listing mount points: %v", err)
+	}
+
+	result := make([]string, 0, n)
+	for _, fs := range buf[:n] {
+		result = append(result, cString(fs.Mntonname[:]))
+	}
+	return result, nil
+}
+
+// MNT_NOWAIT from <sys/mount.h>: don't block on unresponsive (e.g., network) filesystems.
+const mntNoWait = 2
+
+func cString(b []int8) string {
+	out := make([]byte, 0, len(b))
+	for _, c := range b {
+		if c == 0 {
+			break
+		}
+		out = append(out, byte(c))
+	}
+	return string(out)
+}
@@ -0,0 +1,61 @@
+package watch
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func listMountPoints() ([]string, error) {
+	f, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return nil, fmt.Errorf("listing mount points: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+	return parseMountInfo(f)
+}
+
+// parseMountInfo reads the mount points out of a /proc/[pid]/mountinfo file.
+//
+// Each line looks like:
+//
+//	36 35 98:0 /mnt1 /mnt/parent rw,noatime master:1 - ext3 /dev/root rw,errors=continue
+//
+// where the fifth field is the mount point, with whitespace and backslashes octal-escaped.
+func parseMountInfo(r io.Reader) ([]string, error) {
+	var result []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := bytes.Fields(scanner.Bytes())
+		if len(fields) < 5 {
+			continue
+		}
+		result = append(result, unescapeMountInfo(string(fields[4])))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("listing mount points: %v", err)
+	}
+	return result, nil
+}
+
+func unescapeMountInfo(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+4 <= len(s) {
+			if n, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
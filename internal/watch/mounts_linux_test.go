@@ -0,0 +1,51 @@
+package watch
+
+import (
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMountInfo(t *testing.T) {
+	mountInfo := `22 1 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw
+36 22 98:0 /mnt1 /mnt/parent rw,noatime master:1 - ext3 /dev/root rw,errors=continue
+37 22 0:33 / /home/dev/my\040volume rw,relatime - tmpfs tmpfs rw
+`
+	mounts, err := parseMountInfo(strings.NewReader(mountInfo))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/", "/mnt/parent", "/home/dev/my volume"}, mounts)
+}
+
+func TestWatchAcrossMountBoundary(t *testing.T) {
+	f := newNotifyFixture(t)
+
+	root := f.TempDir("root")
+	mnt := filepath.Join(root, "sub", "mnt")
+	f.MkdirAll(mnt)
+	if err := syscall.Mount("tmpfs", mnt, "tmpfs", 0, ""); err != nil {
+		t.Skipf("mounting a tmpfs requires privileges: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = syscall.Unmount(mnt, syscall.MNT_DETACH)
+	})
+
+	mounts, err := MountPointsUnder([]string{root})
+	require.NoError(t, err)
+	require.Equal(t, []string{mnt}, mounts)
+
+	f.watch(root)
+	for _, m := range mounts {
+		f.watch(m)
+	}
+
+	f.fsync()
+	f.events = nil
+
+	changeFilePath := filepath.Join(mnt, "change")
+	f.WriteFile(changeFilePath, "change")
+	f.assertEvents(changeFilePath)
+}
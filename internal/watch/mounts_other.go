@@ -0,0 +1,9 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package watch
+
+// Mount point detection isn't implemented on this platform.
+func listMountPoints() ([]string, error) {
+	return nil, nil
+}
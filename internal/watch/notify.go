@@ -1,6 +1,7 @@
 package watch
 
 import (
+	"errors"
 	"expvar"
 	"fmt"
 	"os"
@@ -8,6 +9,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"syscall"
 
 	"github.com/tilt-dev/tilt/pkg/logger"
 )
@@ -18,19 +20,44 @@ var (
 
 type FileEvent struct {
 	path string
+	op   Op
+}
+
+// Op describes what happened to a file. It's a bit mask, since a single event may
+// combine several operations. It's zero if the monitor couldn't tell.
+type Op uint32
+
+const (
+	OpCreate Op = 1 << iota
+	OpWrite
+	OpRemove
+	OpRename
+)
+
+// Has reports whether op includes any of other.
+func (op Op) Has(other Op) bool {
+	return op&other != 0
 }
 
 func NewFileEvent(p string) FileEvent {
+	return NewFileEventWithOp(p, 0)
+}
+
+func NewFileEventWithOp(p string, op Op) FileEvent {
 	if !filepath.IsAbs(p) {
 		panic(fmt.Sprintf("NewFileEvent only accepts absolute paths. Actual: %s", p))
 	}
-	return FileEvent{path: p}
+	return FileEvent{path: p, op: op}
 }
 
 func (e FileEvent) Path() string {
 	return e.path
 }
 
+func (e FileEvent) Op() Op {
+	return e.op
+}
+
 type Notify interface {
 	// Start watching the paths set at init time
 	Start() error
@@ -45,6 +72,21 @@ type Notify interface {
 	Errors() chan error
 }
 
+// WatchCounter is implemented by monitors that can report how many OS-level
+// watches (e.g., inotify watch descriptors) they hold.
+type WatchCounter interface {
+	WatchCount() int
+}
+
+// WatchCount returns the number of OS-level watches held by the monitor, or 0
+// if the monitor doesn't keep track.
+func WatchCount(n Notify) int {
+	if c, ok := n.(WatchCounter); ok {
+		return c.WatchCount()
+	}
+	return 0
+}
+
 // When we specify directories to watch, we often want to
 // ignore some subset of the files under those directories.
 //
@@ -90,3 +132,16 @@ func DesiredWindowsBufferSize() int {
 func IsWindowsShortReadError(err error) bool {
 	return runtime.GOOS == "windows" && err != nil && strings.Contains(err.Error(), "short read")
 }
+
+// IsWatchLimitError returns true if the error indicates that the OS has run out
+// of file watches (e.g., inotify's max_user_watches) or file descriptors.
+func IsWatchLimitError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, syscall.ENOSPC) || errors.Is(err, syscall.EMFILE) {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "no space left on device") || strings.Contains(msg, "too many open files")
+}
@@ -270,6 +270,35 @@ func TestRemove(t *testing.T) {
 	f.assertEvents(path)
 }
 
+func TestEventOps(t *testing.T) {
+	f := newNotifyFixture(t)
+
+	root := f.TempDir("root")
+	path := filepath.Join(root, "change")
+	f.watch(root)
+	f.fsync()
+	f.events = nil
+
+	ops := func() Op {
+		var ops Op
+		for _, e := range f.events {
+			if e.Path() == path {
+				ops |= e.Op()
+			}
+		}
+		f.events = nil
+		return ops
+	}
+
+	f.WriteFile(path, "hello")
+	f.fsync()
+	assert.True(t, ops().Has(OpCreate), "expected a create")
+
+	require.NoError(t, os.Remove(path))
+	f.fsync()
+	assert.True(t, ops().Has(OpRemove), "expected a remove")
+}
+
 func TestRemoveAndAddBack(t *testing.T) {
 	f := newNotifyFixture(t)
 
@@ -673,9 +702,8 @@ func (f *notifyFixture) assertEvents(expected ...string) {
 	}
 
 	for i, actual := range f.events {
-		e := FileEvent{expected[i]}
-		if actual != e {
-			f.T().Fatalf("Got event %v (expected %v)", actual, e)
+		if actual.Path() != expected[i] {
+			f.T().Fatalf("Got event %v (expected %v)", actual, expected[i])
 		}
 	}
 }
@@ -0,0 +1,280 @@
+package watch
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/pkg/errors"
+
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// DefaultPollInterval is how often a polling watcher scans for changes if no interval is specified.
+const DefaultPollInterval = time.Second
+
+// ChangeDetection is how a polling watcher decides whether a file has changed between scans.
+type ChangeDetection string
+
+const (
+	// ChangeDetectionModTime compares modification times and sizes. It's the default.
+	ChangeDetectionModTime ChangeDetection = "ModTime"
+
+	// ChangeDetectionSize compares sizes only, for filesystems where modification
+	// times can't be trusted.
+	ChangeDetectionSize ChangeDetection = "Size"
+
+	// ChangeDetectionContentHash compares a hash of each file's contents. It reads every
+	// file on every scan, but catches changes that leave the size and modification time
+	// alone (e.g., on filesystems with coarse timestamps).
+	ChangeDetectionContentHash ChangeDetection = "ContentHash"
+)
+
+// A file watcher that periodically walks the watched paths and diffs
+// the modification time and size of every file it finds (or whatever
+// its ChangeDetection compares).
+//
+// This is much more expensive than the native watchers, but is a useful
+// fallback on filesystems where native events are unreliable or missing
+// entirely (e.g., NFS or some Docker for Mac bind mounts).
+type pollNotify struct {
+	paths    []string
+	ignore   PathMatcher
+	log      logger.Logger
+	clock    clockwork.Clock
+	interval time.Duration
+	detect   ChangeDetection
+
+	// The state of each file as of the most recent scan.
+	files map[string]fileState
+
+	events    chan FileEvent
+	errors    chan error
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+
+	// Only set with ChangeDetectionContentHash.
+	hash [sha256.Size]byte
+}
+
+// NewPollingWatcher creates a watcher that scans paths every interval. If detect is empty,
+// files are compared by ChangeDetectionModTime.
+func NewPollingWatcher(paths []string, ignore PathMatcher, interval time.Duration, detect ChangeDetection, clock clockwork.Clock, l logger.Logger) (Notify, error) {
+	if ignore == nil {
+		return nil, fmt.Errorf("NewPollingWatcher: ignore is nil")
+	}
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	switch detect {
+	case "":
+		detect = ChangeDetectionModTime
+	case ChangeDetectionModTime, ChangeDetectionSize, ChangeDetectionContentHash:
+	default:
+		return nil, fmt.Errorf("NewPollingWatcher: unknown change detection %q", detect)
+	}
+
+	absPaths := make([]string, 0, len(paths))
+	for _, p := range paths {
+		p, err := filepath.Abs(p)
+		if err != nil {
+			return nil, errors.Wrap(err, "NewPollingWatcher")
+		}
+		absPaths = append(absPaths, p)
+	}
+
+	return &pollNotify{
+		paths:    absPaths,
+		ignore:   ignore,
+		log:      l,
+		clock:    clock,
+		interval: interval,
+		detect:   detect,
+		events:   make(chan FileEvent),
+		errors:   make(chan error),
+		done:     make(chan struct{}),
+	}, nil
+}
+
+func (d *pollNotify) Start() error {
+	files, err := d.scan()
+	if err != nil {
+		return err
+	}
+	d.files = files
+
+	go d.loop()
+	return nil
+}
+
+func (d *pollNotify) Close() error {
+	d.closeOnce.Do(func() {
+		close(d.done)
+	})
+	return nil
+}
+
+func (d *pollNotify) Events() chan FileEvent {
+	return d.events
+}
+
+func (d *pollNotify) Errors() chan error {
+	return d.errors
+}
+
+func (d *pollNotify) loop() {
+	defer close(d.events)
+
+	ticker := d.clock.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.done:
+			return
+		case <-ticker.Chan():
+		}
+
+		files, err := d.scan()
+		if err != nil {
+			select {
+			case d.errors <- err:
+			case <-d.done:
+				return
+			}
+			continue
+		}
+
+		changed := diffFileStates(d.files, files, d.detect)
+		d.files = files
+		for _, e := range changed {
+			select {
+			case d.events <- e:
+			case <-d.done:
+				return
+			}
+		}
+	}
+}
+
+// scan walks all the watched paths and records the state of every file that isn't ignored.
+func (d *pollNotify) scan() (map[string]fileState, error) {
+	files := make(map[string]fileState)
+	for _, root := range d.paths {
+		err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				// Files are allowed to disappear (or not exist yet) between scans.
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+
+			if entry.IsDir() {
+				skip, err := d.ignore.MatchesEntireDir(path)
+				if err != nil {
+					return errors.Wrap(err, "poll")
+				}
+				if skip && path != root {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			ignored, err := d.ignore.Matches(path)
+			if err != nil {
+				d.log.Infof("Error matching path %q: %v", path, err)
+			} else if ignored {
+				return nil
+			}
+
+			info, err := entry.Info()
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			state := fileState{modTime: info.ModTime(), size: info.Size()}
+			if d.detect == ChangeDetectionContentHash {
+				state.hash, err = hashFile(path)
+				if err != nil {
+					if os.IsNotExist(err) {
+						return nil
+					}
+					return err
+				}
+			}
+			files[path] = state
+			return nil
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "poll(%q)", root)
+		}
+	}
+	return files, nil
+}
+
+func hashFile(path string) ([sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+	file, err := os.Open(path)
+	if err != nil {
+		return sum, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return sum, err
+	}
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
+
+// diffFileStates returns events for the files that were created, modified, or deleted between
+// two scans, sorted by path.
+func diffFileStates(prev, next map[string]fileState, detect ChangeDetection) []FileEvent {
+	var changed []FileEvent
+	for path, state := range next {
+		prevState, ok := prev[path]
+		if !ok {
+			changed = append(changed, FileEvent{path, OpCreate})
+		} else if fileChanged(prevState, state, detect) {
+			changed = append(changed, FileEvent{path, OpWrite})
+		}
+	}
+	for path := range prev {
+		if _, ok := next[path]; !ok {
+			changed = append(changed, FileEvent{path, OpRemove})
+		}
+	}
+	sort.Slice(changed, func(i, j int) bool { return changed[i].path < changed[j].path })
+	return changed
+}
+
+func fileChanged(prev, next fileState, detect ChangeDetection) bool {
+	switch detect {
+	case ChangeDetectionSize:
+		return prev.size != next.size
+	case ChangeDetectionContentHash:
+		return prev.hash != next.hash
+	default:
+		return !prev.modTime.Equal(next.modTime) || prev.size != next.size
+	}
+}
+
+var _ Notify = &pollNotify{}
@@ -0,0 +1,216 @@
+package watch
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/dockerignore"
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+func TestPollCreateModifyDelete(t *testing.T) {
+	f := newPollFixture(t)
+	f.WriteFile(f.JoinPath("watched", "existing.txt"), "hello")
+	f.start(f.JoinPath("watched"))
+
+	f.WriteFile(f.JoinPath("watched", "new.txt"), "hello")
+	f.tick()
+	f.assertEvents(f.JoinPath("watched", "new.txt"))
+
+	f.WriteFile(f.JoinPath("watched", "existing.txt"), "hello world")
+	f.tick()
+	f.assertEvents(f.JoinPath("watched", "existing.txt"))
+
+	require.NoError(t, os.Remove(f.JoinPath("watched", "new.txt")))
+	f.tick()
+	f.assertEvents(f.JoinPath("watched", "new.txt"))
+}
+
+func TestPollEventOps(t *testing.T) {
+	f := newPollFixture(t)
+	path := f.JoinPath("watched", "file.txt")
+	f.start(f.JoinPath("watched"))
+
+	f.WriteFile(path, "hello")
+	f.tick()
+	assert.Equal(t, NewFileEventWithOp(path, OpCreate), f.nextEvent())
+
+	f.WriteFile(path, "hello world")
+	f.tick()
+	assert.Equal(t, NewFileEventWithOp(path, OpWrite), f.nextEvent())
+
+	require.NoError(t, os.Remove(path))
+	f.tick()
+	assert.Equal(t, NewFileEventWithOp(path, OpRemove), f.nextEvent())
+}
+
+func TestPollNoChanges(t *testing.T) {
+	f := newPollFixture(t)
+	f.WriteFile(f.JoinPath("watched", "existing.txt"), "hello")
+	f.start(f.JoinPath("watched"))
+
+	f.tick()
+	f.assertEvents()
+}
+
+func TestPollIgnore(t *testing.T) {
+	f := newPollFixture(t)
+	ignore, err := dockerignore.NewDockerPatternMatcher(f.Path(), []string{"watched/ignored"})
+	require.NoError(t, err)
+	f.ignore = ignore
+	f.start(f.JoinPath("watched"))
+
+	f.WriteFile(f.JoinPath("watched", "ignored", "file.txt"), "hello")
+	f.WriteFile(f.JoinPath("watched", "file.txt"), "hello")
+	f.tick()
+	f.assertEvents(f.JoinPath("watched", "file.txt"))
+}
+
+func TestPollNonexistentPath(t *testing.T) {
+	f := newPollFixture(t)
+	f.start(f.JoinPath("watched", "not-yet"))
+
+	f.WriteFile(f.JoinPath("watched", "not-yet", "file.txt"), "hello")
+	f.tick()
+	f.assertEvents(f.JoinPath("watched", "not-yet", "file.txt"))
+}
+
+func TestPollChangeDetection(t *testing.T) {
+	for _, tc := range []struct {
+		detect ChangeDetection
+		// Whether each kind of change is detected.
+		sameSizeAndModTime bool
+		touch              bool
+		resize             bool
+	}{
+		{"", false, true, true},
+		{ChangeDetectionModTime, false, true, true},
+		{ChangeDetectionSize, false, false, true},
+		{ChangeDetectionContentHash, true, false, true},
+	} {
+		name := string(tc.detect)
+		if name == "" {
+			name = "default"
+		}
+		t.Run(name, func(t *testing.T) {
+			f := newPollFixture(t)
+			f.detect = tc.detect
+			path := f.JoinPath("watched", "file.txt")
+			f.WriteFile(path, "hello")
+			info, err := os.Stat(path)
+			require.NoError(t, err)
+			f.start(f.JoinPath("watched"))
+
+			expected := func(detected bool) []string {
+				if detected {
+					return []string{path}
+				}
+				return nil
+			}
+
+			// Same size, and the modification time is put back, as if the write
+			// landed within the filesystem's timestamp resolution.
+			f.WriteFile(path, "world")
+			require.NoError(t, os.Chtimes(path, info.ModTime(), info.ModTime()))
+			f.tick()
+			f.assertEvents(expected(tc.sameSizeAndModTime)...)
+
+			modTime := info.ModTime().Add(time.Minute)
+			require.NoError(t, os.Chtimes(path, modTime, modTime))
+			f.tick()
+			f.assertEvents(expected(tc.touch)...)
+
+			f.WriteFile(path, "hello world")
+			f.tick()
+			f.assertEvents(expected(tc.resize)...)
+		})
+	}
+}
+
+func TestPollUnknownChangeDetection(t *testing.T) {
+	_, err := NewPollingWatcher(nil, EmptyMatcher{}, time.Second, "Bogus", clockwork.NewFakeClock(), logger.NewTestLogger(os.Stdout))
+	assert.EqualError(t, err, `NewPollingWatcher: unknown change detection "Bogus"`)
+}
+
+type pollFixture struct {
+	*tempdir.TempDirFixture
+	t      *testing.T
+	clock  clockwork.FakeClock
+	ignore PathMatcher
+	detect ChangeDetection
+	notify Notify
+}
+
+func newPollFixture(t *testing.T) *pollFixture {
+	f := &pollFixture{
+		TempDirFixture: tempdir.NewTempDirFixture(t),
+		t:              t,
+		clock:          clockwork.NewFakeClock(),
+		ignore:         EmptyMatcher{},
+	}
+	f.MkdirAll("watched")
+	t.Cleanup(func() {
+		if f.notify != nil {
+			_ = f.notify.Close()
+		}
+	})
+	return f
+}
+
+func (f *pollFixture) start(paths ...string) {
+	notify, err := NewPollingWatcher(paths, f.ignore, time.Second, f.detect, f.clock, logger.NewTestLogger(os.Stdout))
+	require.NoError(f.t, err)
+	require.NoError(f.t, notify.Start())
+	f.notify = notify
+}
+
+// tick advances the clock so that the poller runs exactly one scan.
+func (f *pollFixture) tick() {
+	f.clock.BlockUntil(1)
+	f.clock.Advance(time.Second)
+}
+
+func (f *pollFixture) nextEvent() FileEvent {
+	f.t.Helper()
+	select {
+	case e := <-f.notify.Events():
+		return e
+	case err := <-f.notify.Errors():
+		f.t.Fatal(err)
+	case <-time.After(time.Second):
+		f.t.Fatal("timed out waiting for an event")
+	}
+	return FileEvent{}
+}
+
+func (f *pollFixture) assertEvents(expected ...string) {
+	f.t.Helper()
+	var actual []string
+	for len(actual) < len(expected) {
+		select {
+		case e := <-f.notify.Events():
+			actual = append(actual, e.Path())
+		case err := <-f.notify.Errors():
+			f.t.Fatal(err)
+		case <-time.After(time.Second):
+			f.t.Fatalf("timed out waiting for events. expected: %v, actual: %v", expected, actual)
+		}
+	}
+
+	select {
+	case e := <-f.notify.Events():
+		f.t.Fatalf("unexpected event: %s", e.Path())
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if len(expected) == 0 {
+		actual = nil
+	}
+	assert.ElementsMatch(f.t, expected, actual)
+}
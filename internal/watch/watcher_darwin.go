@@ -58,7 +58,7 @@ func (d *darwinNotify) loop() {
 					continue
 				}
 
-				d.events <- NewFileEvent(e.Path)
+				d.events <- NewFileEventWithOp(e.Path, opFromFSEvents(e.Flags))
 			}
 		}
 	}
@@ -108,6 +108,13 @@ func (d *darwinNotify) Errors() chan error {
 	return d.errors
 }
 
+// WatchCount is the number of paths in the FSEvents stream. A single stream
+// watches each path recursively, so this is a rough equivalent of an inotify
+// watch count.
+func (d *darwinNotify) WatchCount() int {
+	return len(d.stream.Paths)
+}
+
 func newWatcher(paths []string, ignore PathMatcher, l logger.Logger) (*darwinNotify, error) {
 	dw := &darwinNotify{
 		ignore: ignore,
@@ -137,3 +144,23 @@ func newWatcher(paths []string, ignore PathMatcher, l logger.Logger) (*darwinNot
 }
 
 var _ Notify = &darwinNotify{}
+var _ WatchCounter = &darwinNotify{}
+
+// opFromFSEvents converts FSEvents flags. FSEvents coalesces changes to the same file,
+// so several ops may be set at once.
+func opFromFSEvents(flags fsevents.EventFlags) Op {
+	var result Op
+	if flags&fsevents.ItemCreated != 0 {
+		result |= OpCreate
+	}
+	if flags&(fsevents.ItemModified|fsevents.ItemInodeMetaMod) != 0 {
+		result |= OpWrite
+	}
+	if flags&fsevents.ItemRemoved != 0 {
+		result |= OpRemove
+	}
+	if flags&fsevents.ItemRenamed != 0 {
+		result |= OpRename
+	}
+	return result
+}
@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync/atomic"
 
 	"github.com/pkg/errors"
 
@@ -40,7 +41,7 @@ type naiveNotify struct {
 	events             chan fsnotify.Event
 	wrappedEvents      chan FileEvent
 	errors             chan error
-	numWatches         int64
+	numWatches         atomic.Int64
 }
 
 func (d *naiveNotify) Start() error {
@@ -61,6 +62,9 @@ func (d *naiveNotify) Start() error {
 		pathsToWatch = dedupePathsForRecursiveWatcher(pathsToWatch)
 	}
 
+	// Files are watched through their parent directory, so a list of files in the same
+	// directory only needs one watch.
+	watchedParents := make(map[string]bool)
 	for _, name := range pathsToWatch {
 		fi, err := os.Stat(name)
 		if err != nil && !os.IsNotExist(err) {
@@ -76,11 +80,12 @@ func (d *naiveNotify) Start() error {
 			if err != nil {
 				return errors.Wrapf(err, "notify.Add(%q)", name)
 			}
-		} else {
-			err = d.add(filepath.Dir(name))
+		} else if parent := filepath.Dir(name); !watchedParents[parent] {
+			err = d.add(parent)
 			if err != nil {
-				return errors.Wrapf(err, "notify.Add(%q)", filepath.Dir(name))
+				return errors.Wrapf(err, "notify.Add(%q)", parent)
 			}
+			watchedParents[parent] = true
 		}
 	}
 
@@ -128,11 +133,14 @@ func (d *naiveNotify) watchRecursively(dir string) error {
 }
 
 func (d *naiveNotify) Close() error {
-	numberOfWatches.Add(-d.numWatches)
-	d.numWatches = 0
+	numberOfWatches.Add(-d.numWatches.Swap(0))
 	return d.watcher.Close()
 }
 
+func (d *naiveNotify) WatchCount() int {
+	return int(d.numWatches.Load())
+}
+
 func (d *naiveNotify) Events() chan FileEvent {
 	return d.wrappedEvents
 }
@@ -168,7 +176,7 @@ func (d *naiveNotify) loop() {
 				continue
 			}
 
-			d.wrappedEvents <- FileEvent{e.Name}
+			d.wrappedEvents <- FileEvent{e.Name, opFromFsnotify(e.Op)}
 			continue
 		}
 
@@ -176,7 +184,7 @@ func (d *naiveNotify) loop() {
 			if !d.shouldNotify(e.Name) {
 				continue
 			}
-			d.wrappedEvents <- FileEvent{e.Name}
+			d.wrappedEvents <- FileEvent{e.Name, opFromFsnotify(e.Op)}
 			continue
 		}
 
@@ -191,7 +199,7 @@ func (d *naiveNotify) loop() {
 			}
 
 			if d.shouldNotify(path) {
-				d.wrappedEvents <- FileEvent{path}
+				d.wrappedEvents <- FileEvent{path, OpCreate}
 			}
 
 			// TODO(dmiller): symlinks 😭
@@ -294,7 +302,7 @@ func (d *naiveNotify) add(path string) error {
 	if err != nil {
 		return err
 	}
-	d.numWatches++
+	d.numWatches.Add(1)
 	numberOfWatches.Add(1)
 	return nil
 }
@@ -346,6 +354,7 @@ func newWatcher(paths []string, ignore PathMatcher, l logger.Logger) (*naiveNoti
 }
 
 var _ Notify = &naiveNotify{}
+var _ WatchCounter = &naiveNotify{}
 
 func greatestExistingAncestors(paths []string) ([]string, error) {
 	result := []string{}
@@ -358,3 +367,22 @@ func greatestExistingAncestors(paths []string) ([]string, error) {
 	}
 	return result, nil
 }
+
+// opFromFsnotify converts an fsnotify op. Attribute changes are treated as writes,
+// since that's how touching a file to trigger a change shows up on Linux.
+func opFromFsnotify(op fsnotify.Op) Op {
+	var result Op
+	if op&fsnotify.Create != 0 {
+		result |= OpCreate
+	}
+	if op&(fsnotify.Write|fsnotify.Chmod) != 0 {
+		result |= OpWrite
+	}
+	if op&fsnotify.Remove != 0 {
+		result |= OpRemove
+	}
+	if op&fsnotify.Rename != 0 {
+		result |= OpRename
+	}
+	return result
+}
@@ -12,10 +12,53 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/pkg/logger"
 )
 
+func TestWatchFileListOncePerParent(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	var paths []string
+	for _, dir := range []string{"a", "b"} {
+		for i := 0; i < 20; i++ {
+			p := f.JoinPath(dir, strconv.Itoa(i))
+			f.WriteFile(p, "initial data")
+			paths = append(paths, p)
+		}
+	}
+	sibling := f.JoinPath("a", "sibling")
+	f.WriteFile(sibling, "initial data")
+
+	n, err := newWatcher(paths, EmptyMatcher{}, logger.NewTestLogger(os.Stdout))
+	require.NoError(t, err)
+	require.NoError(t, n.Start())
+	defer func() { _ = n.Close() }()
+	require.Equal(t, 2, n.WatchCount())
+
+	// Events arrive in order, so once the change to a watched file is seen, the
+	// change to its sibling would have been seen too.
+	f.WriteFile(sibling, "changed")
+	f.WriteFile(paths[0], "changed")
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case e := <-n.Events():
+			require.NotEqual(t, sibling, e.Path(), "sibling of a watched file was reported")
+			if e.Path() == paths[0] {
+				return
+			}
+		case err := <-n.Errors():
+			t.Fatal(err)
+		case <-timeout:
+			t.Fatalf("change to %s was never reported", paths[0])
+		}
+	}
+}
+
 func TestDontWatchEachFile(t *testing.T) {
 	if runtime.GOOS != "linux" {
 		t.Skip("This test uses linux-specific inotify checks")
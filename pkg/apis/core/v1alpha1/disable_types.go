@@ -10,8 +10,34 @@ type DisableSource struct {
 	// Disabled by multiple ConfigMap values, which must all be set to disabled
 	// to disable the object.
 	EveryConfigMap []ConfigMapDisableSource `json:"everyConfigMap,omitempty" protobuf:"bytes,3,rep,name=everyConfigMap"`
+
+	// Disabled by the existence of a file.
+	//
+	// +optional
+	File *FileDisableSource `json:"file,omitempty" protobuf:"bytes,4,opt,name=file"`
+
+	// Disabled outside of a daily time window.
+	//
+	// +optional
+	Schedule *ScheduleDisableSource `json:"schedule,omitempty" protobuf:"bytes,5,opt,name=schedule"`
+
+	// Disabled by an environment variable of the Tilt process.
+	//
+	// +optional
+	EnvVar *EnvVarDisableSource `json:"envVar,omitempty" protobuf:"bytes,6,opt,name=envVar"`
 }
 
+// DisableSourcePolicy determines how the results of multiple DisableSources are combined.
+type DisableSourcePolicy string
+
+const (
+	// The object is disabled if any of its sources is disabled.
+	DisableSourcePolicyAnyDisabled DisableSourcePolicy = "AnyDisabled"
+
+	// The object is disabled only if all of its sources are disabled.
+	DisableSourcePolicyAllDisabled DisableSourcePolicy = "AllDisabled"
+)
+
 // Specifies a ConfigMap to control a DisableSource
 type ConfigMapDisableSource struct {
 	// The name of the ConfigMap
@@ -21,6 +47,54 @@ type ConfigMapDisableSource struct {
 	Key string `json:"key" protobuf:"bytes,2,opt,name=key"`
 }
 
+// Specifies a sentinel file to control a DisableSource
+type FileDisableSource struct {
+	// The path of the sentinel file. The object is disabled while it exists.
+	//
+	// +tilt:local-path=true
+	Path string `json:"path" protobuf:"bytes,1,opt,name=path"`
+
+	// If true, the object is instead disabled while the file does not exist.
+	//
+	// +optional
+	Invert bool `json:"invert,omitempty" protobuf:"varint,2,opt,name=invert"`
+}
+
+// Specifies a daily time window to control a DisableSource. The object is
+// disabled outside of the window.
+type ScheduleDisableSource struct {
+	// The time of day the window starts, in 24-hour "HH:MM" format.
+	ActiveStart string `json:"activeStart" protobuf:"bytes,1,opt,name=activeStart"`
+
+	// The time of day the window ends, in 24-hour "HH:MM" format.
+	//
+	// If it's earlier than ActiveStart, the window spans midnight. If it's equal
+	// to ActiveStart, the object is never disabled.
+	ActiveEnd string `json:"activeEnd" protobuf:"bytes,2,opt,name=activeEnd"`
+
+	// The IANA name of the time zone the times are in (e.g., "America/New_York").
+	//
+	// Defaults to the local time zone of the Tilt process.
+	//
+	// +optional
+	TimeZone string `json:"timeZone,omitempty" protobuf:"bytes,3,opt,name=timeZone"`
+}
+
+// Specifies an environment variable to control a DisableSource. It's read from
+// the environment of the Tilt process whenever the object is reconciled.
+type EnvVarDisableSource struct {
+	// The name of the environment variable.
+	Name string `json:"name" protobuf:"bytes,1,opt,name=name"`
+
+	// The value that disables the object.
+	//
+	// If empty, the object is disabled while the variable is set to a true value
+	// (e.g., "1" or "true"), and enabled while it's unset or false.
+	//
+	// +optional
+	Value string `json:"value,omitempty" protobuf:"bytes,2,opt,name=value"`
+}
+
 type DisableStatus struct {
 	// Whether this is currently disabled. Deprecated in favor of `State`.
 	Disabled bool `json:"disabled" protobuf:"varint,1,opt,name=disabled"`
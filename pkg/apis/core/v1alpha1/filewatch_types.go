@@ -18,7 +18,9 @@ package v1alpha1
 
 import (
 	"context"
+	"strings"
 
+	apiresource "k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -57,30 +59,579 @@ type FileWatchList struct {
 type FileWatchSpec struct {
 	// WatchedPaths are paths of directories or files to watch for changes to. It cannot be empty.
 	//
+	// Entries may also be globs (e.g., `src/**/*.go`), in which case any file matching the glob is watched,
+	// including files created after the watch has started.
+	//
+	// Paths don't need to exist yet. The nearest existing ancestor is watched until they're
+	// created, so a watch on a lazily-created build output directory sees files created inside it.
+	//
+	// Entries may also be individual files. Only changes to the file itself are reported,
+	// not changes to its siblings.
+	//
 	// +tilt:local-path=true
 	WatchedPaths []string `json:"watchedPaths" protobuf:"bytes,1,rep,name=watchedPaths"`
 
 	// Ignores are optional rules to filter out a subset of changes matched by WatchedPaths.
+	//
+	// A change is filtered out if any rule matches it, so an ignore always wins over
+	// IncludeExtensions. The rules are checked in a fixed order: IncludeExtensions, then
+	// Ignores, then the ephemeral ignores, then the remaining filters (globs in
+	// WatchedPaths, MaxFileSize, IgnoreOlderThan, and MaxDepth). The first rule that
+	// matches is the one reported by DebugIgnores.
 	Ignores []IgnoreDef `json:"ignores,omitempty" protobuf:"bytes,2,rep,name=ignores"`
 	// Specifies how to disable this.
 	//
 	// +optional
 	DisableSource *DisableSource `json:"disableSource,omitempty" protobuf:"bytes,3,opt,name=disableSource"`
+
+	// MaxEventHistory is the maximum number of file events that will be retained on the FileWatch status.
+	//
+	// If unset, the controller default (20) is used.
+	//
+	// +optional
+	MaxEventHistory *int32 `json:"maxEventHistory,omitempty" protobuf:"varint,4,opt,name=maxEventHistory"`
+
+	// DebounceDuration is how long the watcher waits without seeing a new file change before
+	// emitting the batch of changes it has collected.
+	//
+	// If zero, the controller default (200ms) is used.
+	//
+	// +optional
+	DebounceDuration metav1.Duration `json:"debounceDuration,omitempty" protobuf:"bytes,5,opt,name=debounceDuration"`
+
+	// QuietPeriod is how long the watcher waits without seeing a new file change before
+	// emitting the batch of changes it has collected, with no upper bound on how long
+	// a batch stays open.
+	//
+	// Unlike DebounceDuration, which flushes a batch after 10s even if file changes are
+	// still coming in, a batch is only emitted once there's been a gap of QuietPeriod.
+	// Useful for build pipelines that write files in long streaks.
+	//
+	// Cannot be set together with DebounceDuration.
+	//
+	// +optional
+	QuietPeriod metav1.Duration `json:"quietPeriod,omitempty" protobuf:"bytes,22,opt,name=quietPeriod"`
+
+	// PerDirectoryDebounce debounces the file changes in each directory separately, so
+	// that a directory with a steady stream of changes doesn't hold up the changes in a
+	// quiet one.
+	//
+	// Each directory's changes are batched on their own DebounceDuration (or QuietPeriod)
+	// timer, and emitted as their own FileEvent. Changes are grouped by the directory
+	// that contains the changed path.
+	//
+	// +optional
+	PerDirectoryDebounce bool `json:"perDirectoryDebounce,omitempty" protobuf:"varint,44,opt,name=perDirectoryDebounce"`
+
+	// WatchMode determines how the filesystem is monitored for changes.
+	//
+	// Defaults to Native. Poll is slower and more expensive, but works on filesystems where native
+	// notifications are unreliable (e.g., NFS or some Docker bind mounts).
+	//
+	// +optional
+	WatchMode FileWatchMode `json:"watchMode,omitempty" protobuf:"bytes,6,opt,name=watchMode,casttype=FileWatchMode"`
+
+	// PollInterval is how often the watched paths are scanned for changes when WatchMode is Poll.
+	//
+	// If zero, defaults to 1s. Ignored for other watch modes.
+	//
+	// +optional
+	PollInterval metav1.Duration `json:"pollInterval,omitempty" protobuf:"bytes,7,opt,name=pollInterval"`
+
+	// ChangeDetection is how files are compared between scans when WatchMode is Poll.
+	//
+	// Defaults to ModTime. ContentHash reads every watched file on every scan, so it's
+	// best kept to small sets of files. Ignored for other watch modes.
+	//
+	// +optional
+	ChangeDetection FileWatchChangeDetection `json:"changeDetection,omitempty" protobuf:"bytes,33,opt,name=changeDetection,casttype=FileWatchChangeDetection"`
+
+	// DisableSources are additional ways to disable this, combined with DisableSource
+	// (if set) according to DisableSourcePolicy.
+	//
+	// +optional
+	DisableSources []DisableSource `json:"disableSources,omitempty" protobuf:"bytes,8,rep,name=disableSources"`
+
+	// DisableSourcePolicy determines how the results of multiple disable sources are combined.
+	//
+	// Defaults to AnyDisabled.
+	//
+	// +optional
+	DisableSourcePolicy DisableSourcePolicy `json:"disableSourcePolicy,omitempty" protobuf:"bytes,9,opt,name=disableSourcePolicy,casttype=DisableSourcePolicy"`
+
+	// FollowSymlinks watches the real targets of symlinked directories under WatchedPaths.
+	//
+	// Changes behind a symlink are reported under the symlink's path.
+	//
+	// +optional
+	FollowSymlinks bool `json:"followSymlinks,omitempty" protobuf:"varint,10,opt,name=followSymlinks"`
+
+	// WatchSymlinkTargets reports when a watched path that's a symlink is re-pointed
+	// (e.g., a `current -> releases/<ts>` link that's swapped on deploy).
+	//
+	// The symlink itself is watched, and each FileEvent that sees it lists the old and
+	// new targets in SymlinkTargetChanges. The watch then follows the new target.
+	//
+	// +optional
+	WatchSymlinkTargets bool `json:"watchSymlinkTargets,omitempty" protobuf:"varint,23,opt,name=watchSymlinkTargets"`
+
+	// DiffArchives reports which entries changed inside a watched path that's a zip
+	// archive (e.g., a .zip or .jar that's rebuilt), rather than only the archive itself.
+	//
+	// The archive's entries are indexed when the filesystem monitor starts, and each
+	// FileEvent that sees it lists the entries that were added, changed, or removed in
+	// ArchiveChanges. A rewrite that leaves every entry the same isn't reported at all.
+	// Only WatchedPaths entries that are archives are diffed, not archives under watched
+	// directories.
+	//
+	// +optional
+	DiffArchives bool `json:"diffArchives,omitempty" protobuf:"varint,41,opt,name=diffArchives"`
+
+	// TrackMoves reports a file that's renamed or moved within the watched paths as a
+	// move, rather than only as one path being deleted and another created.
+	//
+	// Files are followed by their inode, so the inode of every file under WatchedPaths
+	// that isn't ignored is recorded when the filesystem monitor starts, which takes some
+	// memory on large trees. A file whose size or modification time changed along with
+	// its path isn't reported as a move, since its inode may have been reused. Each FileEvent that sees both paths of a move lists it in
+	// Moves; both paths are still listed in SeenFiles, and the old one in DeletedFiles.
+	// Directories aren't followed. Not supported on Windows.
+	//
+	// +optional
+	TrackMoves bool `json:"trackMoves,omitempty" protobuf:"varint,43,opt,name=trackMoves"`
+
+	// DebugIgnores logs every path the filesystem monitor sees, along with whether it
+	// was ignored and which rule ignored it.
+	//
+	// This is noisy, and only intended for debugging ignores.
+	//
+	// +optional
+	DebugIgnores bool `json:"debugIgnores,omitempty" protobuf:"varint,11,opt,name=debugIgnores"`
+
+	// StaleAfter is how long the watch can go without seeing a file event before
+	// the Stale condition is set on the status.
+	//
+	// Useful for detecting filesystem monitors that have silently stopped delivering events.
+	// If zero, the watch is never considered stale.
+	//
+	// +optional
+	StaleAfter metav1.Duration `json:"staleAfter,omitempty" protobuf:"bytes,12,opt,name=staleAfter"`
+
+	// MaxBatchSize is the maximum number of files in a single FileEvent.
+	//
+	// A batch is recorded as soon as it has changes to this many files, without waiting
+	// for the debounce window, and larger batches of changes are split into multiple
+	// FileEvents, so that consumers can bound the work they do for each event and start
+	// on large changes sooner. If unset, batches are unbounded.
+	//
+	// +optional
+	MaxBatchSize int32 `json:"maxBatchSize,omitempty" protobuf:"varint,13,opt,name=maxBatchSize"`
+
+	// EventBufferSize is the number of file changes that can be queued between the filesystem
+	// monitor and the controller while a batch is being recorded.
+	//
+	// When the buffer is full, the monitor waits for room rather than dropping changes, and a
+	// warning is logged. Heavy watches with bursty changes may want a larger buffer. If unset,
+	// the controller default (1024) is used.
+	//
+	// +optional
+	EventBufferSize int32 `json:"eventBufferSize,omitempty" protobuf:"varint,24,opt,name=eventBufferSize"`
+
+	// ForceRescanToken triggers a full rescan of WatchedPaths when it changes.
+	//
+	// Every file under WatchedPaths (that isn't ignored) is reported in a FileEvent, as if it
+	// had changed. Useful for recovering from events missed while the watch was disabled.
+	//
+	// +optional
+	ForceRescanToken string `json:"forceRescanToken,omitempty" protobuf:"bytes,14,opt,name=forceRescanToken"`
+
+	// ReplayToken re-appends the most recent FileEvent as a new event when it changes, so
+	// that a consumer that starts watching after the event happened can still observe it.
+	//
+	// The new event has the same files, with a new Seq and Time. Nothing is replayed if
+	// there are no FileEvents yet, or while the watch is paused. Unlike ForceRescanToken,
+	// this doesn't walk WatchedPaths or restart the filesystem monitor.
+	//
+	// +optional
+	ReplayToken string `json:"replayToken,omitempty" protobuf:"bytes,40,opt,name=replayToken"`
+
+	// RecordBaseline lists every file under WatchedPaths (that isn't ignored) in
+	// Status.Baseline when the filesystem monitor starts, so that consumers know the
+	// starting state before any FileEvents.
+	//
+	// The listing is taken once, and kept when the monitor is restarted, unless
+	// WatchedPaths change. It can be large for big trees.
+	//
+	// +optional
+	RecordBaseline bool `json:"recordBaseline,omitempty" protobuf:"varint,36,opt,name=recordBaseline"`
+
+	// MaxFileSize ignores changes to files larger than this size, written as a
+	// Kubernetes quantity (e.g., `500Mi`).
+	//
+	// Useful for directories where large binary artifacts land that shouldn't trigger
+	// rebuilds. Files that can't be inspected (e.g., deleted files) are never ignored
+	// by size.
+	//
+	// +optional
+	MaxFileSize string `json:"maxFileSize,omitempty" protobuf:"bytes,15,opt,name=maxFileSize"`
+
+	// IncludeExtensions limits events to files with one of these extensions (e.g., `.go`).
+	//
+	// Files with any other extension are ignored. This is checked before Ignores, but a
+	// file with one of these extensions is still ignored if an ignore matches it. If empty,
+	// files are not filtered by extension.
+	//
+	// +optional
+	IncludeExtensions []string `json:"includeExtensions,omitempty" protobuf:"bytes,16,rep,name=includeExtensions"`
+
+	// MaxDepth limits how many directory levels below each entry in WatchedPaths are watched.
+	//
+	// A MaxDepth of 1 only watches the files and directories directly inside each watched path.
+	// Deeper directories aren't watched at all, which keeps the number of OS-level watches down
+	// for trees with large nested directories (e.g., vendored dependencies). If unset, there is no limit.
+	//
+	// +optional
+	MaxDepth int32 `json:"maxDepth,omitempty" protobuf:"varint,17,opt,name=maxDepth"`
+
+	// MaxEventsPerSecond limits how many FileEvents are recorded per second.
+	//
+	// Once the limit is reached, further batches of changes within the same second are merged
+	// into the most recent FileEvent (so no changed files are lost) and the Throttled condition
+	// is set. Protects consumers from event storms, e.g., when a tool rewrites an entire
+	// directory. If unset, events aren't rate limited.
+	//
+	// +optional
+	MaxEventsPerSecond int32 `json:"maxEventsPerSecond,omitempty" protobuf:"varint,18,opt,name=maxEventsPerSecond"`
+
+	// RelativeTo is a base directory for reporting relative paths.
+	//
+	// When set, each FileEvent also lists its SeenFiles relative to this directory in
+	// RelSeenFiles. SeenFiles are always absolute.
+	//
+	// +tilt:local-path=true
+	// +optional
+	RelativeTo string `json:"relativeTo,omitempty" protobuf:"bytes,19,opt,name=relativeTo"`
+
+	// PathRewrite rewrites the paths in each FileEvent from one prefix to another, e.g.,
+	// from where a remote workspace is mounted locally to where it lives remotely.
+	//
+	// RelSeenFiles are computed from the rewritten paths, with RelativeTo rewritten the
+	// same way. Everything else (WatchedPaths, Ignores, the paths in the status outside
+	// of FileEvents) stays local.
+	//
+	// Consumers inside Tilt match file events against local paths, so this is only
+	// useful for FileWatches read by other tools.
+	//
+	// +optional
+	PathRewrite *FileWatchPathRewrite `json:"pathRewrite,omitempty" protobuf:"bytes,42,opt,name=pathRewrite"`
+
+	// CollapseTo lists directories whose changes are reported as a single change to the
+	// directory itself.
+	//
+	// A change anywhere under one of these directories is reported in SeenFiles as the
+	// directory, rather than as the file that changed. If directories are nested, the
+	// closest one is reported. Useful when consumers only care that something under a
+	// directory changed, and the individual files would bloat the status.
+	//
+	// +tilt:local-path=true
+	// +optional
+	CollapseTo []string `json:"collapseTo,omitempty" protobuf:"bytes,37,rep,name=collapseTo"`
+
+	// LogPrefix starts each line the controller logs about this watch (e.g., errors from
+	// the filesystem monitor), to tell watches apart in sessions with many of them.
+	//
+	// Defaults to "filewatch <name>".
+	//
+	// +optional
+	LogPrefix string `json:"logPrefix,omitempty" protobuf:"bytes,38,opt,name=logPrefix"`
+
+	// SkipUnreadable skips directories under WatchedPaths that can't be read (e.g.,
+	// because of their permissions), instead of failing to start the filesystem monitor.
+	// The rest of the tree is still watched.
+	//
+	// Skipped directories are logged and listed in Status.SkippedPaths. They're found
+	// when the monitor starts, so one that becomes readable later isn't watched until
+	// the monitor is restarted. The watched paths themselves must still be readable.
+	//
+	// +optional
+	SkipUnreadable bool `json:"skipUnreadable,omitempty" protobuf:"varint,39,opt,name=skipUnreadable"`
+
+	// DisableEphemeralIgnores turns off Tilt's built-in ignores for editor temp files and
+	// similar (e.g., `.idea/`, `*.swp`).
+	//
+	// Useful for watching files that the built-in ignores would otherwise hide, such as
+	// `.idea/workspace.xml`. Ignores listed in the spec still apply.
+	//
+	// +optional
+	DisableEphemeralIgnores bool `json:"disableEphemeralIgnores,omitempty" protobuf:"varint,20,opt,name=disableEphemeralIgnores"`
+
+	// WatchHiddenFiles exempts files whose names start with a dot from Tilt's built-in
+	// ignores for editor temp files, while keeping those ignores for everything else.
+	//
+	// Hidden files that aren't editor temp files (e.g., `.env`) are watched either way.
+	// Ignores listed in the spec still apply.
+	//
+	// +optional
+	WatchHiddenFiles bool `json:"watchHiddenFiles,omitempty" protobuf:"varint,34,opt,name=watchHiddenFiles"`
+
+	// CrossMountBoundaries makes the watcher explicitly watch any filesystems mounted
+	// below the watched paths (e.g., a volume mounted inside a watched directory).
+	//
+	// Some native monitors (e.g., on macOS and Windows) don't report changes on the far
+	// side of a mount point. Mount points are detected once, when the watch starts, so
+	// volumes mounted later aren't picked up until the watch is restarted. Detection is
+	// best-effort and only supported on Linux and macOS.
+	//
+	// +optional
+	CrossMountBoundaries bool `json:"crossMountBoundaries,omitempty" protobuf:"varint,21,opt,name=crossMountBoundaries"`
+
+	// ReportDirectoryEvents reports directories that are created or deleted under the
+	// watched paths in each FileEvent's CreatedDirectories and DeletedDirectories.
+	//
+	// Useful for watching a directory of subdirectories (e.g., plugins) that come and go
+	// as a whole. Not supported with WatchMode Poll.
+	//
+	// +optional
+	ReportDirectoryEvents bool `json:"reportDirectoryEvents,omitempty" protobuf:"varint,25,opt,name=reportDirectoryEvents"`
+
+	// StatusUpdateInterval is the minimum time between writes of the status to the API server.
+	//
+	// File events seen in between are still recorded, and are written together in the next
+	// update. Errors and changes to the disable status are always written right away.
+	//
+	// Busy watches may want to set this to reduce load on the API server. If unset, the
+	// status is written as soon as events are recorded.
+	//
+	// +optional
+	StatusUpdateInterval metav1.Duration `json:"statusUpdateInterval,omitempty" protobuf:"bytes,26,opt,name=statusUpdateInterval"`
+
+	// SelfTest checks that the environment can deliver file events when the filesystem
+	// monitor starts, by watching a temporary directory and writing a file to it.
+	//
+	// The result is reported in the SelfTestPassed condition. Useful for catching
+	// environments where the native monitor silently sees nothing (e.g., some network
+	// filesystems and VMs). Not supported with WatchMode Poll.
+	//
+	// +optional
+	SelfTest bool `json:"selfTest,omitempty" protobuf:"varint,27,opt,name=selfTest"`
+
+	// SaveProfiles lists the editors whose atomic saves should be recognized.
+	//
+	// When an editor saves a file by writing temp files and renaming them over it, the
+	// changes to its temp files are collapsed into a single change to the file being saved.
+	// This is more precise than the built-in ephemeral ignores, and still applies when
+	// DisableEphemeralIgnores is set.
+	//
+	// +optional
+	SaveProfiles []FileWatchSaveProfile `json:"saveProfiles,omitempty" protobuf:"bytes,28,rep,name=saveProfiles,casttype=FileWatchSaveProfile"`
+
+	// WatchRestartInterval restarts the filesystem monitor after it's been running this
+	// long, as a mitigation for OS event streams that degrade over very long sessions.
+	//
+	// The new monitor is started before the old one is stopped, and events the old one
+	// had already received are kept, so no changes are missed across the restart. The
+	// event history is preserved. If unset, the monitor is only restarted when needed.
+	//
+	// +optional
+	WatchRestartInterval metav1.Duration `json:"watchRestartInterval,omitempty" protobuf:"bytes,29,opt,name=watchRestartInterval"`
+
+	// PreserveSeenFilesOrder reports the files in each FileEvent in the order they were
+	// first seen, rather than sorted.
+	//
+	// By default, SeenFiles are sorted so that the output is stable across platforms,
+	// whose monitors report the changes in a batch in different orders.
+	//
+	// +optional
+	PreserveSeenFilesOrder bool `json:"preserveSeenFilesOrder,omitempty" protobuf:"varint,30,opt,name=preserveSeenFilesOrder"`
+
+	// DisableMode determines what happens to the watch while it's disabled by its
+	// disable sources.
+	//
+	// Defaults to Stop.
+	//
+	// +optional
+	DisableMode FileWatchDisableMode `json:"disableMode,omitempty" protobuf:"bytes,31,opt,name=disableMode,casttype=FileWatchDisableMode"`
+
+	// IgnoreOlderThan ignores changes to files that were last modified longer ago than
+	// this, according to their mtime.
+	//
+	// Useful for directories where only new files matter (e.g., logs, where rotated
+	// files are touched long after they were last written). Files that can't be
+	// inspected (e.g., deleted files) are never ignored by age. If unset, files aren't
+	// filtered by age.
+	//
+	// +optional
+	IgnoreOlderThan metav1.Duration `json:"ignoreOlderThan,omitempty" protobuf:"bytes,32,opt,name=ignoreOlderThan"`
+
+	// WatchEventTypes limits the changes that are reported to the given types.
+	//
+	// Useful for config files, where only changes to the content matter. Changes that
+	// the filesystem monitor can't classify are always reported. If unset, changes of
+	// every type are reported.
+	//
+	// +optional
+	WatchEventTypes []FileWatchEventType `json:"watchEventTypes,omitempty" protobuf:"bytes,35,rep,name=watchEventTypes,casttype=FileWatchEventType"`
 }
 
+// FileWatchPathRewrite replaces a leading path prefix.
+type FileWatchPathRewrite struct {
+	// From is the prefix to replace. Only whole path components match, so /src matches
+	// /src/main.go but not /srv or /src2.
+	//
+	// +tilt:local-path=true
+	From string `json:"from" protobuf:"bytes,1,opt,name=from"`
+
+	// To is the prefix to replace it with.
+	To string `json:"to" protobuf:"bytes,2,opt,name=to"`
+}
+
+// FileWatchMode is the mechanism used to detect file changes.
+type FileWatchMode string
+
+const (
+	// FileWatchModeNative uses the OS filesystem notification APIs (e.g., inotify, FSEvents).
+	FileWatchModeNative FileWatchMode = "Native"
+
+	// FileWatchModePoll periodically walks the watched paths and compares file modification times and sizes.
+	FileWatchModePoll FileWatchMode = "Poll"
+)
+
+// FileWatchChangeDetection is how a polling FileWatch decides whether a file has changed.
+type FileWatchChangeDetection string
+
+const (
+	// FileWatchChangeDetectionModTime compares modification times and sizes.
+	FileWatchChangeDetectionModTime FileWatchChangeDetection = "ModTime"
+
+	// FileWatchChangeDetectionSize compares sizes only, for filesystems where
+	// modification times can't be trusted.
+	FileWatchChangeDetectionSize FileWatchChangeDetection = "Size"
+
+	// FileWatchChangeDetectionContentHash compares a hash of each file's contents. It
+	// catches changes that leave the size and modification time alone (e.g., on
+	// filesystems with coarse timestamps).
+	FileWatchChangeDetectionContentHash FileWatchChangeDetection = "ContentHash"
+)
+
+// FileWatchDisableMode is what a FileWatch does while it's disabled.
+type FileWatchDisableMode string
+
+const (
+	// FileWatchDisableModeStop stops the filesystem monitor and clears the event history.
+	// The monitor is set up again from scratch when the watch is enabled.
+	FileWatchDisableModeStop FileWatchDisableMode = "Stop"
+
+	// FileWatchDisableModePause keeps the filesystem monitor running and the event
+	// history intact, but discards file events. Events are recorded again as soon as
+	// the watch is enabled, without setting up the monitor again.
+	FileWatchDisableModePause FileWatchDisableMode = "Pause"
+)
+
+// FileWatchSaveProfile is an editor whose atomic saves a FileWatch can recognize.
+type FileWatchSaveProfile string
+
+const (
+	// FileWatchSaveProfileVim recognizes Vim's swap files (`.main.go.swp`), backup
+	// files (`main.go~`), and the `4913` file it writes to check directory permissions.
+	FileWatchSaveProfileVim FileWatchSaveProfile = "Vim"
+
+	// FileWatchSaveProfileEmacs recognizes Emacs's auto-save files (`#main.go#`), lock
+	// files (`.#main.go`), and backup files (`main.go~`).
+	FileWatchSaveProfileEmacs FileWatchSaveProfile = "Emacs"
+
+	// FileWatchSaveProfileJetBrains recognizes the `___jb_tmp___` and `___jb_old___`
+	// files that JetBrains IDEs (e.g., GoLand) write while saving.
+	FileWatchSaveProfileJetBrains FileWatchSaveProfile = "JetBrains"
+)
+
+// FileWatchEventType is a type of change to a file.
+type FileWatchEventType string
+
+const (
+	// FileWatchEventTypeCreate is a file being created.
+	FileWatchEventTypeCreate FileWatchEventType = "Create"
+
+	// FileWatchEventTypeWrite is a file's content or attributes (e.g., its mtime) changing.
+	FileWatchEventTypeWrite FileWatchEventType = "Write"
+
+	// FileWatchEventTypeRemove is a file being deleted.
+	FileWatchEventTypeRemove FileWatchEventType = "Remove"
+
+	// FileWatchEventTypeRename is a file being renamed or moved.
+	FileWatchEventTypeRename FileWatchEventType = "Rename"
+)
+
 // Describes sets of file paths that the FileWatch should ignore.
 type IgnoreDef struct {
-	// BasePath is the base path for the patterns. It cannot be empty.
+	// BasePath is the base path for the patterns.
+	//
+	// If empty, the patterns are evaluated relative to each of the WatchedPaths. It cannot be
+	// empty unless Patterns, PatternsConfigMap, GitignoreFile, or Regex is set.
 	//
-	// If no patterns are specified, everything under it will be recursively ignored.
+	// If no patterns (and no GitignoreFile or Regex) are specified, everything under it will be recursively ignored.
 	//
 	// +tilt:local-path=true
 	BasePath string `json:"basePath" protobuf:"bytes,1,opt,name=basePath"`
 
 	// Patterns are dockerignore style rules. Absolute-style patterns will be rooted to the BasePath.
 	//
+	// A pattern starting with `!` re-includes paths matched by an earlier pattern. When more than one
+	// pattern matches a path, the last one wins.
+	//
 	// See https://docs.docker.com/engine/reference/builder/#dockerignore-file.
 	Patterns []string `json:"patterns,omitempty" protobuf:"bytes,2,rep,name=patterns"`
+
+	// GitignoreFile is a path to a file of gitignore style rules (e.g., a repo's .gitignore).
+	//
+	// The rules are anchored to the directory containing the file, and follow git's semantics:
+	// a pattern without a slash matches at any depth, and a `!` pattern re-includes paths
+	// ignored by an earlier rule. A FileWatch re-reads the file whenever it changes.
+	//
+	// See https://git-scm.com/docs/gitignore.
+	//
+	// +tilt:local-path=true
+	// +optional
+	GitignoreFile string `json:"gitignoreFile,omitempty" protobuf:"bytes,3,opt,name=gitignoreFile"`
+
+	// CaseInsensitive matches BasePath, Patterns, GitignoreFile, and Regex rules without regard to case.
+	//
+	// Useful on case-insensitive filesystems (the default on macOS and Windows), where
+	// a pattern like `build` should also ignore changes reported under `Build`.
+	//
+	// +optional
+	CaseInsensitive bool `json:"caseInsensitive,omitempty" protobuf:"varint,4,opt,name=caseInsensitive"`
+
+	// PatternsConfigMap reads more Patterns from a ConfigMap key, one per line.
+	//
+	// They're added after Patterns, and follow the same rules. Blank lines and lines
+	// starting with `#` are skipped. The ConfigMap is re-read whenever it changes; if it
+	// (or the key) doesn't exist, no patterns are added.
+	//
+	// Only supported for FileWatch ignores.
+	//
+	// +optional
+	PatternsConfigMap *ConfigMapPatternsSource `json:"patternsConfigMap,omitempty" protobuf:"bytes,5,opt,name=patternsConfigMap"`
+
+	// Regex are regular expressions, in Go's RE2 syntax, for paths to ignore.
+	//
+	// Each is matched against the slash-separated path relative to BasePath, and
+	// isn't anchored, so use `^` and `$` to match the whole path. For example,
+	// `(^|/)[^/]*[0-9]{12}[^/]*$` ignores files with a 12-digit timestamp in the name.
+	// Paths outside BasePath never match.
+	//
+	// A regex that doesn't compile is reported in the FileWatch's SetupError.
+	//
+	// +optional
+	Regex []string `json:"regex,omitempty" protobuf:"bytes,6,rep,name=regex"`
+}
+
+// ConfigMapPatternsSource specifies a ConfigMap key that holds ignore patterns.
+type ConfigMapPatternsSource struct {
+	// The name of the ConfigMap.
+	Name string `json:"name" protobuf:"bytes,1,opt,name=name"`
+
+	// The key where the newline-delimited patterns are stored.
+	Key string `json:"key" protobuf:"bytes,2,opt,name=key"`
 }
 
 var _ resource.Object = &FileWatch{}
@@ -135,6 +686,207 @@ func (in *FileWatch) Validate(_ context.Context) field.ErrorList {
 			field.NewPath("spec", "watchedPaths"),
 			"cannot be an empty list"))
 	}
+	for i, ignore := range in.Spec.Ignores {
+		if ignore.BasePath == "" && len(ignore.Patterns) == 0 && ignore.PatternsConfigMap == nil && ignore.GitignoreFile == "" && len(ignore.Regex) == 0 {
+			fieldErrors = append(fieldErrors, field.Required(
+				field.NewPath("spec", "ignores").Index(i).Child("basePath"),
+				"must be set unless patterns, patternsConfigMap, gitignoreFile, or regex is set"))
+		}
+		if cm := ignore.PatternsConfigMap; cm != nil {
+			if cm.Name == "" {
+				fieldErrors = append(fieldErrors, field.Required(
+					field.NewPath("spec", "ignores").Index(i).Child("patternsConfigMap", "name"),
+					"cannot be empty"))
+			}
+			if cm.Key == "" {
+				fieldErrors = append(fieldErrors, field.Required(
+					field.NewPath("spec", "ignores").Index(i).Child("patternsConfigMap", "key"),
+					"cannot be empty"))
+			}
+		}
+	}
+	if in.Spec.MaxEventHistory != nil && *in.Spec.MaxEventHistory <= 0 {
+		fieldErrors = append(fieldErrors, field.Invalid(
+			field.NewPath("spec", "maxEventHistory"),
+			*in.Spec.MaxEventHistory,
+			"must be greater than 0"))
+	}
+	if in.Spec.MaxEventsPerSecond < 0 {
+		fieldErrors = append(fieldErrors, field.Invalid(
+			field.NewPath("spec", "maxEventsPerSecond"),
+			in.Spec.MaxEventsPerSecond,
+			"cannot be negative"))
+	}
+	if in.Spec.MaxDepth < 0 {
+		fieldErrors = append(fieldErrors, field.Invalid(
+			field.NewPath("spec", "maxDepth"),
+			in.Spec.MaxDepth,
+			"cannot be negative"))
+	}
+	if in.Spec.EventBufferSize < 0 {
+		fieldErrors = append(fieldErrors, field.Invalid(
+			field.NewPath("spec", "eventBufferSize"),
+			in.Spec.EventBufferSize,
+			"cannot be negative"))
+	}
+	if in.Spec.MaxBatchSize < 0 {
+		fieldErrors = append(fieldErrors, field.Invalid(
+			field.NewPath("spec", "maxBatchSize"),
+			in.Spec.MaxBatchSize,
+			"cannot be negative"))
+	}
+	if in.Spec.MaxFileSize != "" {
+		q, err := apiresource.ParseQuantity(in.Spec.MaxFileSize)
+		if err != nil {
+			fieldErrors = append(fieldErrors, field.Invalid(
+				field.NewPath("spec", "maxFileSize"),
+				in.Spec.MaxFileSize,
+				err.Error()))
+		} else if q.Sign() < 0 {
+			fieldErrors = append(fieldErrors, field.Invalid(
+				field.NewPath("spec", "maxFileSize"),
+				in.Spec.MaxFileSize,
+				"cannot be negative"))
+		}
+	}
+	for i, ext := range in.Spec.IncludeExtensions {
+		if strings.TrimPrefix(ext, ".") == "" {
+			fieldErrors = append(fieldErrors, field.Invalid(
+				field.NewPath("spec", "includeExtensions").Index(i),
+				ext,
+				"cannot be empty"))
+		}
+	}
+	for i, profile := range in.Spec.SaveProfiles {
+		switch profile {
+		case FileWatchSaveProfileVim, FileWatchSaveProfileEmacs, FileWatchSaveProfileJetBrains:
+		default:
+			fieldErrors = append(fieldErrors, field.NotSupported(
+				field.NewPath("spec", "saveProfiles").Index(i),
+				profile,
+				[]string{string(FileWatchSaveProfileVim), string(FileWatchSaveProfileEmacs), string(FileWatchSaveProfileJetBrains)}))
+		}
+	}
+	for i, t := range in.Spec.WatchEventTypes {
+		switch t {
+		case FileWatchEventTypeCreate, FileWatchEventTypeWrite, FileWatchEventTypeRemove, FileWatchEventTypeRename:
+		default:
+			fieldErrors = append(fieldErrors, field.NotSupported(
+				field.NewPath("spec", "watchEventTypes").Index(i),
+				t,
+				[]string{
+					string(FileWatchEventTypeCreate),
+					string(FileWatchEventTypeWrite),
+					string(FileWatchEventTypeRemove),
+					string(FileWatchEventTypeRename),
+				}))
+		}
+	}
+	if in.Spec.DebounceDuration.Duration < 0 {
+		fieldErrors = append(fieldErrors, field.Invalid(
+			field.NewPath("spec", "debounceDuration"),
+			in.Spec.DebounceDuration.Duration.String(),
+			"cannot be negative"))
+	}
+	if in.Spec.QuietPeriod.Duration < 0 {
+		fieldErrors = append(fieldErrors, field.Invalid(
+			field.NewPath("spec", "quietPeriod"),
+			in.Spec.QuietPeriod.Duration.String(),
+			"cannot be negative"))
+	} else if in.Spec.QuietPeriod.Duration > 0 && in.Spec.DebounceDuration.Duration > 0 {
+		fieldErrors = append(fieldErrors, field.Forbidden(
+			field.NewPath("spec", "quietPeriod"),
+			"cannot be set together with debounceDuration"))
+	}
+	if in.Spec.WatchRestartInterval.Duration < 0 {
+		fieldErrors = append(fieldErrors, field.Invalid(
+			field.NewPath("spec", "watchRestartInterval"),
+			in.Spec.WatchRestartInterval.Duration.String(),
+			"cannot be negative"))
+	}
+	if in.Spec.StatusUpdateInterval.Duration < 0 {
+		fieldErrors = append(fieldErrors, field.Invalid(
+			field.NewPath("spec", "statusUpdateInterval"),
+			in.Spec.StatusUpdateInterval.Duration.String(),
+			"cannot be negative"))
+	}
+	switch in.Spec.WatchMode {
+	case "", FileWatchModeNative:
+	case FileWatchModePoll:
+		if in.Spec.ReportDirectoryEvents {
+			fieldErrors = append(fieldErrors, field.Forbidden(
+				field.NewPath("spec", "reportDirectoryEvents"),
+				"is not supported with watchMode Poll"))
+		}
+		if in.Spec.SelfTest {
+			fieldErrors = append(fieldErrors, field.Forbidden(
+				field.NewPath("spec", "selfTest"),
+				"is not supported with watchMode Poll"))
+		}
+	default:
+		fieldErrors = append(fieldErrors, field.NotSupported(
+			field.NewPath("spec", "watchMode"),
+			in.Spec.WatchMode,
+			[]string{string(FileWatchModeNative), string(FileWatchModePoll)}))
+	}
+	switch in.Spec.DisableSourcePolicy {
+	case "", DisableSourcePolicyAnyDisabled, DisableSourcePolicyAllDisabled:
+	default:
+		fieldErrors = append(fieldErrors, field.NotSupported(
+			field.NewPath("spec", "disableSourcePolicy"),
+			in.Spec.DisableSourcePolicy,
+			[]string{string(DisableSourcePolicyAnyDisabled), string(DisableSourcePolicyAllDisabled)}))
+	}
+	switch in.Spec.ChangeDetection {
+	case "", FileWatchChangeDetectionModTime, FileWatchChangeDetectionSize, FileWatchChangeDetectionContentHash:
+	default:
+		fieldErrors = append(fieldErrors, field.NotSupported(
+			field.NewPath("spec", "changeDetection"),
+			in.Spec.ChangeDetection,
+			[]string{
+				string(FileWatchChangeDetectionModTime),
+				string(FileWatchChangeDetectionSize),
+				string(FileWatchChangeDetectionContentHash),
+			}))
+	}
+	switch in.Spec.DisableMode {
+	case "", FileWatchDisableModeStop, FileWatchDisableModePause:
+	default:
+		fieldErrors = append(fieldErrors, field.NotSupported(
+			field.NewPath("spec", "disableMode"),
+			in.Spec.DisableMode,
+			[]string{string(FileWatchDisableModeStop), string(FileWatchDisableModePause)}))
+	}
+	if in.Spec.StaleAfter.Duration < 0 {
+		fieldErrors = append(fieldErrors, field.Invalid(
+			field.NewPath("spec", "staleAfter"),
+			in.Spec.StaleAfter.Duration.String(),
+			"cannot be negative"))
+	}
+	if in.Spec.IgnoreOlderThan.Duration < 0 {
+		fieldErrors = append(fieldErrors, field.Invalid(
+			field.NewPath("spec", "ignoreOlderThan"),
+			in.Spec.IgnoreOlderThan.Duration.String(),
+			"cannot be negative"))
+	}
+	if in.Spec.PollInterval.Duration < 0 {
+		fieldErrors = append(fieldErrors, field.Invalid(
+			field.NewPath("spec", "pollInterval"),
+			in.Spec.PollInterval.Duration.String(),
+			"cannot be negative"))
+	}
+	if rw := in.Spec.PathRewrite; rw != nil {
+		if rw.From == "" {
+			fieldErrors = append(fieldErrors, field.Required(
+				field.NewPath("spec", "pathRewrite", "from"),
+				"must be set"))
+		}
+		if rw.To == "" {
+			fieldErrors = append(fieldErrors, field.Required(
+				field.NewPath("spec", "pathRewrite", "to"),
+				"must be set"))
+		}
+	}
 	return fieldErrors
 }
 
@@ -155,16 +907,151 @@ type FileWatchStatus struct {
 	// needing to inspect FileEvents.
 	LastEventTime metav1.MicroTime `json:"lastEventTime,omitempty" protobuf:"bytes,2,opt,name=lastEventTime"`
 	// FileEvents summarizes batches of file changes (create, modify, or delete) that have been seen in ascending
-	// chronological order. Only the most recent 20 events are included.
+	// chronological order. Only the most recent events are included, as limited by MaxEventHistory (default 20).
 	FileEvents []FileEvent `json:"fileEvents,omitempty" protobuf:"bytes,3,rep,name=fileEvents"`
-	// Error is set if there is a problem with the filesystem watch. If non-empty, consumers should assume that
+	// Error is set if the filesystem monitor fails while it's running. If non-empty, consumers should assume that
 	// no filesystem events will be seen and that the file watcher is in a failed state.
+	//
+	// Failures starting the monitor are reported in SetupError instead.
 	Error string `json:"error,omitempty" protobuf:"bytes,4,opt,name=error"`
+	// ErrorTime is the timestamp of when Error was set.
+	//
+	// +optional
+	ErrorTime metav1.MicroTime `json:"errorTime,omitempty" protobuf:"bytes,9,opt,name=errorTime"`
+	// SetupError is set if the filesystem monitor could not be started (e.g., a watched path
+	// could not be read). No filesystem events will be seen until a retry succeeds.
+	//
+	// Starting the monitor is retried with a growing backoff. After several failures in a
+	// row, it's only retried every few minutes, until the spec changes.
+	//
+	// +optional
+	SetupError string `json:"setupError,omitempty" protobuf:"bytes,10,opt,name=setupError"`
+	// SetupErrorTime is the timestamp of when SetupError was set.
+	//
+	// +optional
+	SetupErrorTime metav1.MicroTime `json:"setupErrorTime,omitempty" protobuf:"bytes,11,opt,name=setupErrorTime"`
 	// Details about whether/why this is disabled.
 	// +optional
 	DisableStatus *DisableStatus `json:"disableStatus,omitempty" protobuf:"bytes,5,opt,name=disableStatus"`
+	// DebounceDuration is the debounce window applied by the current filesystem monitor,
+	// after defaults have been resolved.
+	//
+	// +optional
+	DebounceDuration metav1.Duration `json:"debounceDuration,omitempty" protobuf:"bytes,6,opt,name=debounceDuration"`
+	// WatchedPaths are the paths the current filesystem monitor is subscribed to, after
+	// globs have been resolved to their root directories and symlinks have been followed.
+	//
+	// +optional
+	WatchedPaths []string `json:"watchedPaths,omitempty" protobuf:"bytes,7,rep,name=watchedPaths"`
+	// Conditions describe the health of the filesystem monitor.
+	//
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" protobuf:"bytes,8,rep,name=conditions"`
+	// LastRescanToken is the Spec.ForceRescanToken of the most recent rescan.
+	//
+	// +optional
+	LastRescanToken string `json:"lastRescanToken,omitempty" protobuf:"bytes,12,opt,name=lastRescanToken"`
+	// LastReplayToken is the Spec.ReplayToken of the most recent replay.
+	//
+	// +optional
+	LastReplayToken string `json:"lastReplayToken,omitempty" protobuf:"bytes,20,opt,name=lastReplayToken"`
+	// Baseline lists the files under WatchedPaths when the filesystem monitor started, in
+	// sorted order. Only set when Spec.RecordBaseline is set.
+	//
+	// It's written shortly after the monitor starts, so a file that changes in between
+	// may be listed here and in a FileEvent.
+	//
+	// +optional
+	Baseline []string `json:"baseline,omitempty" protobuf:"bytes,18,rep,name=baseline"`
+	// SkippedPaths lists the directories that couldn't be read when the filesystem monitor
+	// started, and so aren't watched. Only set when Spec.SkipUnreadable is set.
+	//
+	// +optional
+	SkippedPaths []string `json:"skippedPaths,omitempty" protobuf:"bytes,19,rep,name=skippedPaths"`
+
+	// ActiveIgnoreRules counts the ignore rules in effect for the current filesystem
+	// monitor, by where they came from.
+	//
+	// Useful for catching an ignore set that's silently empty, e.g., because a
+	// gitignoreFile or patternsConfigMap doesn't exist yet.
+	//
+	// +optional
+	ActiveIgnoreRules *IgnoreRuleCounts `json:"activeIgnoreRules,omitempty" protobuf:"bytes,21,opt,name=activeIgnoreRules"`
+
+	// WatchCount is the number of OS-level watches (e.g., inotify watch descriptors) held
+	// by the current filesystem monitor.
+	//
+	// Useful for finding which watches use up the OS watch limit. Monitors that can't
+	// count their watches (e.g., in Poll mode) report zero.
+	//
+	// +optional
+	WatchCount int32 `json:"watchCount,omitempty" protobuf:"varint,13,opt,name=watchCount"`
+
+	// TotalEventCount is the number of FileEvents recorded over the lifetime of the watch.
+	//
+	// Unlike FileEvents, it isn't limited by Spec.MaxEventHistory.
+	//
+	// +optional
+	TotalEventCount int64 `json:"totalEventCount,omitempty" protobuf:"varint,14,opt,name=totalEventCount"`
+
+	// TotalFilesSeen is the number of file changes recorded over the lifetime of the watch,
+	// summed across FileEvents.
+	//
+	// Unlike FileEvents, it isn't limited by Spec.MaxEventHistory.
+	//
+	// +optional
+	TotalFilesSeen int64 `json:"totalFilesSeen,omitempty" protobuf:"varint,15,opt,name=totalFilesSeen"`
+
+	// RecentlyIgnored lists the most recent paths that the ignore rules filtered out, oldest
+	// first, for spotting a misconfigured ignore.
+	//
+	// Only a limited number are kept. Not populated in Poll mode, where every scan consults
+	// the ignore rules.
+	//
+	// +optional
+	RecentlyIgnored []string `json:"recentlyIgnored,omitempty" protobuf:"bytes,16,rep,name=recentlyIgnored"`
+
+	// EstimatedWatchBytes is a rough estimate of the memory used by the current
+	// filesystem monitor's OS-level watches and the paths tracked alongside them.
+	//
+	// It's advisory, for sizing watches of large trees; the real cost depends on the
+	// OS and the length of the paths. Monitors that can't count their watches (e.g.,
+	// in Poll mode) report zero.
+	//
+	// +optional
+	EstimatedWatchBytes int64 `json:"estimatedWatchBytes,omitempty" protobuf:"varint,17,opt,name=estimatedWatchBytes"`
 }
 
+const (
+	// FileWatchConditionReady means the filesystem monitor is live and delivering events.
+	//
+	// It's False while the monitor is being restarted after an error (reason
+	// SetupInProgress), if it couldn't be started (reason SetupFailed), if it would go over
+	// the controller's budget of OS-level watches (reason OverBudget), or if it has failed
+	// to start so many times in a row that it's only retried occasionally (reason
+	// SetupCircuitOpen).
+	FileWatchConditionReady string = "Ready"
+
+	// FileWatchConditionStale means the watch has seen no file events for longer
+	// than Spec.StaleAfter. It's only set when StaleAfter is set.
+	FileWatchConditionStale string = "Stale"
+
+	// FileWatchConditionThrottled means file events arrived faster than
+	// Spec.MaxEventsPerSecond, and were merged together. It's only set when
+	// MaxEventsPerSecond is set.
+	FileWatchConditionThrottled string = "Throttled"
+
+	// FileWatchConditionSelfTestPassed means a file event made it through a test monitor
+	// started alongside the watch. It's Unknown while the test is running, and only set
+	// when Spec.SelfTest is set.
+	FileWatchConditionSelfTestPassed string = "SelfTestPassed"
+
+	// FileWatchConditionRootMissing means one of the paths in Spec.WatchedPaths doesn't
+	// exist. The watch is re-established automatically when it's created again. It's only
+	// set once a watched path has been missing.
+	FileWatchConditionRootMissing string = "RootMissing"
+)
+
 type FileEvent struct {
 	// Time is an approximate timestamp for a batch of file changes.
 	//
@@ -172,7 +1059,154 @@ type FileEvent struct {
 	// informational or for use as an opaque watermark.
 	Time metav1.MicroTime `json:"time" protobuf:"bytes,1,opt,name=time"`
 	// SeenFiles is a list of paths which changed (create, modify, or delete).
+	//
+	// Sorted, unless Spec.PreserveSeenFilesOrder is set.
 	SeenFiles []string `json:"seenFiles" protobuf:"bytes,2,rep,name=seenFiles"`
+	// DeletedFiles is the subset of SeenFiles that no longer existed on disk when the batch was recorded.
+	//
+	// +optional
+	DeletedFiles []string `json:"deletedFiles,omitempty" protobuf:"bytes,3,rep,name=deletedFiles"`
+	// RelSeenFiles are the paths in SeenFiles (in the same order), relative to Spec.RelativeTo.
+	//
+	// Only populated when Spec.RelativeTo is set.
+	//
+	// +optional
+	RelSeenFiles []string `json:"relSeenFiles,omitempty" protobuf:"bytes,4,rep,name=relSeenFiles"`
+	// FileWatchName is the name of the FileWatch that produced the event.
+	//
+	// Lets consumers that read events from several FileWatches tell them apart.
+	//
+	// +optional
+	FileWatchName string `json:"fileWatchName,omitempty" protobuf:"bytes,5,opt,name=fileWatchName"`
+	// FileWatchNamespace is the namespace of the FileWatch that produced the event.
+	//
+	// +optional
+	FileWatchNamespace string `json:"fileWatchNamespace,omitempty" protobuf:"bytes,6,opt,name=fileWatchNamespace"`
+	// SymlinkTargetChanges lists the watched symlinks in SeenFiles that were re-pointed.
+	//
+	// Only populated when Spec.WatchSymlinkTargets is set.
+	//
+	// +optional
+	SymlinkTargetChanges []SymlinkTargetChange `json:"symlinkTargetChanges,omitempty" protobuf:"bytes,7,rep,name=symlinkTargetChanges"`
+	// CreatedDirectories lists the directories in SeenFiles that were created.
+	//
+	// Only populated when Spec.ReportDirectoryEvents is set.
+	//
+	// +optional
+	CreatedDirectories []string `json:"createdDirectories,omitempty" protobuf:"bytes,8,rep,name=createdDirectories"`
+	// DeletedDirectories lists the directories in SeenFiles that were deleted.
+	//
+	// Only populated when Spec.ReportDirectoryEvents is set.
+	//
+	// +optional
+	DeletedDirectories []string `json:"deletedDirectories,omitempty" protobuf:"bytes,9,rep,name=deletedDirectories"`
+	// Seq numbers the FileEvents recorded by the watch, starting at 1 and increasing by
+	// one with each event, in step with Status.TotalEventCount.
+	//
+	// Consumers can compare it with the last Seq they read to tell whether events were
+	// trimmed from the history in between.
+	//
+	// +optional
+	Seq int64 `json:"seq,omitempty" protobuf:"varint,10,opt,name=seq"`
+	// FileModTime is the latest modification time on disk of the files in SeenFiles,
+	// as of when the batch was recorded.
+	//
+	// Unlike Time, it reflects when the files actually changed, so it tells a change
+	// that was recorded late apart from a recent one. Unset if none of the files exist.
+	//
+	// +optional
+	FileModTime metav1.MicroTime `json:"fileModTime,omitempty" protobuf:"bytes,11,opt,name=fileModTime"`
+	// ArchiveChanges lists the watched archives in SeenFiles whose entries changed.
+	//
+	// Only populated when Spec.DiffArchives is set.
+	//
+	// +optional
+	ArchiveChanges []ArchiveChange `json:"archiveChanges,omitempty" protobuf:"bytes,12,rep,name=archiveChanges"`
+	// FlushReason is why the batch of file changes was recorded when it was.
+	//
+	// +optional
+	FlushReason FileEventFlushReason `json:"flushReason,omitempty" protobuf:"bytes,13,opt,name=flushReason,casttype=FileEventFlushReason"`
+	// Moves lists the files in SeenFiles that were moved from another path in SeenFiles.
+	//
+	// Only populated when Spec.TrackMoves is set.
+	//
+	// +optional
+	Moves []FileMove `json:"moves,omitempty" protobuf:"bytes,14,rep,name=moves"`
+}
+
+// FileMove describes a file that was renamed or moved.
+type FileMove struct {
+	// OldPath is the absolute path the file was moved from.
+	OldPath string `json:"oldPath" protobuf:"bytes,1,opt,name=oldPath"`
+	// NewPath is the absolute path the file was moved to.
+	NewPath string `json:"newPath" protobuf:"bytes,2,opt,name=newPath"`
+}
+
+// FileEventFlushReason is why a FileEvent was recorded.
+type FileEventFlushReason string
+
+const (
+	// FileEventFlushReasonQuietPeriod means no new file changes were seen for the debounce
+	// duration.
+	FileEventFlushReasonQuietPeriod FileEventFlushReason = "QuietPeriod"
+
+	// FileEventFlushReasonTimer means file changes kept coming in for the longest a batch
+	// is held open, so the changes seen so far were recorded.
+	FileEventFlushReasonTimer FileEventFlushReason = "Timer"
+
+	// FileEventFlushReasonBatchSize means the batch reached Spec.MaxBatchSize, and the
+	// rest of the changes went into the next FileEvent.
+	FileEventFlushReasonBatchSize FileEventFlushReason = "BatchSize"
+
+	// FileEventFlushReasonForceRescan means the files were listed by a rescan requested
+	// with Spec.ForceRescanToken.
+	FileEventFlushReasonForceRescan FileEventFlushReason = "ForceRescan"
+
+	// FileEventFlushReasonShutdownDrain means the filesystem monitor was being stopped
+	// (e.g., because Tilt is shutting down, or the FileWatch was deleted), and the
+	// changes it had already received were recorded.
+	FileEventFlushReasonShutdownDrain FileEventFlushReason = "ShutdownDrain"
+)
+
+// SymlinkTargetChange describes a symlink that was re-pointed.
+type SymlinkTargetChange struct {
+	// Path is the absolute path of the symlink.
+	Path string `json:"path" protobuf:"bytes,1,opt,name=path"`
+	// OldTarget is the absolute path the symlink pointed to before the change.
+	//
+	// +optional
+	OldTarget string `json:"oldTarget,omitempty" protobuf:"bytes,2,opt,name=oldTarget"`
+	// NewTarget is the absolute path the symlink points to now, or empty if it was removed.
+	//
+	// +optional
+	NewTarget string `json:"newTarget,omitempty" protobuf:"bytes,3,opt,name=newTarget"`
+}
+
+// ArchiveChange describes the entries that changed inside a watched archive.
+type ArchiveChange struct {
+	// Path is the absolute path of the archive.
+	Path string `json:"path" protobuf:"bytes,1,opt,name=path"`
+	// Entries are the slash-separated paths, inside the archive, of the entries that
+	// were added, changed, or removed. Sorted.
+	Entries []string `json:"entries" protobuf:"bytes,2,rep,name=entries"`
+}
+
+// IgnoreRuleCounts counts the ignore rules of a FileWatch by source.
+type IgnoreRuleCounts struct {
+	// Inline is the number of patterns and regexes written in Spec.Ignores, plus
+	// ignores with only a basePath, which ignore everything under it.
+	//
+	// +optional
+	Inline int32 `json:"inline,omitempty" protobuf:"varint,1,opt,name=inline"`
+	// File is the number of patterns read from gitignoreFiles and patternsConfigMaps.
+	//
+	// +optional
+	File int32 `json:"file,omitempty" protobuf:"varint,2,opt,name=file"`
+	// Ephemeral is the number of built-in patterns for editor and tool temp files, or
+	// zero if Spec.DisableEphemeralIgnores is set.
+	//
+	// +optional
+	Ephemeral int32 `json:"ephemeral,omitempty" protobuf:"varint,3,opt,name=ephemeral"`
 }
 
 // FileWatch implements ObjectWithStatusSubResource interface.
@@ -29,6 +29,7 @@ import (
 
 func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
 	return map[string]common.OpenAPIDefinition{
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ArchiveChange":                     schema_pkg_apis_core_v1alpha1_ArchiveChange(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.Cluster":                           schema_pkg_apis_core_v1alpha1_Cluster(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ClusterConnection":                 schema_pkg_apis_core_v1alpha1_ClusterConnection(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ClusterConnectionStatus":           schema_pkg_apis_core_v1alpha1_ClusterConnectionStatus(ref),
@@ -52,6 +53,7 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ConfigMap":                         schema_pkg_apis_core_v1alpha1_ConfigMap(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ConfigMapDisableSource":            schema_pkg_apis_core_v1alpha1_ConfigMapDisableSource(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ConfigMapList":                     schema_pkg_apis_core_v1alpha1_ConfigMapList(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ConfigMapPatternsSource":           schema_pkg_apis_core_v1alpha1_ConfigMapPatternsSource(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ConfigMapStateSource":              schema_pkg_apis_core_v1alpha1_ConfigMapStateSource(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.Container":                         schema_pkg_apis_core_v1alpha1_Container(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ContainerLogStreamStatus":          schema_pkg_apis_core_v1alpha1_ContainerLogStreamStatus(ref),
@@ -82,6 +84,7 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageStateWaiting":           schema_pkg_apis_core_v1alpha1_DockerImageStateWaiting(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageStatus":                 schema_pkg_apis_core_v1alpha1_DockerImageStatus(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerPortBinding":                 schema_pkg_apis_core_v1alpha1_DockerPortBinding(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.EnvVarDisableSource":               schema_pkg_apis_core_v1alpha1_EnvVarDisableSource(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ExecAction":                        schema_pkg_apis_core_v1alpha1_ExecAction(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.Extension":                         schema_pkg_apis_core_v1alpha1_Extension(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ExtensionList":                     schema_pkg_apis_core_v1alpha1_ExtensionList(ref),
@@ -91,9 +94,12 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ExtensionRepoStatus":               schema_pkg_apis_core_v1alpha1_ExtensionRepoStatus(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ExtensionSpec":                     schema_pkg_apis_core_v1alpha1_ExtensionSpec(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ExtensionStatus":                   schema_pkg_apis_core_v1alpha1_ExtensionStatus(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.FileDisableSource":                 schema_pkg_apis_core_v1alpha1_FileDisableSource(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.FileEvent":                         schema_pkg_apis_core_v1alpha1_FileEvent(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.FileMove":                          schema_pkg_apis_core_v1alpha1_FileMove(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.FileWatch":                         schema_pkg_apis_core_v1alpha1_FileWatch(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.FileWatchList":                     schema_pkg_apis_core_v1alpha1_FileWatchList(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.FileWatchPathRewrite":              schema_pkg_apis_core_v1alpha1_FileWatchPathRewrite(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.FileWatchSpec":                     schema_pkg_apis_core_v1alpha1_FileWatchSpec(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.FileWatchStatus":                   schema_pkg_apis_core_v1alpha1_FileWatchStatus(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.Forward":                           schema_pkg_apis_core_v1alpha1_Forward(ref),
@@ -102,6 +108,7 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.HTTPHeader":                        schema_pkg_apis_core_v1alpha1_HTTPHeader(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.Handler":                           schema_pkg_apis_core_v1alpha1_Handler(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.IgnoreDef":                         schema_pkg_apis_core_v1alpha1_IgnoreDef(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.IgnoreRuleCounts":                  schema_pkg_apis_core_v1alpha1_IgnoreRuleCounts(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ImageMap":                          schema_pkg_apis_core_v1alpha1_ImageMap(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ImageMapList":                      schema_pkg_apis_core_v1alpha1_ImageMapList(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ImageMapOverrideArgs":              schema_pkg_apis_core_v1alpha1_ImageMapOverrideArgs(ref),
@@ -155,6 +162,7 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.Probe":                             schema_pkg_apis_core_v1alpha1_Probe(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.RegistryHosting":                   schema_pkg_apis_core_v1alpha1_RegistryHosting(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.RestartOnSpec":                     schema_pkg_apis_core_v1alpha1_RestartOnSpec(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ScheduleDisableSource":             schema_pkg_apis_core_v1alpha1_ScheduleDisableSource(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.Session":                           schema_pkg_apis_core_v1alpha1_Session(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.SessionCISpec":                     schema_pkg_apis_core_v1alpha1_SessionCISpec(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.SessionList":                       schema_pkg_apis_core_v1alpha1_SessionList(ref),
@@ -163,6 +171,7 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.StartOnSpec":                       schema_pkg_apis_core_v1alpha1_StartOnSpec(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.StateSource":                       schema_pkg_apis_core_v1alpha1_StateSource(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.StopOnSpec":                        schema_pkg_apis_core_v1alpha1_StopOnSpec(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.SymlinkTargetChange":               schema_pkg_apis_core_v1alpha1_SymlinkTargetChange(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.TCPSocketAction":                   schema_pkg_apis_core_v1alpha1_TCPSocketAction(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.Target":                            schema_pkg_apis_core_v1alpha1_Target(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.TargetState":                       schema_pkg_apis_core_v1alpha1_TargetState(ref),
@@ -275,6 +284,43 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 	}
 }
 
+func schema_pkg_apis_core_v1alpha1_ArchiveChange(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ArchiveChange describes the entries that changed inside a watched archive.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"path": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Path is the absolute path of the archive.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"entries": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Entries are the slash-separated paths, inside the archive, of the entries that were added, changed, or removed. Sorted.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"path", "entries"},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_core_v1alpha1_Cluster(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -1283,6 +1329,36 @@ func schema_pkg_apis_core_v1alpha1_ConfigMapList(ref common.ReferenceCallback) c
 	}
 }
 
+func schema_pkg_apis_core_v1alpha1_ConfigMapPatternsSource(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ConfigMapPatternsSource specifies a ConfigMap key that holds ignore patterns.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The name of the ConfigMap.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"key": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The key where the newline-delimited patterns are stored.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"name", "key"},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_core_v1alpha1_ConfigMapStateSource(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -1648,11 +1724,29 @@ func schema_pkg_apis_core_v1alpha1_DisableSource(ref common.ReferenceCallback) c
 							},
 						},
 					},
+					"file": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Disabled by the existence of a file.",
+							Ref:         ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.FileDisableSource"),
+						},
+					},
+					"schedule": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Disabled outside of a daily time window.",
+							Ref:         ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ScheduleDisableSource"),
+						},
+					},
+					"envVar": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Disabled by an environment variable of the Tilt process.",
+							Ref:         ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.EnvVarDisableSource"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ConfigMapDisableSource"},
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ConfigMapDisableSource", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.EnvVarDisableSource", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.FileDisableSource", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ScheduleDisableSource"},
 	}
 }
 
@@ -2758,6 +2852,35 @@ func schema_pkg_apis_core_v1alpha1_DockerPortBinding(ref common.ReferenceCallbac
 	}
 }
 
+func schema_pkg_apis_core_v1alpha1_EnvVarDisableSource(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "Specifies an environment variable to control a DisableSource. It's read from the environment of the Tilt process whenever the object is reconciled.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The name of the environment variable.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"value": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The value that disables the object.\n\nIf empty, the object is disabled while the variable is set to a true value (e.g., \"1\" or \"true\"), and enabled while it's unset or false.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"name"},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_core_v1alpha1_ExecAction(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -3142,6 +3265,35 @@ func schema_pkg_apis_core_v1alpha1_ExtensionStatus(ref common.ReferenceCallback)
 	}
 }
 
+func schema_pkg_apis_core_v1alpha1_FileDisableSource(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "Specifies a sentinel file to control a DisableSource",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"path": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The path of the sentinel file. The object is disabled while it exists.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"invert": {
+						SchemaProps: spec.SchemaProps{
+							Description: "If true, the object is instead disabled while the file does not exist.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"path"},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_core_v1alpha1_FileEvent(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -3156,7 +3308,22 @@ func schema_pkg_apis_core_v1alpha1_FileEvent(ref common.ReferenceCallback) commo
 					},
 					"seenFiles": {
 						SchemaProps: spec.SchemaProps{
-							Description: "SeenFiles is a list of paths which changed (create, modify, or delete).",
+							Description: "SeenFiles is a list of paths which changed (create, modify, or delete).\n\nSorted, unless Spec.PreserveSeenFilesOrder is set.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"deletedFiles": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DeletedFiles is the subset of SeenFiles that no longer existed on disk when the batch was recorded.",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
@@ -3169,12 +3336,163 @@ func schema_pkg_apis_core_v1alpha1_FileEvent(ref common.ReferenceCallback) commo
 							},
 						},
 					},
+					"relSeenFiles": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RelSeenFiles are the paths in SeenFiles (in the same order), relative to Spec.RelativeTo.\n\nOnly populated when Spec.RelativeTo is set.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"fileWatchName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "FileWatchName is the name of the FileWatch that produced the event.\n\nLets consumers that read events from several FileWatches tell them apart.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"fileWatchNamespace": {
+						SchemaProps: spec.SchemaProps{
+							Description: "FileWatchNamespace is the namespace of the FileWatch that produced the event.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"symlinkTargetChanges": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SymlinkTargetChanges lists the watched symlinks in SeenFiles that were re-pointed.\n\nOnly populated when Spec.WatchSymlinkTargets is set.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.SymlinkTargetChange"),
+									},
+								},
+							},
+						},
+					},
+					"createdDirectories": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CreatedDirectories lists the directories in SeenFiles that were created.\n\nOnly populated when Spec.ReportDirectoryEvents is set.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"deletedDirectories": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DeletedDirectories lists the directories in SeenFiles that were deleted.\n\nOnly populated when Spec.ReportDirectoryEvents is set.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"seq": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Seq numbers the FileEvents recorded by the watch, starting at 1 and increasing by one with each event, in step with Status.TotalEventCount.\n\nConsumers can compare it with the last Seq they read to tell whether events were trimmed from the history in between.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"fileModTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "FileModTime is the latest modification time on disk of the files in SeenFiles, as of when the batch was recorded.\n\nUnlike Time, it reflects when the files actually changed, so it tells a change that was recorded late apart from a recent one. Unset if none of the files exist.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"),
+						},
+					},
+					"archiveChanges": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ArchiveChanges lists the watched archives in SeenFiles whose entries changed.\n\nOnly populated when Spec.DiffArchives is set.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ArchiveChange"),
+									},
+								},
+							},
+						},
+					},
+					"flushReason": {
+						SchemaProps: spec.SchemaProps{
+							Description: "FlushReason is why the batch of file changes was recorded when it was.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"moves": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Moves lists the files in SeenFiles that were moved from another path in SeenFiles.\n\nOnly populated when Spec.TrackMoves is set.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.FileMove"),
+									},
+								},
+							},
+						},
+					},
 				},
 				Required: []string{"time", "seenFiles"},
 			},
 		},
 		Dependencies: []string{
-			"k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"},
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ArchiveChange", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.FileMove", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.SymlinkTargetChange", "k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"},
+	}
+}
+
+func schema_pkg_apis_core_v1alpha1_FileMove(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "FileMove describes a file that was renamed or moved.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"oldPath": {
+						SchemaProps: spec.SchemaProps{
+							Description: "OldPath is the absolute path the file was moved from.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"newPath": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NewPath is the absolute path the file was moved to.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"oldPath", "newPath"},
+			},
+		},
 	}
 }
 
@@ -3192,98 +3510,604 @@ func schema_pkg_apis_core_v1alpha1_FileWatch(ref common.ReferenceCallback) commo
 							Format:      "",
 						},
 					},
-					"apiVersion": {
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.FileWatchSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.FileWatchStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.FileWatchSpec", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.FileWatchStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_pkg_apis_core_v1alpha1_FileWatchList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "FileWatchList",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"),
+						},
+					},
+					"items": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.FileWatch"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"items"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.FileWatch", "k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"},
+	}
+}
+
+func schema_pkg_apis_core_v1alpha1_FileWatchPathRewrite(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "FileWatchPathRewrite replaces a leading path prefix.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"from": {
+						SchemaProps: spec.SchemaProps{
+							Description: "From is the prefix to replace. Only whole path components match, so /src matches /src/main.go but not /srv or /src2.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"to": {
+						SchemaProps: spec.SchemaProps{
+							Description: "To is the prefix to replace it with.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"from", "to"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_core_v1alpha1_FileWatchSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "FileWatchSpec defines the desired state of FileWatch",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"watchedPaths": {
+						SchemaProps: spec.SchemaProps{
+							Description: "WatchedPaths are paths of directories or files to watch for changes to. It cannot be empty.\n\nEntries may also be globs (e.g., `src/**/*.go`), in which case any file matching the glob is watched, including files created after the watch has started.\n\nPaths don't need to exist yet. The nearest existing ancestor is watched until they're created, so a watch on a lazily-created build output directory sees files created inside it.\n\nEntries may also be individual files. Only changes to the file itself are reported, not changes to its siblings.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"ignores": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Ignores are optional rules to filter out a subset of changes matched by WatchedPaths.\n\nA change is filtered out if any rule matches it, so an ignore always wins over IncludeExtensions. The rules are checked in a fixed order: IncludeExtensions, then Ignores, then the ephemeral ignores, then the remaining filters (globs in WatchedPaths, MaxFileSize, IgnoreOlderThan, and MaxDepth). The first rule that matches is the one reported by DebugIgnores.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.IgnoreDef"),
+									},
+								},
+							},
+						},
+					},
+					"disableSource": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Specifies how to disable this.",
+							Ref:         ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DisableSource"),
+						},
+					},
+					"maxEventHistory": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxEventHistory is the maximum number of file events that will be retained on the FileWatch status.\n\nIf unset, the controller default (20) is used.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"debounceDuration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DebounceDuration is how long the watcher waits without seeing a new file change before emitting the batch of changes it has collected.\n\nIf zero, the controller default (200ms) is used.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+					"quietPeriod": {
+						SchemaProps: spec.SchemaProps{
+							Description: "QuietPeriod is how long the watcher waits without seeing a new file change before emitting the batch of changes it has collected, with no upper bound on how long a batch stays open.\n\nUnlike DebounceDuration, which flushes a batch after 10s even if file changes are still coming in, a batch is only emitted once there's been a gap of QuietPeriod. Useful for build pipelines that write files in long streaks.\n\nCannot be set together with DebounceDuration.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+					"perDirectoryDebounce": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PerDirectoryDebounce debounces the file changes in each directory separately, so that a directory with a steady stream of changes doesn't hold up the changes in a quiet one.\n\nEach directory's changes are batched on their own DebounceDuration (or QuietPeriod) timer, and emitted as their own FileEvent. Changes are grouped by the directory that contains the changed path.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"watchMode": {
+						SchemaProps: spec.SchemaProps{
+							Description: "WatchMode determines how the filesystem is monitored for changes.\n\nDefaults to Native. Poll is slower and more expensive, but works on filesystems where native notifications are unreliable (e.g., NFS or some Docker bind mounts).",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"pollInterval": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PollInterval is how often the watched paths are scanned for changes when WatchMode is Poll.\n\nIf zero, defaults to 1s. Ignored for other watch modes.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+					"changeDetection": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ChangeDetection is how files are compared between scans when WatchMode is Poll.\n\nDefaults to ModTime. ContentHash reads every watched file on every scan, so it's best kept to small sets of files. Ignored for other watch modes.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"disableSources": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DisableSources are additional ways to disable this, combined with DisableSource (if set) according to DisableSourcePolicy.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DisableSource"),
+									},
+								},
+							},
+						},
+					},
+					"disableSourcePolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DisableSourcePolicy determines how the results of multiple disable sources are combined.\n\nDefaults to AnyDisabled.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"followSymlinks": {
+						SchemaProps: spec.SchemaProps{
+							Description: "FollowSymlinks watches the real targets of symlinked directories under WatchedPaths.\n\nChanges behind a symlink are reported under the symlink's path.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"watchSymlinkTargets": {
+						SchemaProps: spec.SchemaProps{
+							Description: "WatchSymlinkTargets reports when a watched path that's a symlink is re-pointed (e.g., a `current -> releases/<ts>` link that's swapped on deploy).\n\nThe symlink itself is watched, and each FileEvent that sees it lists the old and new targets in SymlinkTargetChanges. The watch then follows the new target.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"diffArchives": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DiffArchives reports which entries changed inside a watched path that's a zip archive (e.g., a .zip or .jar that's rebuilt), rather than only the archive itself.\n\nThe archive's entries are indexed when the filesystem monitor starts, and each FileEvent that sees it lists the entries that were added, changed, or removed in ArchiveChanges. A rewrite that leaves every entry the same isn't reported at all. Only WatchedPaths entries that are archives are diffed, not archives under watched directories.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"trackMoves": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TrackMoves reports a file that's renamed or moved within the watched paths as a move, rather than only as one path being deleted and another created.\n\nFiles are followed by their inode, so the inode of every file under WatchedPaths that isn't ignored is recorded when the filesystem monitor starts, which takes some memory on large trees. A file whose size or modification time changed along with its path isn't reported as a move, since its inode may have been reused. Each FileEvent that sees both paths of a move lists it in Moves; both paths are still listed in SeenFiles, and the old one in DeletedFiles. Directories aren't followed. Not supported on Windows.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"debugIgnores": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DebugIgnores logs every path the filesystem monitor sees, along with whether it was ignored and which rule ignored it.\n\nThis is noisy, and only intended for debugging ignores.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"staleAfter": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StaleAfter is how long the watch can go without seeing a file event before the Stale condition is set on the status.\n\nUseful for detecting filesystem monitors that have silently stopped delivering events. If zero, the watch is never considered stale.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+					"maxBatchSize": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxBatchSize is the maximum number of files in a single FileEvent.\n\nA batch is recorded as soon as it has changes to this many files, without waiting for the debounce window, and larger batches of changes are split into multiple FileEvents, so that consumers can bound the work they do for each event and start on large changes sooner. If unset, batches are unbounded.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"eventBufferSize": {
+						SchemaProps: spec.SchemaProps{
+							Description: "EventBufferSize is the number of file changes that can be queued between the filesystem monitor and the controller while a batch is being recorded.\n\nWhen the buffer is full, the monitor waits for room rather than dropping changes, and a warning is logged. Heavy watches with bursty changes may want a larger buffer. If unset, the controller default (1024) is used.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"forceRescanToken": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ForceRescanToken triggers a full rescan of WatchedPaths when it changes.\n\nEvery file under WatchedPaths (that isn't ignored) is reported in a FileEvent, as if it had changed. Useful for recovering from events missed while the watch was disabled.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"replayToken": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ReplayToken re-appends the most recent FileEvent as a new event when it changes, so that a consumer that starts watching after the event happened can still observe it.\n\nThe new event has the same files, with a new Seq and Time. Nothing is replayed if there are no FileEvents yet, or while the watch is paused. Unlike ForceRescanToken, this doesn't walk WatchedPaths or restart the filesystem monitor.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"recordBaseline": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RecordBaseline lists every file under WatchedPaths (that isn't ignored) in Status.Baseline when the filesystem monitor starts, so that consumers know the starting state before any FileEvents.\n\nThe listing is taken once, and kept when the monitor is restarted, unless WatchedPaths change. It can be large for big trees.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"maxFileSize": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxFileSize ignores changes to files larger than this size, written as a Kubernetes quantity (e.g., `500Mi`).\n\nUseful for directories where large binary artifacts land that shouldn't trigger rebuilds. Files that can't be inspected (e.g., deleted files) are never ignored by size.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"includeExtensions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "IncludeExtensions limits events to files with one of these extensions (e.g., `.go`).\n\nFiles with any other extension are ignored. This is checked before Ignores, but a file with one of these extensions is still ignored if an ignore matches it. If empty, files are not filtered by extension.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"maxDepth": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxDepth limits how many directory levels below each entry in WatchedPaths are watched.\n\nA MaxDepth of 1 only watches the files and directories directly inside each watched path. Deeper directories aren't watched at all, which keeps the number of OS-level watches down for trees with large nested directories (e.g., vendored dependencies). If unset, there is no limit.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"maxEventsPerSecond": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxEventsPerSecond limits how many FileEvents are recorded per second.\n\nOnce the limit is reached, further batches of changes within the same second are merged into the most recent FileEvent (so no changed files are lost) and the Throttled condition is set. Protects consumers from event storms, e.g., when a tool rewrites an entire directory. If unset, events aren't rate limited.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"relativeTo": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RelativeTo is a base directory for reporting relative paths.\n\nWhen set, each FileEvent also lists its SeenFiles relative to this directory in RelSeenFiles. SeenFiles are always absolute.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"pathRewrite": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PathRewrite rewrites the paths in each FileEvent from one prefix to another, e.g., from where a remote workspace is mounted locally to where it lives remotely.\n\nRelSeenFiles are computed from the rewritten paths, with RelativeTo rewritten the same way. Everything else (WatchedPaths, Ignores, the paths in the status outside of FileEvents) stays local.\n\nConsumers inside Tilt match file events against local paths, so this is only useful for FileWatches read by other tools.",
+							Ref:         ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.FileWatchPathRewrite"),
+						},
+					},
+					"collapseTo": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CollapseTo lists directories whose changes are reported as a single change to the directory itself.\n\nA change anywhere under one of these directories is reported in SeenFiles as the directory, rather than as the file that changed. If directories are nested, the closest one is reported. Useful when consumers only care that something under a directory changed, and the individual files would bloat the status.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"logPrefix": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LogPrefix starts each line the controller logs about this watch (e.g., errors from the filesystem monitor), to tell watches apart in sessions with many of them.\n\nDefaults to \"filewatch <name>\".",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"skipUnreadable": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SkipUnreadable skips directories under WatchedPaths that can't be read (e.g., because of their permissions), instead of failing to start the filesystem monitor. The rest of the tree is still watched.\n\nSkipped directories are logged and listed in Status.SkippedPaths. They're found when the monitor starts, so one that becomes readable later isn't watched until the monitor is restarted. The watched paths themselves must still be readable.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"disableEphemeralIgnores": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DisableEphemeralIgnores turns off Tilt's built-in ignores for editor temp files and similar (e.g., `.idea/`, `*.swp`).\n\nUseful for watching files that the built-in ignores would otherwise hide, such as `.idea/workspace.xml`. Ignores listed in the spec still apply.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"watchHiddenFiles": {
+						SchemaProps: spec.SchemaProps{
+							Description: "WatchHiddenFiles exempts files whose names start with a dot from Tilt's built-in ignores for editor temp files, while keeping those ignores for everything else.\n\nHidden files that aren't editor temp files (e.g., `.env`) are watched either way. Ignores listed in the spec still apply.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"crossMountBoundaries": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CrossMountBoundaries makes the watcher explicitly watch any filesystems mounted below the watched paths (e.g., a volume mounted inside a watched directory).\n\nSome native monitors (e.g., on macOS and Windows) don't report changes on the far side of a mount point. Mount points are detected once, when the watch starts, so volumes mounted later aren't picked up until the watch is restarted. Detection is best-effort and only supported on Linux and macOS.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"reportDirectoryEvents": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ReportDirectoryEvents reports directories that are created or deleted under the watched paths in each FileEvent's CreatedDirectories and DeletedDirectories.\n\nUseful for watching a directory of subdirectories (e.g., plugins) that come and go as a whole. Not supported with WatchMode Poll.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"statusUpdateInterval": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StatusUpdateInterval is the minimum time between writes of the status to the API server.\n\nFile events seen in between are still recorded, and are written together in the next update. Errors and changes to the disable status are always written right away.\n\nBusy watches may want to set this to reduce load on the API server. If unset, the status is written as soon as events are recorded.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+					"selfTest": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SelfTest checks that the environment can deliver file events when the filesystem monitor starts, by watching a temporary directory and writing a file to it.\n\nThe result is reported in the SelfTestPassed condition. Useful for catching environments where the native monitor silently sees nothing (e.g., some network filesystems and VMs). Not supported with WatchMode Poll.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"saveProfiles": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SaveProfiles lists the editors whose atomic saves should be recognized.\n\nWhen an editor saves a file by writing temp files and renaming them over it, the changes to its temp files are collapsed into a single change to the file being saved. This is more precise than the built-in ephemeral ignores, and still applies when DisableEphemeralIgnores is set.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"watchRestartInterval": {
+						SchemaProps: spec.SchemaProps{
+							Description: "WatchRestartInterval restarts the filesystem monitor after it's been running this long, as a mitigation for OS event streams that degrade over very long sessions.\n\nThe new monitor is started before the old one is stopped, and events the old one had already received are kept, so no changes are missed across the restart. The event history is preserved. If unset, the monitor is only restarted when needed.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+					"preserveSeenFilesOrder": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PreserveSeenFilesOrder reports the files in each FileEvent in the order they were first seen, rather than sorted.\n\nBy default, SeenFiles are sorted so that the output is stable across platforms, whose monitors report the changes in a batch in different orders.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"disableMode": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DisableMode determines what happens to the watch while it's disabled by its disable sources.\n\nDefaults to Stop.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"ignoreOlderThan": {
+						SchemaProps: spec.SchemaProps{
+							Description: "IgnoreOlderThan ignores changes to files that were last modified longer ago than this, according to their mtime.\n\nUseful for directories where only new files matter (e.g., logs, where rotated files are touched long after they were last written). Files that can't be inspected (e.g., deleted files) are never ignored by age. If unset, files aren't filtered by age.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+					"watchEventTypes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "WatchEventTypes limits the changes that are reported to the given types.\n\nUseful for config files, where only changes to the content matter. Changes that the filesystem monitor can't classify are always reported. If unset, changes of every type are reported.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"watchedPaths"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DisableSource", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.FileWatchPathRewrite", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.IgnoreDef", "k8s.io/apimachinery/pkg/apis/meta/v1.Duration"},
+	}
+}
+
+func schema_pkg_apis_core_v1alpha1_FileWatchStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "FileWatchStatus defines the observed state of FileWatch",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"monitorStartTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MonitorStartTime is the timestamp of when filesystem monitor was started. It is zero if the monitor has not been started yet.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"),
+						},
+					},
+					"lastEventTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LastEventTime is the timestamp of the most recent file event. It is zero if no events have been seen yet.\n\nIf the specifics of which files changed are not important, this field can be used as a watermark without needing to inspect FileEvents.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"),
+						},
+					},
+					"fileEvents": {
+						SchemaProps: spec.SchemaProps{
+							Description: "FileEvents summarizes batches of file changes (create, modify, or delete) that have been seen in ascending chronological order. Only the most recent events are included, as limited by MaxEventHistory (default 20).",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.FileEvent"),
+									},
+								},
+							},
+						},
+					},
+					"error": {
 						SchemaProps: spec.SchemaProps{
-							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Description: "Error is set if the filesystem monitor fails while it's running. If non-empty, consumers should assume that no filesystem events will be seen and that the file watcher is in a failed state.\n\nFailures starting the monitor are reported in SetupError instead.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"metadata": {
+					"errorTime": {
 						SchemaProps: spec.SchemaProps{
-							Default: map[string]interface{}{},
-							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+							Description: "ErrorTime is the timestamp of when Error was set.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"),
 						},
 					},
-					"spec": {
+					"setupError": {
 						SchemaProps: spec.SchemaProps{
-							Default: map[string]interface{}{},
-							Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.FileWatchSpec"),
+							Description: "SetupError is set if the filesystem monitor could not be started (e.g., a watched path could not be read). No filesystem events will be seen until a retry succeeds.\n\nStarting the monitor is retried with a growing backoff. After several failures in a row, it's only retried every few minutes, until the spec changes.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"status": {
+					"setupErrorTime": {
 						SchemaProps: spec.SchemaProps{
-							Default: map[string]interface{}{},
-							Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.FileWatchStatus"),
+							Description: "SetupErrorTime is the timestamp of when SetupError was set.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"),
 						},
 					},
-				},
-			},
-		},
-		Dependencies: []string{
-			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.FileWatchSpec", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.FileWatchStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
-	}
-}
-
-func schema_pkg_apis_core_v1alpha1_FileWatchList(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "FileWatchList",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"kind": {
+					"disableStatus": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Details about whether/why this is disabled.",
+							Ref:         ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DisableStatus"),
 						},
 					},
-					"apiVersion": {
+					"debounceDuration": {
 						SchemaProps: spec.SchemaProps{
-							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "DebounceDuration is the debounce window applied by the current filesystem monitor, after defaults have been resolved.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
 						},
 					},
-					"metadata": {
+					"watchedPaths": {
 						SchemaProps: spec.SchemaProps{
-							Default: map[string]interface{}{},
-							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"),
+							Description: "WatchedPaths are the paths the current filesystem monitor is subscribed to, after globs have been resolved to their root directories and symlinks have been followed.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
 						},
 					},
-					"items": {
+					"conditions": {
 						SchemaProps: spec.SchemaProps{
-							Type: []string{"array"},
+							Description: "Conditions describe the health of the filesystem monitor.",
+							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
 										Default: map[string]interface{}{},
-										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.FileWatch"),
+										Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.Condition"),
 									},
 								},
 							},
 						},
 					},
-				},
-				Required: []string{"items"},
-			},
-		},
-		Dependencies: []string{
-			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.FileWatch", "k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"},
-	}
-}
-
-func schema_pkg_apis_core_v1alpha1_FileWatchSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "FileWatchSpec defines the desired state of FileWatch",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"watchedPaths": {
+					"lastRescanToken": {
 						SchemaProps: spec.SchemaProps{
-							Description: "WatchedPaths are paths of directories or files to watch for changes to. It cannot be empty.",
+							Description: "LastRescanToken is the Spec.ForceRescanToken of the most recent rescan.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"lastReplayToken": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LastReplayToken is the Spec.ReplayToken of the most recent replay.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"baseline": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Baseline lists the files under WatchedPaths when the filesystem monitor started, in sorted order. Only set when Spec.RecordBaseline is set.\n\nIt's written shortly after the monitor starts, so a file that changes in between may be listed here and in a FileEvent.",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
@@ -3296,86 +4120,75 @@ func schema_pkg_apis_core_v1alpha1_FileWatchSpec(ref common.ReferenceCallback) c
 							},
 						},
 					},
-					"ignores": {
+					"skippedPaths": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Ignores are optional rules to filter out a subset of changes matched by WatchedPaths.",
+							Description: "SkippedPaths lists the directories that couldn't be read when the filesystem monitor started, and so aren't watched. Only set when Spec.SkipUnreadable is set.",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Default: map[string]interface{}{},
-										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.IgnoreDef"),
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
 									},
 								},
 							},
 						},
 					},
-					"disableSource": {
+					"activeIgnoreRules": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Specifies how to disable this.",
-							Ref:         ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DisableSource"),
+							Description: "ActiveIgnoreRules counts the ignore rules in effect for the current filesystem monitor, by where they came from.\n\nUseful for catching an ignore set that's silently empty, e.g., because a gitignoreFile or patternsConfigMap doesn't exist yet.",
+							Ref:         ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.IgnoreRuleCounts"),
 						},
 					},
-				},
-				Required: []string{"watchedPaths"},
-			},
-		},
-		Dependencies: []string{
-			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DisableSource", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.IgnoreDef"},
-	}
-}
-
-func schema_pkg_apis_core_v1alpha1_FileWatchStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "FileWatchStatus defines the observed state of FileWatch",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"monitorStartTime": {
+					"watchCount": {
 						SchemaProps: spec.SchemaProps{
-							Description: "MonitorStartTime is the timestamp of when filesystem monitor was started. It is zero if the monitor has not been started yet.",
-							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"),
+							Description: "WatchCount is the number of OS-level watches (e.g., inotify watch descriptors) held by the current filesystem monitor.\n\nUseful for finding which watches use up the OS watch limit. Monitors that can't count their watches (e.g., in Poll mode) report zero.",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"lastEventTime": {
+					"totalEventCount": {
 						SchemaProps: spec.SchemaProps{
-							Description: "LastEventTime is the timestamp of the most recent file event. It is zero if no events have been seen yet.\n\nIf the specifics of which files changed are not important, this field can be used as a watermark without needing to inspect FileEvents.",
-							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"),
+							Description: "TotalEventCount is the number of FileEvents recorded over the lifetime of the watch.\n\nUnlike FileEvents, it isn't limited by Spec.MaxEventHistory.",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"fileEvents": {
+					"totalFilesSeen": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TotalFilesSeen is the number of file changes recorded over the lifetime of the watch, summed across FileEvents.\n\nUnlike FileEvents, it isn't limited by Spec.MaxEventHistory.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"recentlyIgnored": {
 						SchemaProps: spec.SchemaProps{
-							Description: "FileEvents summarizes batches of file changes (create, modify, or delete) that have been seen in ascending chronological order. Only the most recent 20 events are included.",
+							Description: "RecentlyIgnored lists the most recent paths that the ignore rules filtered out, oldest first, for spotting a misconfigured ignore.\n\nOnly a limited number are kept. Not populated in Poll mode, where every scan consults the ignore rules.",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Default: map[string]interface{}{},
-										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.FileEvent"),
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
 									},
 								},
 							},
 						},
 					},
-					"error": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Error is set if there is a problem with the filesystem watch. If non-empty, consumers should assume that no filesystem events will be seen and that the file watcher is in a failed state.",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"disableStatus": {
+					"estimatedWatchBytes": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Details about whether/why this is disabled.",
-							Ref:         ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DisableStatus"),
+							Description: "EstimatedWatchBytes is a rough estimate of the memory used by the current filesystem monitor's OS-level watches and the paths tracked alongside them.\n\nIt's advisory, for sizing watches of large trees; the real cost depends on the OS and the length of the paths. Monitors that can't count their watches (e.g., in Poll mode) report zero.",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DisableStatus", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.FileEvent", "k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"},
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DisableStatus", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.FileEvent", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.IgnoreRuleCounts", "k8s.io/apimachinery/pkg/apis/meta/v1.Condition", "k8s.io/apimachinery/pkg/apis/meta/v1.Duration", "k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"},
 	}
 }
 
@@ -3620,7 +4433,7 @@ func schema_pkg_apis_core_v1alpha1_IgnoreDef(ref common.ReferenceCallback) commo
 				Properties: map[string]spec.Schema{
 					"basePath": {
 						SchemaProps: spec.SchemaProps{
-							Description: "BasePath is the base path for the patterns. It cannot be empty.\n\nIf no patterns are specified, everything under it will be recursively ignored.",
+							Description: "BasePath is the base path for the patterns.\n\nIf empty, the patterns are evaluated relative to each of the WatchedPaths. It cannot be empty unless Patterns, PatternsConfigMap, GitignoreFile, or Regex is set.\n\nIf no patterns (and no GitignoreFile or Regex) are specified, everything under it will be recursively ignored.",
 							Default:     "",
 							Type:        []string{"string"},
 							Format:      "",
@@ -3628,7 +4441,42 @@ func schema_pkg_apis_core_v1alpha1_IgnoreDef(ref common.ReferenceCallback) commo
 					},
 					"patterns": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Patterns are dockerignore style rules. Absolute-style patterns will be rooted to the BasePath.\n\nSee https://docs.docker.com/engine/reference/builder/#dockerignore-file.",
+							Description: "Patterns are dockerignore style rules. Absolute-style patterns will be rooted to the BasePath.\n\nA pattern starting with `!` re-includes paths matched by an earlier pattern. When more than one pattern matches a path, the last one wins.\n\nSee https://docs.docker.com/engine/reference/builder/#dockerignore-file.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"gitignoreFile": {
+						SchemaProps: spec.SchemaProps{
+							Description: "GitignoreFile is a path to a file of gitignore style rules (e.g., a repo's .gitignore).\n\nThe rules are anchored to the directory containing the file, and follow git's semantics: a pattern without a slash matches at any depth, and a `!` pattern re-includes paths ignored by an earlier rule. A FileWatch re-reads the file whenever it changes.\n\nSee https://git-scm.com/docs/gitignore.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"caseInsensitive": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CaseInsensitive matches BasePath, Patterns, GitignoreFile, and Regex rules without regard to case.\n\nUseful on case-insensitive filesystems (the default on macOS and Windows), where a pattern like `build` should also ignore changes reported under `Build`.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"patternsConfigMap": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PatternsConfigMap reads more Patterns from a ConfigMap key, one per line.\n\nThey're added after Patterns, and follow the same rules. Blank lines and lines starting with `#` are skipped. The ConfigMap is re-read whenever it changes; if it (or the key) doesn't exist, no patterns are added.\n\nOnly supported for FileWatch ignores.",
+							Ref:         ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ConfigMapPatternsSource"),
+						},
+					},
+					"regex": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Regex are regular expressions, in Go's RE2 syntax, for paths to ignore.\n\nEach is matched against the slash-separated path relative to BasePath, and isn't anchored, so use `^` and `$` to match the whole path. For example, `(^|/)[^/]*[0-9]{12}[^/]*$` ignores files with a 12-digit timestamp in the name. Paths outside BasePath never match.\n\nA regex that doesn't compile is reported in the FileWatch's SetupError.",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
@@ -3645,6 +4493,42 @@ func schema_pkg_apis_core_v1alpha1_IgnoreDef(ref common.ReferenceCallback) commo
 				Required: []string{"basePath"},
 			},
 		},
+		Dependencies: []string{
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ConfigMapPatternsSource"},
+	}
+}
+
+func schema_pkg_apis_core_v1alpha1_IgnoreRuleCounts(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "IgnoreRuleCounts counts the ignore rules of a FileWatch by source.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"inline": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Inline is the number of patterns and regexes written in Spec.Ignores, plus ignores with only a basePath, which ignore everything under it.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"file": {
+						SchemaProps: spec.SchemaProps{
+							Description: "File is the number of patterns read from gitignoreFiles and patternsConfigMaps.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"ephemeral": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Ephemeral is the number of built-in patterns for editor and tool temp files, or zero if Spec.DisableEphemeralIgnores is set.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+			},
+		},
 	}
 }
 
@@ -6150,6 +7034,43 @@ func schema_pkg_apis_core_v1alpha1_RestartOnSpec(ref common.ReferenceCallback) c
 	}
 }
 
+func schema_pkg_apis_core_v1alpha1_ScheduleDisableSource(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "Specifies a daily time window to control a DisableSource. The object is disabled outside of the window.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"activeStart": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The time of day the window starts, in 24-hour \"HH:MM\" format.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"activeEnd": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The time of day the window ends, in 24-hour \"HH:MM\" format.\n\nIf it's earlier than ActiveStart, the window spans midnight. If it's equal to ActiveStart, the object is never disabled.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"timeZone": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The IANA name of the time zone the times are in (e.g., \"America/New_York\").\n\nDefaults to the local time zone of the Tilt process.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"activeStart", "activeEnd"},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_core_v1alpha1_Session(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -6455,6 +7376,42 @@ func schema_pkg_apis_core_v1alpha1_StopOnSpec(ref common.ReferenceCallback) comm
 	}
 }
 
+func schema_pkg_apis_core_v1alpha1_SymlinkTargetChange(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "SymlinkTargetChange describes a symlink that was re-pointed.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"path": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Path is the absolute path of the symlink.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"oldTarget": {
+						SchemaProps: spec.SchemaProps{
+							Description: "OldTarget is the absolute path the symlink pointed to before the change.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"newTarget": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NewTarget is the absolute path the symlink points to now, or empty if it was removed.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"path"},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_core_v1alpha1_TCPSocketAction(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{